@@ -374,6 +374,8 @@ type Integrations struct {
 	// (https://kueue.sigs.k8s.io/docs/tasks/run/plain_pods/)
 	// instead.
 	PodOptions *PodIntegrationOptions `json:"podOptions,omitempty"`
+	// RayClusterOptions defines kueue controller behaviour for RayCluster objects
+	RayClusterOptions *RayClusterIntegrationOptions `json:"rayClusterOptions,omitempty"`
 
 	// labelKeysToCopy is a list of label keys that should be copied from the job into the
 	// workload object. It is not required for the job to have all the labels from this
@@ -394,6 +396,24 @@ type PodIntegrationOptions struct {
 	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
 }
 
+type RayClusterIntegrationOptions struct {
+	// WorkerReplicasBasis defines how many pods of an autoscaling worker group
+	// with zero replicas count toward the quota of the RayCluster.
+	// Possible values are:
+	// - Current (default): the replicas of the worker group.
+	// - Min: the minReplicas of the worker group, so that the quota the
+	//   autoscaler needs to scale the group up to its minimum is reserved
+	//   on admission.
+	WorkerReplicasBasis WorkerReplicasBasis `json:"workerReplicasBasis,omitempty"`
+}
+
+type WorkerReplicasBasis string
+
+const (
+	WorkerReplicasCurrent WorkerReplicasBasis = "Current"
+	WorkerReplicasMin     WorkerReplicasBasis = "Min"
+)
+
 type QueueVisibility struct {
 	// ClusterQueues is configuration to expose the information
 	// about the top pending workloads in the cluster queue.
@@ -293,6 +293,11 @@ func (in *Integrations) DeepCopyInto(out *Integrations) {
 		*out = new(PodIntegrationOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RayClusterOptions != nil {
+		in, out := &in.RayClusterOptions, &out.RayClusterOptions
+		*out = new(RayClusterIntegrationOptions)
+		**out = **in
+	}
 	if in.LabelKeysToCopy != nil {
 		in, out := &in.LabelKeysToCopy, &out.LabelKeysToCopy
 		*out = make([]string, len(*in))
@@ -415,6 +420,21 @@ func (in *QueueVisibility) DeepCopy() *QueueVisibility {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RayClusterIntegrationOptions) DeepCopyInto(out *RayClusterIntegrationOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RayClusterIntegrationOptions.
+func (in *RayClusterIntegrationOptions) DeepCopy() *RayClusterIntegrationOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RayClusterIntegrationOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequeuingStrategy) DeepCopyInto(out *RequeuingStrategy) {
 	*out = *in
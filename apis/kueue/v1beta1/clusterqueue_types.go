@@ -27,6 +27,7 @@ const (
 	ClusterQueueActiveReasonTerminating                                     = "Terminating"
 	ClusterQueueActiveReasonStopped                                         = "Stopped"
 	ClusterQueueActiveReasonFlavorNotFound                                  = "FlavorNotFound"
+	ClusterQueueActiveReasonDuplicateFlavor                                 = "DuplicateFlavor"
 	ClusterQueueActiveReasonAdmissionCheckNotFound                          = "AdmissionCheckNotFound"
 	ClusterQueueActiveReasonAdmissionCheckInactive                          = "AdmissionCheckInactive"
 	ClusterQueueActiveReasonMultipleSingleInstanceControllerAdmissionChecks = "MultipleSingleInstanceControllerAdmissionChecks"
@@ -35,6 +36,8 @@ const (
 	ClusterQueueActiveReasonMultiKueueAdmissionCheckAppliedPerFlavor        = "MultiKueueAdmissionCheckAppliedPerFlavor"
 	ClusterQueueActiveReasonNotSupportedWithTopologyAwareScheduling         = "NotSupportedWithTopologyAwareScheduling"
 	ClusterQueueActiveReasonTopologyNotFound                                = "TopologyNotFound"
+	ClusterQueueActiveReasonInvalidDefaultTolerations                       = "InvalidDefaultTolerations"
+	ClusterQueueActiveReasonMultiKueueNoHealthyClusters                     = "MultiKueueNoHealthyClusters"
 	ClusterQueueActiveReasonUnknown                                         = "Unknown"
 	ClusterQueueActiveReasonReady                                           = "Ready"
 )
@@ -19,6 +19,10 @@ package v1beta1
 const (
 	ResourceInUseFinalizerName                 = "kueue.x-k8s.io/resource-in-use"
 	DefaultPodSetName          PodSetReference = "main"
+
+	// OrphanedResourceFlavorName is reserved to report, in the status of the
+	// ClusterQueues, the usage of the ResourceFlavors which were deleted.
+	OrphanedResourceFlavorName ResourceFlavorReference = "orphaned.kueue.x-k8s.io"
 )
 
 type StopPolicy string
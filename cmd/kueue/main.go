@@ -25,6 +25,7 @@ import (
 	"os"
 	"path/filepath"
 
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 	zaplog "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
@@ -218,6 +219,9 @@ func main() {
 	if cfg.FairSharing != nil {
 		cacheOptions = append(cacheOptions, cache.WithFairSharing(cfg.FairSharing.Enable))
 	}
+	if cfg.Metrics.EnableClusterQueueResources {
+		cacheOptions = append(cacheOptions, cache.WithReportResourceMetrics(true))
+	}
 	cCache := cache.New(mgr.GetClient(), cacheOptions...)
 	queues := queue.NewManager(mgr.GetClient(), cCache, queueOptions...)
 
@@ -359,6 +363,9 @@ func setupControllers(ctx context.Context, mgr ctrl.Manager, cCache *cache.Cache
 	if cfg.Integrations.PodOptions != nil {
 		opts = append(opts, jobframework.WithIntegrationOptions(corev1.SchemeGroupVersion.WithKind("Pod").String(), cfg.Integrations.PodOptions))
 	}
+	if cfg.Integrations.RayClusterOptions != nil {
+		opts = append(opts, jobframework.WithIntegrationOptions(rayv1.GroupVersion.WithKind("RayCluster").String(), cfg.Integrations.RayClusterOptions))
+	}
 	if features.Enabled(features.ManagedJobsNamespaceSelector) {
 		nsSelector, err := metav1.LabelSelectorAsSelector(cfg.ManagedJobsNamespaceSelector)
 		if err != nil {
@@ -0,0 +1,84 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// AdmissionCheckSemantics defines which AdmissionChecks of a workload
+// must be Ready for the workload to be admitted in its ClusterQueue.
+type AdmissionCheckSemantics string
+
+const (
+	// AdmissionCheckAll requires all the AdmissionChecks of the workload
+	// to be Ready. This is the default.
+	AdmissionCheckAll AdmissionCheckSemantics = "All"
+	// AdmissionCheckAny requires any of the AdmissionChecks of the
+	// workload to be Ready.
+	AdmissionCheckAny AdmissionCheckSemantics = "Any"
+)
+
+// SetAdmissionCheckSemantics sets which AdmissionChecks must be Ready for
+// the workloads of the ClusterQueue to be admitted. An empty value resets
+// the ClusterQueue to AdmissionCheckAll.
+func (c *Cache) SetAdmissionCheckSemantics(cqName kueue.ClusterQueueReference, s AdmissionCheckSemantics) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.admissionCheckSemantics = s
+	return nil
+}
+
+// AdmissionChecksSatisfied indicates whether the AdmissionChecks of the
+// workload, which reserves quota in a ClusterQueue, allow its admission
+// under the AdmissionCheckSemantics of the ClusterQueue. A workload
+// without AdmissionChecks satisfies them. Under AdmissionCheckAny, a
+// single Ready check is enough as long as no check is Retry or Rejected,
+// because those evict or deactivate the workload regardless of the
+// semantics. It returns false if the workload doesn't reserve quota, and
+// assumes AdmissionCheckAll if its ClusterQueue doesn't exist.
+func (c *Cache) AdmissionChecksSatisfied(wl *kueue.Workload) bool {
+	if !workload.HasQuotaReservation(wl) {
+		return false
+	}
+	if c.admissionCheckSemantics(wl.Status.Admission.ClusterQueue) != AdmissionCheckAny || len(wl.Status.AdmissionChecks) == 0 {
+		return workload.HasAllChecksReady(wl)
+	}
+	if workload.HasRetryChecks(wl) || workload.HasRejectedChecks(wl) {
+		return false
+	}
+	for _, check := range wl.Status.AdmissionChecks {
+		if check.State == kueue.CheckStateReady {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) admissionCheckSemantics(cqName kueue.ClusterQueueReference) AdmissionCheckSemantics {
+	c.RLock()
+	defer c.RUnlock()
+	if cq := c.hm.ClusterQueue(cqName); cq != nil && cq.admissionCheckSemantics != "" {
+		return cq.admissionCheckSemantics
+	}
+	return AdmissionCheckAll
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestAdmissionChecksSatisfied(t *testing.T) {
+	cases := map[string]struct {
+		semantics AdmissionCheckSemantics
+		checks    []kueue.AdmissionCheckState
+		noQuota   bool
+		want      bool
+	}{
+		"all: one passing check": {
+			semantics: AdmissionCheckAll,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+				{Name: "check2", State: kueue.CheckStatePending},
+			},
+		},
+		"all: all checks passing": {
+			semantics: AdmissionCheckAll,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+				{Name: "check2", State: kueue.CheckStateReady},
+			},
+			want: true,
+		},
+		"default: one passing check": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+				{Name: "check2", State: kueue.CheckStatePending},
+			},
+		},
+		"any: one passing check": {
+			semantics: AdmissionCheckAny,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStatePending},
+				{Name: "check2", State: kueue.CheckStateReady},
+			},
+			want: true,
+		},
+		"any: no passing check": {
+			semantics: AdmissionCheckAny,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStatePending},
+				{Name: "check2", State: kueue.CheckStateRetry},
+			},
+		},
+		"any: passing and rejected checks": {
+			semantics: AdmissionCheckAny,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+				{Name: "check2", State: kueue.CheckStateRejected},
+			},
+		},
+		"any: passing and retry checks": {
+			semantics: AdmissionCheckAny,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateRetry},
+				{Name: "check2", State: kueue.CheckStateReady},
+			},
+		},
+		"any: no checks": {
+			semantics: AdmissionCheckAny,
+			want:      true,
+		},
+		"any: no quota reservation": {
+			semantics: AdmissionCheckAny,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+			},
+			noQuota: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+				AdmissionChecks("check1", "check2").
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if err := cache.SetAdmissionCheckSemantics("cq", tc.semantics); err != nil {
+				t.Fatalf("Failed setting the admission check semantics: %v", err)
+			}
+			wl := utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "1").
+				AdmissionChecks(tc.checks...)
+			if !tc.noQuota {
+				wl.ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj())
+			}
+			if got := cache.AdmissionChecksSatisfied(wl.Obj()); got != tc.want {
+				t.Errorf("Unexpected AdmissionChecksSatisfied, want=%v, got=%v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSetAdmissionCheckSemanticsMissingClusterQueue(t *testing.T) {
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.SetAdmissionCheckSemantics("missing", AdmissionCheckAny); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+}
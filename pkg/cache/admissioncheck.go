@@ -16,9 +16,63 @@ limitations under the License.
 
 package cache
 
+import (
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
 type AdmissionCheck struct {
 	Active                       bool
 	Controller                   string
 	SingleInstanceInClusterQueue bool
 	FlavorIndependent            bool
+	// NoHealthyClusters indicates that none of the worker clusters of a
+	// MultiKueue AdmissionCheck is healthy.
+	NoHealthyClusters bool
+}
+
+// AdmissionControllerLastSeen returns the last time an AdmissionCheck
+// managed by the controller was added or updated in the cache, and
+// whether any was seen. The time is kept after the AdmissionChecks are
+// deleted, so that stalled controllers can be detected.
+func (c *Cache) AdmissionControllerLastSeen(controller string) (time.Time, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	lastSeen, found := c.acControllersLastSeen[controller]
+	return lastSeen, found
+}
+
+// PendingChecksForWorkload returns the sorted names of the AdmissionChecks
+// which the workload, reserving quota in the cache, didn't pass yet. These
+// are the checks which are not Ready in its status, plus the checks of its
+// ClusterQueue which are missing from its status. It returns nil if the
+// workload is not reserving quota in the cache.
+func (c *Cache) PendingChecksForWorkload(key string) []string {
+	c.RLock()
+	defer c.RUnlock()
+	for _, cq := range c.hm.ClusterQueues() {
+		wi, found := cq.Workloads[key]
+		if !found {
+			continue
+		}
+		var pending []string
+		for _, check := range wi.Obj.Status.AdmissionChecks {
+			if check.State != kueue.CheckStateReady {
+				pending = append(pending, check.Name)
+			}
+		}
+		for name := range workload.AdmissionChecksForWorkload(logr.Discard(), wi.Obj, cq.AdmissionChecks) {
+			if workload.FindAdmissionCheck(wi.Obj.Status.AdmissionChecks, name) == nil {
+				pending = append(pending, name)
+			}
+		}
+		slices.Sort(pending)
+		return pending
+	}
+	return nil
 }
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestAdmissionControllerLastSeen(t *testing.T) {
+	_, log := utiltesting.ContextWithLog(t)
+	fakeClock := testingclock.NewFakeClock(time.Now().Truncate(time.Second))
+	cache := New(utiltesting.NewFakeClient(), WithClock(t, fakeClock))
+
+	if _, found := cache.AdmissionControllerLastSeen("controller"); found {
+		t.Error("Unexpected last seen time before any AdmissionCheck")
+	}
+
+	ac := utiltesting.MakeAdmissionCheck("ac").ControllerName("controller").Obj()
+	cache.AddOrUpdateAdmissionCheck(log, ac)
+	firstSeen := fakeClock.Now()
+	if got, found := cache.AdmissionControllerLastSeen("controller"); !found || !got.Equal(firstSeen) {
+		t.Errorf("Unexpected last seen time, want=%v, got=%v (found=%v)", firstSeen, got, found)
+	}
+
+	fakeClock.Step(time.Minute)
+	cache.AddOrUpdateAdmissionCheck(log, utiltesting.MakeAdmissionCheck("ac").ControllerName("controller").Active(metav1.ConditionTrue).Obj())
+	if got, _ := cache.AdmissionControllerLastSeen("controller"); !got.Equal(firstSeen.Add(time.Minute)) {
+		t.Errorf("Expected the last seen time to advance, want=%v, got=%v", firstSeen.Add(time.Minute), got)
+	}
+
+	fakeClock.Step(time.Minute)
+	cache.DeleteAdmissionCheck(log, ac)
+	if got, found := cache.AdmissionControllerLastSeen("controller"); !found || !got.Equal(firstSeen.Add(time.Minute)) {
+		t.Errorf("Unexpected last seen time after deleting the AdmissionCheck, want=%v, got=%v (found=%v)", firstSeen.Add(time.Minute), got, found)
+	}
+	if _, found := cache.AdmissionControllerLastSeen("other"); found {
+		t.Error("Unexpected last seen time for another controller")
+	}
+}
+
+func TestPendingChecksForWorkload(t *testing.T) {
+	cases := map[string]struct {
+		checks []kueue.AdmissionCheckState
+		key    string
+		want   []string
+	}{
+		"one passed and one pending check": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+				{Name: "check2", State: kueue.CheckStatePending},
+			},
+			key:  "ns/wl",
+			want: []string{"check2"},
+		},
+		"check missing from the status": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateRetry},
+			},
+			key:  "ns/wl",
+			want: []string{"check1", "check2"},
+		},
+		"all checks passed": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "check1", State: kueue.CheckStateReady},
+				{Name: "check2", State: kueue.CheckStateReady},
+			},
+			key: "ns/wl",
+		},
+		"unknown workload": {
+			key: "ns/other",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+				AdmissionChecks("check1", "check2").
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			wl := utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "1").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+				AdmissionChecks(tc.checks...).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatal("Failed adding workload")
+			}
+			if diff := cmp.Diff(tc.want, cache.PendingChecksForWorkload(tc.key)); diff != "" {
+				t.Errorf("Unexpected pending checks (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+	"slices"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/features"
+)
+
+// BlockerKind is the kind of condition preventing a ClusterQueue from
+// becoming active. The values match the reasons of the Active condition.
+type BlockerKind string
+
+const (
+	BlockerTerminating                                     BlockerKind = kueue.ClusterQueueActiveReasonTerminating
+	BlockerStopped                                         BlockerKind = kueue.ClusterQueueActiveReasonStopped
+	BlockerFlavorNotFound                                  BlockerKind = kueue.ClusterQueueActiveReasonFlavorNotFound
+	BlockerDuplicateFlavor                                 BlockerKind = kueue.ClusterQueueActiveReasonDuplicateFlavor
+	BlockerAdmissionCheckNotFound                          BlockerKind = kueue.ClusterQueueActiveReasonAdmissionCheckNotFound
+	BlockerAdmissionCheckInactive                          BlockerKind = kueue.ClusterQueueActiveReasonAdmissionCheckInactive
+	BlockerMultipleMultiKueueAdmissionChecks               BlockerKind = kueue.ClusterQueueActiveReasonMultipleMultiKueueAdmissionChecks
+	BlockerMultiKueueAdmissionCheckAppliedPerFlavor        BlockerKind = kueue.ClusterQueueActiveReasonMultiKueueAdmissionCheckAppliedPerFlavor
+	BlockerMultipleSingleInstanceControllerAdmissionChecks BlockerKind = kueue.ClusterQueueActiveReasonMultipleSingleInstanceControllerAdmissionChecks
+	BlockerFlavorIndependentAdmissionCheckAppliedPerFlavor BlockerKind = kueue.ClusterQueueActiveReasonFlavorIndependentAdmissionCheckAppliedPerFlavor
+	BlockerNotSupportedWithTopologyAwareScheduling         BlockerKind = kueue.ClusterQueueActiveReasonNotSupportedWithTopologyAwareScheduling
+	BlockerTopologyNotFound                                BlockerKind = kueue.ClusterQueueActiveReasonTopologyNotFound
+	BlockerMultiKueueNoHealthyClusters                     BlockerKind = kueue.ClusterQueueActiveReasonMultiKueueNoHealthyClusters
+	BlockerInvalidDefaultTolerations                       BlockerKind = kueue.ClusterQueueActiveReasonInvalidDefaultTolerations
+)
+
+// Blocker is a condition preventing a ClusterQueue from becoming active.
+type Blocker struct {
+	Kind BlockerKind
+	// Name is the name of the object causing the condition, like the
+	// ResourceFlavor, the AdmissionCheck or the admission check controller.
+	// It is the name of the ClusterQueue for the Terminating, Stopped and
+	// InvalidDefaultTolerations kinds.
+	Name string
+}
+
+// ClusterQueueBlockers returns the conditions preventing the ClusterQueue
+// from becoming active, in the same order as they are reported in the
+// Active condition. It returns nil if the ClusterQueue is active or doesn't
+// exist.
+func (c *Cache) ClusterQueueBlockers(name kueue.ClusterQueueReference) []Blocker {
+	c.RLock()
+	defer c.RUnlock()
+	cq := c.hm.ClusterQueue(name)
+	if cq == nil {
+		return nil
+	}
+	return cq.blockers()
+}
+
+func (c *clusterQueue) blockers() []Blocker {
+	switch c.Status {
+	case terminating:
+		return []Blocker{{Kind: BlockerTerminating, Name: string(c.Name)}}
+	case pending:
+	default:
+		return nil
+	}
+	var blockers []Blocker
+	add := func(kind BlockerKind, names ...string) {
+		for _, n := range names {
+			blockers = append(blockers, Blocker{Kind: kind, Name: n})
+		}
+	}
+	if c.isStopped {
+		add(BlockerStopped, string(c.Name))
+	}
+	for _, f := range c.missingFlavors {
+		add(BlockerFlavorNotFound, string(f))
+	}
+	for _, f := range c.duplicateFlavors {
+		add(BlockerDuplicateFlavor, string(f))
+	}
+	add(BlockerAdmissionCheckNotFound, c.missingAdmissionChecks...)
+	add(BlockerAdmissionCheckInactive, c.inactiveAdmissionChecks...)
+	if len(c.multiKueueAdmissionChecks) > 1 {
+		add(BlockerMultipleMultiKueueAdmissionChecks, c.multiKueueAdmissionChecks...)
+	}
+	add(BlockerMultiKueueAdmissionCheckAppliedPerFlavor, c.perFlavorMultiKueueAdmissionChecks...)
+	add(BlockerMultipleSingleInstanceControllerAdmissionChecks, slices.Sorted(maps.Keys(c.multipleSingleInstanceControllersChecks))...)
+	add(BlockerFlavorIndependentAdmissionCheckAppliedPerFlavor, c.flavorIndependentAdmissionCheckAppliedPerFlavor...)
+	if c.hasNoHealthyClusters() {
+		add(BlockerMultiKueueNoHealthyClusters, c.multiKueueChecksWithoutHealthyClusters...)
+	}
+	if len(c.invalidDefaultTolerations) > 0 {
+		add(BlockerInvalidDefaultTolerations, string(c.Name))
+	}
+	if features.Enabled(features.TopologyAwareScheduling) && len(c.tasFlavors) > 0 {
+		add(BlockerNotSupportedWithTopologyAwareScheduling, c.multiKueueAdmissionChecks...)
+		add(BlockerNotSupportedWithTopologyAwareScheduling, c.provisioningAdmissionChecks...)
+		for _, tasFlavor := range slices.Sorted(maps.Keys(c.tasFlavors)) {
+			if c.tasCache.Get(tasFlavor) == nil {
+				add(BlockerTopologyNotFound, string(tasFlavor))
+			}
+		}
+	}
+	return blockers
+}
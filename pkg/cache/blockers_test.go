@@ -0,0 +1,141 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestClusterQueueBlockers(t *testing.T) {
+	flavor := utiltesting.MakeResourceFlavor("flavor1").Obj()
+	activeCheck := utiltesting.MakeAdmissionCheck("active").Active(metav1.ConditionTrue).Obj()
+	inactiveCheck := utiltesting.MakeAdmissionCheck("inactive").Obj()
+	multiKueueCheck := utiltesting.MakeAdmissionCheck("mk").
+		ControllerName(kueue.MultiKueueControllerName).
+		Active(metav1.ConditionTrue).
+		Obj()
+
+	cases := map[string]struct {
+		clusterQueue       *kueue.ClusterQueue
+		terminate          bool
+		defaultTolerations []corev1.Toleration
+		noHealthyClusters  bool
+		want               []Blocker
+	}{
+		"active": {
+			clusterQueue: utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("flavor1").Resource(corev1.ResourceCPU, "5").Obj()).
+				AdmissionChecks("active").
+				Obj(),
+		},
+		"terminating": {
+			clusterQueue: utiltesting.MakeClusterQueue("cq").Obj(),
+			terminate:    true,
+			want: []Blocker{
+				{Kind: BlockerTerminating, Name: "cq"},
+			},
+		},
+		"multiple blockers": {
+			clusterQueue: utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("flavor1").Resource(corev1.ResourceCPU, "5").Obj(),
+					*utiltesting.MakeFlavorQuotas("flavor2").Resource(corev1.ResourceCPU, "5").Obj(),
+					*utiltesting.MakeFlavorQuotas("flavor3").Resource(corev1.ResourceCPU, "5").Obj(),
+				).
+				AdmissionChecks("active", "inactive", "missing").
+				StopPolicy(kueue.Hold).
+				Obj(),
+			want: []Blocker{
+				{Kind: BlockerStopped, Name: "cq"},
+				{Kind: BlockerFlavorNotFound, Name: "flavor2"},
+				{Kind: BlockerFlavorNotFound, Name: "flavor3"},
+				{Kind: BlockerAdmissionCheckNotFound, Name: "missing"},
+				{Kind: BlockerAdmissionCheckInactive, Name: "inactive"},
+			},
+		},
+		"invalid default tolerations": {
+			clusterQueue: utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("flavor1").Resource(corev1.ResourceCPU, "5").Obj()).
+				Obj(),
+			defaultTolerations: []corev1.Toleration{{Key: "invalid key", Operator: corev1.TolerationOpExists}},
+			want: []Blocker{
+				{Kind: BlockerInvalidDefaultTolerations, Name: "cq"},
+			},
+		},
+		"no healthy MultiKueue worker cluster": {
+			clusterQueue: utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("flavor1").Resource(corev1.ResourceCPU, "5").Obj()).
+				AdmissionChecks("mk").
+				Obj(),
+			noHealthyClusters:  true,
+			defaultTolerations: []corev1.Toleration{{Key: "invalid key", Operator: corev1.TolerationOpExists}},
+			want: []Blocker{
+				{Kind: BlockerMultiKueueNoHealthyClusters, Name: "mk"},
+				{Kind: BlockerInvalidDefaultTolerations, Name: "cq"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, flavor)
+			cache.AddOrUpdateAdmissionCheck(log, activeCheck)
+			cache.AddOrUpdateAdmissionCheck(log, inactiveCheck)
+			cache.AddOrUpdateAdmissionCheck(log, multiKueueCheck)
+			if err := cache.AddClusterQueue(ctx, tc.clusterQueue); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if tc.defaultTolerations != nil {
+				if err := cache.SetDefaultTolerations(log, "cq", tc.defaultTolerations); err != nil {
+					t.Fatalf("Failed setting the default tolerations: %v", err)
+				}
+			}
+			if tc.noHealthyClusters {
+				if err := cache.SetStopWithoutHealthyClusters(log, "cq", true); err != nil {
+					t.Fatalf("Failed setting the stop without healthy clusters: %v", err)
+				}
+				cache.SetMultiKueueClustersHealthy(log, "mk", false)
+			}
+			if tc.terminate {
+				cache.TerminateClusterQueue("cq")
+			}
+			got := cache.ClusterQueueBlockers("cq")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected blockers (-want,+got):\n%s", diff)
+			}
+			// The first blocker is the reason of the Active condition.
+			if _, reason, _ := cache.ClusterQueueReadiness("cq"); len(got) > 0 && string(got[0].Kind) != reason {
+				t.Errorf("First blocker %s doesn't match the readiness reason %s", got[0].Kind, reason)
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		cache := New(utiltesting.NewFakeClient())
+		if got := cache.ClusterQueueBlockers("cq"); got != nil {
+			t.Errorf("Unexpected blockers for a missing ClusterQueue: %v", got)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+var errInvalidBorrowingFraction = errors.New("borrowing fraction must be between 0 and 1")
+
+// SetBorrowingFraction caps how much the ClusterQueue can borrow, for
+// every flavor and resource, to a fraction of the capacity lent to its
+// Cohort by the other members, so that a single ClusterQueue can't
+// monopolize the capacity of the Cohort. The cap is applied on top of the
+// BorrowingLimits of the ClusterQueue. A nil fraction removes the cap.
+func (c *Cache) SetBorrowingFraction(cqName kueue.ClusterQueueReference, fraction *float64) error {
+	if fraction != nil && (*fraction < 0 || *fraction > 1) {
+		return errInvalidBorrowingFraction
+	}
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.borrowingFraction = fraction
+	return nil
+}
+
+// borrowingCappedNode is implemented by the nodes whose borrowing can be
+// capped beyond their BorrowingLimits.
+type borrowingCappedNode interface {
+	// borrowingCap returns the maximum amount of the flavor and resource
+	// which the node can borrow from its parent, and whether it is
+	// capped.
+	borrowingCap(fr resources.FlavorResource) (int64, bool)
+}
+
+var _ borrowingCappedNode = (*ClusterQueueSnapshot)(nil)
+
+// borrowingCap returns the BorrowingFraction of the capacity lent to the
+// Cohort by the other members, which is the SubtreeQuota of the Cohort
+// minus the quota the ClusterQueue lends itself.
+func (c *ClusterQueueSnapshot) borrowingCap(fr resources.FlavorResource) (int64, bool) {
+	if c.BorrowingFraction == nil || !c.HasParent() {
+		return 0, false
+	}
+	lentByOthers := c.Parent().ResourceNode.SubtreeQuota[fr] - (c.ResourceNode.SubtreeQuota[fr] - c.ResourceNode.guaranteedQuota(fr))
+	return int64(*c.BorrowingFraction * float64(max(0, lentByOthers))), true
+}
+
+// borrowingLimit returns the BorrowingLimit of the node for the flavor
+// and resource, lowered to the borrowing cap of the node, if any.
+func borrowingLimit(node hierarchicalResourceNode, fr resources.FlavorResource) *int64 {
+	limit := node.getResourceNode().Quotas[fr].BorrowingLimit
+	if b, ok := node.(borrowingCappedNode); ok {
+		if borrowingCap, capped := b.borrowingCap(fr); capped && (limit == nil || borrowingCap < *limit) {
+			return &borrowingCap
+		}
+	}
+	return limit
+}
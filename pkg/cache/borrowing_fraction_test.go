@@ -0,0 +1,142 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestBorrowingFraction(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		fraction               *float64
+		borrowingLimit         string
+		usage                  string
+		wantAvailable          int64
+		wantPotentialAvailable int64
+	}{
+		"no cap": {
+			wantAvailable:          12_000,
+			wantPotentialAvailable: 12_000,
+		},
+		"capped at 50% of the cohort lendable": {
+			fraction:               ptr.To(0.5),
+			wantAvailable:          7_000,
+			wantPotentialAvailable: 7_000,
+		},
+		"capped at 50% of the cohort lendable, while borrowing": {
+			fraction:               ptr.To(0.5),
+			usage:                  "5",
+			wantAvailable:          2_000,
+			wantPotentialAvailable: 7_000,
+		},
+		"capped at 50% of the cohort lendable, borrowing up to the cap": {
+			fraction:               ptr.To(0.5),
+			usage:                  "7",
+			wantAvailable:          0,
+			wantPotentialAvailable: 7_000,
+		},
+		"cap below the BorrowingLimit": {
+			fraction:               ptr.To(0.25),
+			borrowingLimit:         "8",
+			wantAvailable:          4_500,
+			wantPotentialAvailable: 4_500,
+		},
+		"BorrowingLimit below the cap": {
+			fraction:               ptr.To(0.5),
+			borrowingLimit:         "1",
+			wantAvailable:          3_000,
+			wantPotentialAvailable: 3_000,
+		},
+		"no borrowing": {
+			fraction:               ptr.To(0.0),
+			wantAvailable:          2_000,
+			wantPotentialAvailable: 2_000,
+		},
+		"whole cohort lendable": {
+			fraction:               ptr.To(1.0),
+			wantAvailable:          12_000,
+			wantPotentialAvailable: 12_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2", tc.borrowingLimit).Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("peer").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			if tc.usage != "" {
+				wl := utiltesting.MakeWorkload("wl", "ns").
+					Request(corev1.ResourceCPU, tc.usage).
+					ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", tc.usage).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatal("Failed adding workload")
+				}
+			}
+			if err := cache.SetBorrowingFraction("cq", tc.fraction); err != nil {
+				t.Fatalf("Failed setting the borrowing fraction: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cq := snapshot.ClusterQueue("cq")
+			if got := cq.Available(fr); got != tc.wantAvailable {
+				t.Errorf("Unexpected available, want=%d, got=%d", tc.wantAvailable, got)
+			}
+			if got := cq.PotentialAvailable(fr); got != tc.wantPotentialAvailable {
+				t.Errorf("Unexpected potential available, want=%d, got=%d", tc.wantPotentialAvailable, got)
+			}
+		})
+	}
+}
+
+func TestSetBorrowingFractionErrors(t *testing.T) {
+	ctx, _ := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	if err := cache.SetBorrowingFraction("missing", ptr.To(0.5)); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+	if err := cache.SetBorrowingFraction("cq", ptr.To(1.5)); !errors.Is(err, errInvalidBorrowingFraction) {
+		t.Errorf("Unexpected error for an invalid fraction: %v", err)
+	}
+}
@@ -20,8 +20,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"sync"
+	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -50,6 +55,8 @@ var (
 	ErrCqNotFound          = errors.New("cluster queue not found")
 	errQNotFound           = errors.New("queue not found")
 	errWorkloadNotAdmitted = errors.New("workload not admitted by a ClusterQueue")
+
+	realClock = clock.RealClock{}
 )
 
 const (
@@ -59,9 +66,20 @@ const (
 )
 
 type options struct {
-	workloadInfoOptions []workload.InfoOption
-	podsReadyTracking   bool
-	fairSharingEnabled  bool
+	workloadInfoOptions         []workload.InfoOption
+	podsReadyTracking           bool
+	fairSharingEnabled          bool
+	partialAdmissionGranularity int32
+	clock                       clock.Clock
+	localQueueObservers         []LocalQueueObserver
+	tasCacheObservers           []TASCacheObserver
+	labelKeysObservers          []LabelKeysObserver
+	orphanedFlavorStatusPolicy  OrphanedFlavorStatusPolicy
+	reportResourceMetrics       bool
+	fairSharingUsage            FairSharingUsage
+	cohortDeficitPolicy         CohortDeficitPolicy
+	cohortAggregation           CohortAggregation
+	workloadKeyFunc             WorkloadKeyFunc
 }
 
 // Option configures the reconciler.
@@ -89,13 +107,55 @@ func WithResourceTransformations(transforms []config.ResourceTransformation) Opt
 	}
 }
 
+// WithQuotaGranularity sets, by flavor and resource, the minimum
+// allocation unit to which the usage of the workloads is rounded up, so
+// that admitted workloads don't leave unusably small fragments of quota.
+func WithQuotaGranularity(granularity resources.FlavorResourceQuantities) Option {
+	return func(o *options) {
+		o.workloadInfoOptions = append(o.workloadInfoOptions, workload.WithQuotaGranularity(granularity))
+	}
+}
+
+// WithPodSetAccounting sets how the usage of the podsets of the workloads
+// is combined. PodSetAccountingMax avoids double counting the resources of
+// workloads whose podsets overlap. By default, PodSetAccountingSum is used.
+func WithPodSetAccounting(a workload.PodSetAccounting) Option {
+	return func(o *options) {
+		o.workloadInfoOptions = append(o.workloadInfoOptions, workload.WithPodSetAccounting(a))
+	}
+}
+
 func WithFairSharing(enabled bool) Option {
 	return func(o *options) {
 		o.fairSharingEnabled = enabled
 	}
 }
 
-var defaultOptions = options{}
+// WithPartialAdmissionGranularity sets the multiple to which the pod counts
+// of partially admitted PodSets are rounded down.
+func WithPartialAdmissionGranularity(granularity int32) Option {
+	return func(o *options) {
+		o.partialAdmissionGranularity = granularity
+	}
+}
+
+// WithReportResourceMetrics indicates whether the cache reports the
+// metrics of the resource usage of the ClusterQueues.
+func WithReportResourceMetrics(report bool) Option {
+	return func(o *options) {
+		o.reportResourceMetrics = report
+	}
+}
+
+func WithClock(_ testing.TB, c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+var defaultOptions = options{
+	clock: realClock,
+}
 
 // Cache keeps track of the Workloads that got admitted through ClusterQueues.
 type Cache struct {
@@ -110,6 +170,50 @@ type Cache struct {
 	workloadInfoOptions []workload.InfoOption
 	fairSharingEnabled  bool
 
+	partialAdmissionGranularity int32
+
+	// acControllersLastSeen holds, by controller name, the last
+	// time an AdmissionCheck managed by the controller was updated.
+	acControllersLastSeen map[string]time.Time
+
+	// preemptingWorkloads holds the workloads, by key, which were selected
+	// for preemption and still hold their quota.
+	preemptingWorkloads map[string]preemptionGrace
+	// earmarkedClusterQueues holds, by preemptor key, the ClusterQueues
+	// in which quota is earmarked for the preemptor.
+	earmarkedClusterQueues map[string]sets.Set[kueue.ClusterQueueReference]
+
+	// rejections holds, by workload key, the last rejection of the
+	// workload by the scheduler.
+	rejections map[string]rejection
+
+	// issuedPreemptions holds, by workload key, the ClusterQueue of the
+	// preemptor of the workloads which were preempted and still hold
+	// their quota.
+	issuedPreemptions map[string]kueue.ClusterQueueReference
+	// preemptionsCausedBy holds the number of completed preemptions
+	// attributed to each ClusterQueue.
+	preemptionsCausedBy map[kueue.ClusterQueueReference]int
+	// nodeLabelMismatches holds, by flavor, the number of times a workload
+	// couldn't be assigned the flavor because of a node label mismatch.
+	nodeLabelMismatches map[kueue.ResourceFlavorReference]int
+	// cohortNamespaceSelectors holds, by Cohort, the default
+	// NamespaceSelectors narrowing the ones of the ClusterQueues.
+	cohortNamespaceSelectors map[kueue.CohortReference]labels.Selector
+	// requeues holds, by workload key, how many times the workloads were
+	// added back to the cache after being removed.
+	requeues map[string]requeueState
+
+	clock                      clock.Clock
+	localQueueObservers        []LocalQueueObserver
+	tasCacheObservers          []TASCacheObserver
+	labelKeysObservers         []LabelKeysObserver
+	orphanedFlavorStatusPolicy OrphanedFlavorStatusPolicy
+	reportResourceMetrics      bool
+	fairSharingUsage           FairSharingUsage
+	cohortDeficitPolicy        CohortDeficitPolicy
+	workloadKeyFunc            WorkloadKeyFunc
+
 	hm hierarchy.Manager[*clusterQueue, *cohort]
 
 	tasCache tasCache
@@ -121,15 +225,34 @@ func New(client client.Client, opts ...Option) *Cache {
 		opt(&options)
 	}
 	c := &Cache{
-		client:              client,
-		assumedWorkloads:    make(map[string]kueue.ClusterQueueReference),
-		resourceFlavors:     make(map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor),
-		admissionChecks:     make(map[string]AdmissionCheck),
-		podsReadyTracking:   options.podsReadyTracking,
-		workloadInfoOptions: options.workloadInfoOptions,
-		fairSharingEnabled:  options.fairSharingEnabled,
-		hm:                  hierarchy.NewManager[*clusterQueue, *cohort](newCohort),
-		tasCache:            NewTASCache(client),
+		client:                      client,
+		assumedWorkloads:            make(map[string]kueue.ClusterQueueReference),
+		resourceFlavors:             make(map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor),
+		admissionChecks:             make(map[string]AdmissionCheck),
+		acControllersLastSeen:       make(map[string]time.Time),
+		podsReadyTracking:           options.podsReadyTracking,
+		workloadInfoOptions:         options.workloadInfoOptions,
+		fairSharingEnabled:          options.fairSharingEnabled,
+		partialAdmissionGranularity: options.partialAdmissionGranularity,
+		preemptingWorkloads:         make(map[string]preemptionGrace),
+		earmarkedClusterQueues:      make(map[string]sets.Set[kueue.ClusterQueueReference]),
+		rejections:                  make(map[string]rejection),
+		issuedPreemptions:           make(map[string]kueue.ClusterQueueReference),
+		preemptionsCausedBy:         make(map[kueue.ClusterQueueReference]int),
+		nodeLabelMismatches:         make(map[kueue.ResourceFlavorReference]int),
+		cohortNamespaceSelectors:    make(map[kueue.CohortReference]labels.Selector),
+		requeues:                    make(map[string]requeueState),
+		clock:                       options.clock,
+		localQueueObservers:         options.localQueueObservers,
+		tasCacheObservers:           options.tasCacheObservers,
+		labelKeysObservers:          options.labelKeysObservers,
+		orphanedFlavorStatusPolicy:  options.orphanedFlavorStatusPolicy,
+		reportResourceMetrics:       options.reportResourceMetrics,
+		fairSharingUsage:            options.fairSharingUsage,
+		cohortDeficitPolicy:         options.cohortDeficitPolicy,
+		workloadKeyFunc:             options.workloadKeyFunc,
+		hm:                          hierarchy.NewManager[*clusterQueue, *cohort](newCohortWithAggregation(options.cohortAggregation)),
+		tasCache:                    NewTASCache(client),
 	}
 	c.podsReadyCond.L = &c.RWMutex
 	return c
@@ -137,15 +260,17 @@ func New(client client.Client, opts ...Option) *Cache {
 
 func (c *Cache) newClusterQueue(log logr.Logger, cq *kueue.ClusterQueue) (*clusterQueue, error) {
 	cqImpl := &clusterQueue{
-		Name:                kueue.ClusterQueueReference(cq.Name),
-		Workloads:           make(map[string]*workload.Info),
-		WorkloadsNotReady:   sets.New[string](),
-		localQueues:         make(map[string]*queue),
-		podsReadyTracking:   c.podsReadyTracking,
-		workloadInfoOptions: c.workloadInfoOptions,
-		AdmittedUsage:       make(resources.FlavorResourceQuantities),
-		resourceNode:        NewResourceNode(),
-		tasCache:            &c.tasCache,
+		Name:                  kueue.ClusterQueueReference(cq.Name),
+		Workloads:             make(map[string]*workload.Info),
+		WorkloadsNotReady:     sets.New[string](),
+		localQueues:           make(map[string]*queue),
+		podsReadyTracking:     c.podsReadyTracking,
+		reportResourceMetrics: c.reportResourceMetrics,
+		workloadInfoOptions:   c.workloadInfoOptions,
+		workloadKeyFunc:       c.workloadKeyFunc,
+		AdmittedUsage:         make(resources.FlavorResourceQuantities),
+		resourceNode:          NewResourceNode(),
+		tasCache:              &c.tasCache,
 
 		workloadsNotAccountedForTAS: sets.New[string](),
 	}
@@ -220,11 +345,15 @@ func (c *Cache) updateClusterQueues(log logr.Logger) sets.Set[kueue.ClusterQueue
 
 	for _, cq := range c.hm.ClusterQueues() {
 		prevStatus := cq.Status
+		prevLabelKeys := cq.labelKeys()
 		// We call update on all ClusterQueues irrespective of which CQ actually use this flavor
 		// because it is not expensive to do so, and is not worth tracking which ClusterQueues use
 		// which flavors.
 		cq.UpdateWithFlavors(log, c.resourceFlavors)
 		cq.updateWithAdmissionChecks(log, c.admissionChecks)
+		if !cq.labelKeysEqual(prevLabelKeys) {
+			c.notifyLabelKeysChanged(cq.Name)
+		}
 		curStatus := cq.Status
 		if prevStatus == pending && curStatus == active {
 			cqs.Insert(cq.Name)
@@ -254,7 +383,7 @@ func (c *Cache) AddOrUpdateResourceFlavor(log logr.Logger, rf *kueue.ResourceFla
 	defer c.Unlock()
 	c.resourceFlavors[kueue.ResourceFlavorReference(rf.Name)] = rf
 	if handleTASFlavor(rf) {
-		c.tasCache.AddFlavor(rf)
+		c.tasFlavorsSynced(log, c.tasCache.AddFlavor(rf))
 	}
 	return c.updateClusterQueues(log)
 }
@@ -263,6 +392,7 @@ func (c *Cache) DeleteResourceFlavor(log logr.Logger, rf *kueue.ResourceFlavor)
 	c.Lock()
 	defer c.Unlock()
 	delete(c.resourceFlavors, kueue.ResourceFlavorReference(rf.Name))
+	delete(c.nodeLabelMismatches, kueue.ResourceFlavorReference(rf.Name))
 	if handleTASFlavor(rf) {
 		c.tasCache.DeleteFlavor(kueue.ResourceFlavorReference(rf.Name))
 	}
@@ -272,7 +402,7 @@ func (c *Cache) DeleteResourceFlavor(log logr.Logger, rf *kueue.ResourceFlavor)
 func (c *Cache) AddOrUpdateTopology(log logr.Logger, topology *kueuealpha.Topology) sets.Set[kueue.ClusterQueueReference] {
 	c.Lock()
 	defer c.Unlock()
-	c.tasCache.AddTopology(topology)
+	c.tasFlavorsSynced(log, c.tasCache.AddTopology(topology))
 	return c.updateClusterQueues(log)
 }
 
@@ -304,7 +434,10 @@ func (c *Cache) AddOrUpdateAdmissionCheck(log logr.Logger, ac *kueue.AdmissionCh
 		newAC.SingleInstanceInClusterQueue = true
 		newAC.FlavorIndependent = true
 	}
+	// The health of the worker clusters is fed separately.
+	newAC.NoHealthyClusters = c.admissionChecks[ac.Name].NoHealthyClusters
 	c.admissionChecks[ac.Name] = newAC
+	c.acControllersLastSeen[newAC.Controller] = c.clock.Now()
 
 	return c.updateClusterQueues(log)
 }
@@ -332,6 +465,24 @@ func (c *Cache) AdmissionChecksForClusterQueue(cqName kueue.ClusterQueueReferenc
 	return acs
 }
 
+// AdmissionCheckStrategyFlavors returns the flavors on which the
+// AdmissionCheck applies in the ClusterQueue. An empty set means that it
+// applies on all the flavors. It returns nil if the ClusterQueue doesn't
+// exist or doesn't use the AdmissionCheck.
+func (c *Cache) AdmissionCheckStrategyFlavors(cqName kueue.ClusterQueueReference, acName string) sets.Set[kueue.ResourceFlavorReference] {
+	c.RLock()
+	defer c.RUnlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return nil
+	}
+	flavors, found := cq.AdmissionChecks[acName]
+	if !found {
+		return nil
+	}
+	return flavors.Clone()
+}
+
 func (c *Cache) ClusterQueueActive(name kueue.ClusterQueueReference) bool {
 	return c.clusterQueueInStatus(name, active)
 }
@@ -418,13 +569,14 @@ func (c *Cache) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) err
 		}
 		qImpl.resetFlavorsAndResources(cqImpl.resourceNode.Usage, cqImpl.AdmittedUsage)
 		cqImpl.localQueues[qKey] = qImpl
+		c.notifyLocalQueueAdded(cqImpl.Name, qKey)
 	}
 	var workloads kueue.WorkloadList
 	if err := c.client.List(ctx, &workloads, client.MatchingFields{utilindexer.WorkloadClusterQueueKey: cq.Name}); err != nil {
 		return fmt.Errorf("listing workloads that match the queue: %w", err)
 	}
 	for i, w := range workloads.Items {
-		log := log.WithValues("workload", workload.Key(&w))
+		log := log.WithValues("workload", c.WorkloadKey(&w))
 		if !workload.HasQuotaReservation(&w) || workload.IsFinished(&w) {
 			continue
 		}
@@ -463,11 +615,23 @@ func (c *Cache) DeleteClusterQueue(cq *kueue.ClusterQueue) {
 	if curCq == nil {
 		return
 	}
-	if features.Enabled(features.LocalQueueMetrics) {
-		for _, q := range c.hm.ClusterQueue(cqName).localQueues {
+	for _, q := range curCq.localQueues {
+		if features.Enabled(features.LocalQueueMetrics) {
 			metrics.ClearLocalQueueCacheMetrics(metrics.LQRefFromLocalQueueKey(q.key))
 		}
+		c.notifyLocalQueueDeleted(cqName, q.key)
 	}
+	for k, grace := range c.preemptingWorkloads {
+		if grace.clusterQueue == cqName {
+			delete(c.preemptingWorkloads, k)
+		}
+	}
+	for preemptor, cqs := range c.earmarkedClusterQueues {
+		if cqs.Delete(cqName).Len() == 0 {
+			delete(c.earmarkedClusterQueues, preemptor)
+		}
+	}
+	c.forgetPreemptionsCausedBy(cqName)
 	c.hm.DeleteClusterQueue(cqName)
 	metrics.ClearCacheMetrics(cq.Name)
 }
@@ -503,7 +667,7 @@ func (c *Cache) AddLocalQueue(q *kueue.LocalQueue) error {
 	if cq == nil {
 		return nil
 	}
-	return cq.addLocalQueue(q)
+	return c.addLocalQueue(cq, q)
 }
 
 func (c *Cache) DeleteLocalQueue(q *kueue.LocalQueue) {
@@ -513,7 +677,7 @@ func (c *Cache) DeleteLocalQueue(q *kueue.LocalQueue) {
 	if cq == nil {
 		return
 	}
-	cq.deleteLocalQueue(q)
+	c.deleteLocalQueue(cq, q)
 }
 
 func (c *Cache) UpdateLocalQueue(oldQ, newQ *kueue.LocalQueue) error {
@@ -524,11 +688,11 @@ func (c *Cache) UpdateLocalQueue(oldQ, newQ *kueue.LocalQueue) error {
 	defer c.Unlock()
 	cq := c.hm.ClusterQueue(oldQ.Spec.ClusterQueue)
 	if cq != nil {
-		cq.deleteLocalQueue(oldQ)
+		c.deleteLocalQueue(cq, oldQ)
 	}
 	cq = c.hm.ClusterQueue(newQ.Spec.ClusterQueue)
 	if cq != nil {
-		return cq.addLocalQueue(newQ)
+		return c.addLocalQueue(cq, newQ)
 	}
 	return nil
 }
@@ -551,25 +715,57 @@ func (c *Cache) addOrUpdateWorkload(log logr.Logger, w *kueue.Workload) bool {
 
 	c.cleanupAssumedState(log, w)
 
-	if _, exist := clusterQueue.Workloads[workload.Key(w)]; exist {
+	var prevAdmittedUsage resources.FlavorResourceQuantities
+	var prevReservingNotAdmitted bool
+	if _, exist := clusterQueue.Workloads[c.WorkloadKey(w)]; exist {
+		prevAdmittedUsage = clusterQueue.admittedUsageOf(c.WorkloadKey(w))
+		prevReservingNotAdmitted = clusterQueue.reservingNotAdmitted(c.WorkloadKey(w))
 		clusterQueue.deleteWorkload(log, w)
 	}
 
 	if c.podsReadyTracking {
 		c.podsReadyCond.Broadcast()
 	}
-	return clusterQueue.addWorkload(log, w) == nil
+	if err := clusterQueue.addWorkload(log, w); err != nil {
+		return false
+	}
+	c.recordWorkloadAdded(c.WorkloadKey(w))
+	if c.reportResourceMetrics {
+		clusterQueue.reportUsageIncrease(c.WorkloadKey(w), prevAdmittedUsage)
+	}
+	if prevReservingNotAdmitted && workload.IsAdmitted(w) {
+		clusterQueue.reportAdmissionCheckWait(w)
+		clusterQueue.reservationOutcomes.Admitted++
+	}
+	c.releaseEarmarks(log, c.WorkloadKey(w))
+	delete(c.rejections, c.WorkloadKey(w))
+	return true
 }
 
 func (c *Cache) UpdateWorkload(log logr.Logger, oldWl, newWl *kueue.Workload) error {
 	c.Lock()
 	defer c.Unlock()
+	var prevAdmittedUsage resources.FlavorResourceQuantities
+	var prevReservingNotAdmitted bool
 	if workload.HasQuotaReservation(oldWl) {
 		cq := c.hm.ClusterQueue(oldWl.Status.Admission.ClusterQueue)
 		if cq == nil {
 			return errors.New("old ClusterQueue doesn't exist")
 		}
+		if workload.HasQuotaReservation(newWl) && newWl.Status.Admission.ClusterQueue == cq.Name {
+			prevAdmittedUsage = cq.admittedUsageOf(c.WorkloadKey(oldWl))
+			prevReservingNotAdmitted = cq.reservingNotAdmitted(c.WorkloadKey(oldWl))
+		} else if cq.reservingNotAdmitted(c.WorkloadKey(oldWl)) {
+			cq.reservationOutcomes.Dropped++
+		}
+		var usage resources.FlavorResourceQuantities
+		if wl, found := cq.Workloads[c.WorkloadKey(oldWl)]; found && !workload.HasQuotaReservation(newWl) {
+			usage = wl.FlavorResourceUsage()
+		}
 		cq.deleteWorkload(log, oldWl)
+		if usage != nil {
+			c.completePreemption(log, c.WorkloadKey(oldWl), usage)
+		}
 	}
 	c.cleanupAssumedState(log, oldWl)
 
@@ -583,13 +779,27 @@ func (c *Cache) UpdateWorkload(log logr.Logger, oldWl, newWl *kueue.Workload) er
 	if c.podsReadyTracking {
 		c.podsReadyCond.Broadcast()
 	}
-	return cq.addWorkload(log, newWl)
+	if err := cq.addWorkload(log, newWl); err != nil {
+		return err
+	}
+	if c.reportResourceMetrics {
+		cq.reportUsageIncrease(c.WorkloadKey(newWl), prevAdmittedUsage)
+	}
+	if prevReservingNotAdmitted && workload.IsAdmitted(newWl) {
+		cq.reportAdmissionCheckWait(newWl)
+		cq.reservationOutcomes.Admitted++
+	}
+	c.releaseEarmarks(log, c.WorkloadKey(newWl))
+	delete(c.rejections, c.WorkloadKey(newWl))
+	return nil
 }
 
 func (c *Cache) DeleteWorkload(log logr.Logger, w *kueue.Workload) error {
 	c.Lock()
 	defer c.Unlock()
 
+	c.releaseEarmarks(log, c.WorkloadKey(w))
+
 	cq := c.clusterQueueForWorkload(w)
 	if cq == nil {
 		return ErrCqNotFound
@@ -597,7 +807,19 @@ func (c *Cache) DeleteWorkload(log logr.Logger, w *kueue.Workload) error {
 
 	c.cleanupAssumedState(log, w)
 
+	k := c.WorkloadKey(w)
+	var usage resources.FlavorResourceQuantities
+	if wl, found := cq.Workloads[k]; found {
+		usage = wl.FlavorResourceUsage()
+	}
+	if cq.reservingNotAdmitted(k) {
+		cq.reservationOutcomes.Dropped++
+	}
 	cq.forgetWorkload(log, w)
+	if usage != nil {
+		c.completePreemption(log, k, usage)
+		c.recordWorkloadRemoved(k)
+	}
 	if c.podsReadyTracking {
 		c.podsReadyCond.Broadcast()
 	}
@@ -608,7 +830,7 @@ func (c *Cache) IsAssumedOrAdmittedWorkload(w workload.Info) bool {
 	c.RLock()
 	defer c.RUnlock()
 
-	k := workload.Key(w.Obj)
+	k := c.WorkloadKey(w.Obj)
 	if _, assumed := c.assumedWorkloads[k]; assumed {
 		return true
 	}
@@ -628,7 +850,7 @@ func (c *Cache) AssumeWorkload(log logr.Logger, w *kueue.Workload) error {
 		return errWorkloadNotAdmitted
 	}
 
-	k := workload.Key(w)
+	k := c.WorkloadKey(w)
 	assumedCq, assumed := c.assumedWorkloads[k]
 	if assumed {
 		return fmt.Errorf("the workload is already assumed to ClusterQueue %q", assumedCq)
@@ -642,7 +864,10 @@ func (c *Cache) AssumeWorkload(log logr.Logger, w *kueue.Workload) error {
 	if err := cq.addWorkload(log, w); err != nil {
 		return err
 	}
+	c.recordWorkloadAdded(k)
 	c.assumedWorkloads[k] = w.Status.Admission.ClusterQueue
+	c.releaseEarmarks(log, k)
+	delete(c.rejections, k)
 	return nil
 }
 
@@ -650,7 +875,7 @@ func (c *Cache) ForgetWorkload(log logr.Logger, w *kueue.Workload) error {
 	c.Lock()
 	defer c.Unlock()
 
-	if _, assumed := c.assumedWorkloads[workload.Key(w)]; !assumed {
+	if _, assumed := c.assumedWorkloads[c.WorkloadKey(w)]; !assumed {
 		return errors.New("the workload is not assumed")
 	}
 	c.cleanupAssumedState(log, w)
@@ -689,20 +914,124 @@ func (c *Cache) Usage(cqObj *kueue.ClusterQueue) (*ClusterQueueUsageStats, error
 	}
 
 	stats := &ClusterQueueUsageStats{
-		ReservedResources:  getUsage(cq.resourceNode.Usage, cq),
+		ReservedResources:  c.getUsageWithOrphaned(cq.resourceNode.Usage, cq),
 		ReservingWorkloads: len(cq.Workloads),
-		AdmittedResources:  getUsage(cq.AdmittedUsage, cq),
+		AdmittedResources:  c.getUsageWithOrphaned(cq.AdmittedUsage, cq),
 		AdmittedWorkloads:  cq.admittedWorkloadsCount,
 	}
 
 	if c.fairSharingEnabled {
-		weightedShare, _ := dominantResourceShare(cq, nil)
+		weightedShare, _ := dominantResourceShare(cq, nil, c.fairSharingUsage)
 		stats.WeightedShare = int64(weightedShare)
 	}
 
 	return stats, nil
 }
 
+// UsageByPriorityClass returns the usage of the workloads admitted in the
+// ClusterQueue, grouped by their priority class name. Workloads without a
+// priority class are grouped under the empty name. It returns nil if the
+// ClusterQueue doesn't exist.
+func (c *Cache) UsageByPriorityClass(cqName kueue.ClusterQueueReference) map[string]resources.FlavorResourceQuantities {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return nil
+	}
+	usage := make(map[string]resources.FlavorResourceQuantities)
+	for _, wi := range cq.Workloads {
+		if !workload.IsAdmitted(wi.Obj) {
+			continue
+		}
+		pc := wi.Obj.Spec.PriorityClassName
+		if usage[pc] == nil {
+			usage[pc] = make(resources.FlavorResourceQuantities)
+		}
+		updateFlavorUsage(wi.FlavorResourceUsage(), usage[pc], 1)
+	}
+	return usage
+}
+
+// WorkloadsUsingPriorityClass returns the sorted keys of the workloads
+// reserving quota in any ClusterQueue whose priority class is pc. Their
+// preemption ordering changes when the priority class is deleted.
+func (c *Cache) WorkloadsUsingPriorityClass(pc string) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	var keys []string
+	for _, cq := range c.hm.ClusterQueues() {
+		for key, wi := range cq.Workloads {
+			if wi.Obj.Spec.PriorityClassName == pc {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// WorkloadsAdmittedAfter returns the sorted keys of the workloads in the
+// ClusterQueue which were admitted in the cache when the generation of
+// the ClusterQueue was greater than gen.
+func (c *Cache) WorkloadsAdmittedAfter(cqName kueue.ClusterQueueReference, gen int64) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return nil
+	}
+	var keys []string
+	for k, wi := range cq.Workloads {
+		if wi.AdmittedGeneration() > gen {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// ProjectedUsage returns the usage of the ClusterQueue, including the
+// workloads reserving quota, plus the usage of the pending workloads, as
+// if there was enough capacity to admit them. This represents the demand
+// on the ClusterQueue, regardless of its quota. The pending workloads are
+// expected to come from the queue manager, which tracks them. Their
+// requests are counted against the first flavor of the ResourceGroups
+// covering them. The pending workloads requesting resources which the
+// ClusterQueue doesn't cover, and which could never be admitted, are
+// skipped. It returns nil if the ClusterQueue doesn't exist.
+func (c *Cache) ProjectedUsage(cqName kueue.ClusterQueueReference, pending []*workload.Info) resources.FlavorResourceQuantities {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return nil
+	}
+	usage := maps.Clone(cq.resourceNode.Usage)
+	for _, wi := range pending {
+		wlUsage := make(resources.FlavorResourceQuantities)
+		covered := true
+		for _, ps := range wi.TotalRequests {
+			for rName, q := range ps.Requests {
+				rg := cq.rgByResource(rName)
+				if rg == nil || len(rg.Flavors) == 0 {
+					covered = false
+					break
+				}
+				wlUsage[resources.FlavorResource{Flavor: rg.Flavors[0], Resource: rName}] += q
+			}
+		}
+		if covered {
+			updateFlavorUsage(wlUsage, usage, 1)
+		}
+	}
+	return usage
+}
+
 type CohortUsageStats struct {
 	WeightedShare int64
 }
@@ -718,7 +1047,7 @@ func (c *Cache) CohortStats(cohortObj *kueuealpha.Cohort) (*CohortUsageStats, er
 
 	stats := &CohortUsageStats{}
 	if c.fairSharingEnabled {
-		weightedShare, _ := dominantResourceShare(cohort, nil)
+		weightedShare, _ := dominantResourceShare(cohort, nil, c.fairSharingUsage)
 		stats.WeightedShare = int64(weightedShare)
 	}
 
@@ -884,7 +1213,7 @@ func filterLocalQueueUsage(orig resources.FlavorResourceQuantities, resourceGrou
 }
 
 func (c *Cache) cleanupAssumedState(log logr.Logger, w *kueue.Workload) {
-	k := workload.Key(w)
+	k := c.WorkloadKey(w)
 	assumedCQName, assumed := c.assumedWorkloads[k]
 	if assumed {
 		// If the workload's assigned ClusterQueue is different from the assumed
@@ -902,7 +1231,7 @@ func (c *Cache) clusterQueueForWorkload(w *kueue.Workload) *clusterQueue {
 	if workload.HasQuotaReservation(w) {
 		return c.hm.ClusterQueue(w.Status.Admission.ClusterQueue)
 	}
-	wKey := workload.Key(w)
+	wKey := c.WorkloadKey(w)
 	for _, cq := range c.hm.ClusterQueues() {
 		if cq.Workloads[wKey] != nil {
 			return cq
@@ -911,6 +1240,8 @@ func (c *Cache) clusterQueueForWorkload(w *kueue.Workload) *clusterQueue {
 	return nil
 }
 
+// ClusterQueuesUsingFlavor returns the names of the ClusterQueues whose
+// ResourceGroups include the flavor, regardless of their Cohorts.
 func (c *Cache) ClusterQueuesUsingFlavor(flavor kueue.ResourceFlavorReference) []kueue.ClusterQueueReference {
 	c.RLock()
 	defer c.RUnlock()
@@ -924,6 +1255,25 @@ func (c *Cache) ClusterQueuesUsingFlavor(flavor kueue.ResourceFlavorReference) [
 	return cqs
 }
 
+// EmptyCohorts returns the sorted names of the Cohorts which have no active
+// ClusterQueue in their subtree, including those with no ClusterQueues at
+// all. Cohorts which are part of a cycle are not reported.
+func (c *Cache) EmptyCohorts() []string {
+	c.RLock()
+	defer c.RUnlock()
+	var empty []string
+	for name, cohort := range c.hm.Cohorts() {
+		if hierarchy.HasCycle(cohort) {
+			continue
+		}
+		if !cohort.hasActiveMember() {
+			empty = append(empty, string(name))
+		}
+	}
+	slices.Sort(empty)
+	return empty
+}
+
 func (c *Cache) ClusterQueuesUsingTopology(tName kueue.TopologyReference) []kueue.ClusterQueueReference {
 	c.RLock()
 	defer c.RUnlock()
@@ -20,11 +20,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	dto "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -35,13 +38,16 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kueuealpha "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/hierarchy"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/resources"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	testingmetrics "sigs.k8s.io/kueue/pkg/util/testing/metrics"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -1112,7 +1118,7 @@ func TestCacheClusterQueueOperations(t *testing.T) {
 			}
 			if diff := cmp.Diff(tc.wantClusterQueues, cache.hm.ClusterQueues(),
 				cmpopts.IgnoreFields(clusterQueue{}, "ResourceGroups"),
-				cmpopts.IgnoreFields(workload.Info{}, "Obj", "LastAssignment"),
+				cmpopts.IgnoreFields(workload.Info{}, "Obj", "LastAssignment", "AllocatableResourceGeneration"),
 				cmpopts.IgnoreUnexported(clusterQueue{}, hierarchy.ClusterQueue[*cohort]{}),
 				cmpopts.EquateEmpty()); diff != "" {
 				t.Errorf("Unexpected clusterQueues (-want,+got):\n%s", diff)
@@ -2794,6 +2800,29 @@ func TestClusterQueuesUsingFlavor(t *testing.T) {
 				kueue.ClusterQueueReference(fizzCq.Name),
 			},
 		},
+		{
+			name: "clusterQueues in different cohorts sharing the flavor",
+			clusterQueues: []*kueue.ClusterQueue{
+				fooCq,
+				utiltesting.MakeClusterQueue("cohortACq").
+					Cohort("cohort-a").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("x86").Resource("cpu", "5").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("cohortBCq").
+					Cohort("cohort-b").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("x86").Resource("cpu", "5").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("cohortBOtherCq").
+					Cohort("cohort-b").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("aarch64").Resource("cpu", "3").Obj()).
+					Obj(),
+			},
+			wantInUseClusterQueueNames: []kueue.ClusterQueueReference{
+				kueue.ClusterQueueReference(fooCq.Name),
+				"cohortACq",
+				"cohortBCq",
+			},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -3339,6 +3368,59 @@ func TestClusterQueuesUsingAdmissionChecks(t *testing.T) {
 	}
 }
 
+func TestAdmissionCheckStrategyFlavors(t *testing.T) {
+	cq := utiltesting.MakeClusterQueue("cq").
+		AdmissionCheckStrategy(
+			*utiltesting.MakeAdmissionCheckStrategyRule("ac1", "on-demand", "spot").Obj(),
+			*utiltesting.MakeAdmissionCheckStrategyRule("ac2").Obj()).
+		Obj()
+
+	cases := map[string]struct {
+		cq      kueue.ClusterQueueReference
+		check   string
+		want    sets.Set[kueue.ResourceFlavorReference]
+		wantNil bool
+	}{
+		"flavor-scoped rule": {
+			cq:    "cq",
+			check: "ac1",
+			want:  sets.New[kueue.ResourceFlavorReference]("on-demand", "spot"),
+		},
+		"rule applying on all the flavors": {
+			cq:    "cq",
+			check: "ac2",
+			want:  sets.New[kueue.ResourceFlavorReference](),
+		},
+		"check not used by the clusterQueue": {
+			cq:      "cq",
+			check:   "ac3",
+			wantNil: true,
+		},
+		"unknown clusterQueue": {
+			cq:      "other",
+			check:   "ac1",
+			wantNil: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding clusterQueue: %v", err)
+			}
+
+			got := cache.AdmissionCheckStrategyFlavors(tc.cq, tc.check)
+			if gotNil := got == nil; gotNil != tc.wantNil {
+				t.Errorf("Unexpected nil result, want=%v, got=%v", tc.wantNil, gotNil)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Unexpected flavors (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestClusterQueueReadiness(t *testing.T) {
 	baseFlavor := utiltesting.MakeResourceFlavor("flavor1").Obj()
 	baseCheck := utiltesting.MakeAdmissionCheck("check1").Active(metav1.ConditionTrue).Obj()
@@ -3420,6 +3502,21 @@ func TestClusterQueueReadiness(t *testing.T) {
 			wantMessage:      "Can admit new workloads",
 			wantActive:       true,
 		},
+		"duplicate flavor": {
+			clusterQueues: []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("queue1").
+					ResourceGroup(
+						*utiltesting.MakeFlavorQuotas(baseFlavor.Name).Resource(corev1.ResourceCPU, "10").Obj(),
+						*utiltesting.MakeFlavorQuotas(baseFlavor.Name).Resource(corev1.ResourceCPU, "5").Obj(),
+					).
+					Obj(),
+			},
+			resourceFlavors:  []*kueue.ResourceFlavor{baseFlavor},
+			clusterQueueName: "queue1",
+			wantStatus:       metav1.ConditionFalse,
+			wantReason:       "DuplicateFlavor",
+			wantMessage:      "Can't admit new workloads: references ResourceFlavor(s) more than once in a resource group: [flavor1].",
+		},
 		"stopped": {
 			clusterQueues:    []*kueue.ClusterQueue{utiltesting.MakeClusterQueue("queue1").StopPolicy(kueue.HoldAndDrain).Obj()},
 			clusterQueueName: "queue1",
@@ -3918,3 +4015,591 @@ func TestClusterQueueAncestors(t *testing.T) {
 		})
 	}
 }
+
+func TestAdmittedGeneration(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admission := utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()
+	first := utiltesting.MakeWorkload("first", "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Obj()
+	if !cache.AddOrUpdateWorkload(log, first) {
+		t.Fatal("Failed adding workload")
+	}
+	cqImpl := cache.hm.ClusterQueue("cq")
+	firstGeneration := cqImpl.AllocatableResourceGeneration
+	if got := cqImpl.Workloads[workload.Key(first)].AdmittedGeneration(); got != firstGeneration {
+		t.Errorf("Unexpected admitted generation, want=%d, got=%d", firstGeneration, got)
+	}
+
+	cq = utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "8").Obj()).
+		Obj()
+	if err := cache.UpdateClusterQueue(log, cq); err != nil {
+		t.Fatalf("Failed updating ClusterQueue: %v", err)
+	}
+	second := utiltesting.MakeWorkload("second", "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Obj()
+	if !cache.AddOrUpdateWorkload(log, second) {
+		t.Fatal("Failed adding workload")
+	}
+	if cqImpl.AllocatableResourceGeneration == firstGeneration {
+		t.Fatal("Expected the generation to change after the quota change")
+	}
+	if got := cqImpl.Workloads[workload.Key(first)].AdmittedGeneration(); got != firstGeneration {
+		t.Errorf("Unexpected admitted generation for the first workload, want=%d, got=%d", firstGeneration, got)
+	}
+	if got := cqImpl.Workloads[workload.Key(second)].AdmittedGeneration(); got != cqImpl.AllocatableResourceGeneration {
+		t.Errorf("Unexpected admitted generation for the second workload, want=%d, got=%d", cqImpl.AllocatableResourceGeneration, got)
+	}
+}
+
+func TestWorkloadsAdmittedAfter(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admission := utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()
+	addWorkloads := func(names ...string) {
+		for _, name := range names {
+			wl := utiltesting.MakeWorkload(name, "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatalf("Failed adding workload %q", name)
+			}
+		}
+	}
+	addWorkloads("old-1", "old-2")
+	firstGeneration := cache.hm.ClusterQueue("cq").AllocatableResourceGeneration
+
+	cq = utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "8").Obj()).
+		Obj()
+	if err := cache.UpdateClusterQueue(log, cq); err != nil {
+		t.Fatalf("Failed updating ClusterQueue: %v", err)
+	}
+	addWorkloads("new-2", "new-1")
+	secondGeneration := cache.hm.ClusterQueue("cq").AllocatableResourceGeneration
+
+	cases := map[string]struct {
+		cqName kueue.ClusterQueueReference
+		gen    int64
+		want   []string
+	}{
+		"all workloads": {
+			cqName: "cq",
+			gen:    0,
+			want:   []string{"ns/new-1", "ns/new-2", "ns/old-1", "ns/old-2"},
+		},
+		"after the first generation": {
+			cqName: "cq",
+			gen:    firstGeneration,
+			want:   []string{"ns/new-1", "ns/new-2"},
+		},
+		"after the current generation": {
+			cqName: "cq",
+			gen:    secondGeneration,
+		},
+		"unknown ClusterQueue": {
+			cqName: "missing",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := cache.WorkloadsAdmittedAfter(tc.cqName, tc.gen)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected workloads (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUsageByPriorityClass(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	makeWorkload := func(name, priorityClass, cpu string, admitted bool) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			PriorityClass(priorityClass).
+			Request(corev1.ResourceCPU, cpu).
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+			Admitted(admitted).
+			Obj()
+	}
+	for _, wl := range []*kueue.Workload{
+		makeWorkload("high-1", "high", "1", true),
+		makeWorkload("high-2", "high", "2", true),
+		makeWorkload("low-1", "low", "3", true),
+		makeWorkload("low-reserving", "low", "4", false),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	got := cache.UsageByPriorityClass("cq")
+	want := map[string]resources.FlavorResourceQuantities{
+		"high": {fr: 3_000},
+		"low":  {fr: 3_000},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected usage by priority class (-want,+got):\n%s", diff)
+	}
+	total := make(resources.FlavorResourceQuantities)
+	for _, usage := range got {
+		updateFlavorUsage(usage, total, 1)
+	}
+	if diff := cmp.Diff(cache.hm.ClusterQueue("cq").AdmittedUsage, total); diff != "" {
+		t.Errorf("Usage by priority class doesn't add up to the admitted usage (-want,+got):\n%s", diff)
+	}
+	if got := cache.UsageByPriorityClass("missing"); got != nil {
+		t.Errorf("Unexpected usage for a missing ClusterQueue: %v", got)
+	}
+}
+
+func TestWorkloadsUsingPriorityClass(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq-a").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-b").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	makeWorkload := func(name, priorityClass, cq string) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			PriorityClass(priorityClass).
+			Request(corev1.ResourceCPU, "1").
+			ReserveQuota(utiltesting.MakeAdmission(cq).Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+			Obj()
+	}
+	for _, wl := range []*kueue.Workload{
+		makeWorkload("high-a", "high", "cq-a"),
+		makeWorkload("high-b", "high", "cq-b"),
+		makeWorkload("low-a", "low", "cq-a"),
+		makeWorkload("no-class", "", "cq-b"),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+
+	cases := map[string]struct {
+		priorityClass string
+		want          []string
+	}{
+		"priority class used in several ClusterQueues": {
+			priorityClass: "high",
+			want:          []string{"ns/high-a", "ns/high-b"},
+		},
+		"priority class used once": {
+			priorityClass: "low",
+			want:          []string{"ns/low-a"},
+		},
+		"unused priority class": {
+			priorityClass: "medium",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, cache.WorkloadsUsingPriorityClass(tc.priorityClass)); diff != "" {
+				t.Errorf("Unexpected workloads using the priority class (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEmptyCohorts(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cohort := range []*kueuealpha.Cohort{
+		utiltesting.MakeCohort("no-members").Obj(),
+		utiltesting.MakeCohort("parent").Obj(),
+		utiltesting.MakeCohort("child").Parent("parent").Obj(),
+	} {
+		if err := cache.AddOrUpdateCohort(cohort); err != nil {
+			t.Fatalf("Failed adding Cohort: %v", err)
+		}
+	}
+	for _, cq := range []*kueue.ClusterQueue{
+		// Pending, as it references a missing flavor.
+		utiltesting.MakeClusterQueue("pending").
+			Cohort("only-pending").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("missing").Resource(corev1.ResourceCPU, "1").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("active").
+			Cohort("with-active").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "1").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("active-in-child").
+			Cohort("child").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "1").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+
+	want := []string{"no-members", "only-pending"}
+	if diff := cmp.Diff(want, cache.EmptyCohorts()); diff != "" {
+		t.Errorf("Unexpected empty cohorts (-want,+got):\n%s", diff)
+	}
+}
+
+func TestProjectedUsage(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+			*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4").Obj(),
+		).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admitted := utiltesting.MakeWorkload("admitted", "ns").
+		Request(corev1.ResourceCPU, "3").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "spot", "3").Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, admitted) {
+		t.Fatal("Failed adding workload")
+	}
+	onDemand := resources.FlavorResource{Flavor: "on-demand", Resource: corev1.ResourceCPU}
+	spot := resources.FlavorResource{Flavor: "spot", Resource: corev1.ResourceCPU}
+
+	cases := map[string]struct {
+		cqName  kueue.ClusterQueueReference
+		pending []*kueue.Workload
+		want    resources.FlavorResourceQuantities
+	}{
+		"no pending workloads": {
+			cqName: "cq",
+			want:   resources.FlavorResourceQuantities{spot: 3_000},
+		},
+		"pending demand above the quota": {
+			cqName: "cq",
+			pending: []*kueue.Workload{
+				utiltesting.MakeWorkload("pending-1", "ns").Request(corev1.ResourceCPU, "3").Obj(),
+				utiltesting.MakeWorkload("pending-2", "ns").
+					PodSets(*utiltesting.MakePodSet("main", 2).Request(corev1.ResourceCPU, "2").Obj()).
+					Obj(),
+			},
+			want: resources.FlavorResourceQuantities{onDemand: 7_000, spot: 3_000},
+		},
+		"pending workload requesting an uncovered resource": {
+			cqName: "cq",
+			pending: []*kueue.Workload{
+				utiltesting.MakeWorkload("pending", "ns").
+					Request(corev1.ResourceCPU, "1").
+					Request("example.com/gpu", "1").
+					Obj(),
+			},
+			want: resources.FlavorResourceQuantities{spot: 3_000},
+		},
+		"unknown ClusterQueue": {
+			cqName: "missing",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pending := make([]*workload.Info, len(tc.pending))
+			for i, wl := range tc.pending {
+				pending[i] = workload.NewInfo(wl)
+			}
+			got := cache.ProjectedUsage(tc.cqName, pending)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected projected usage (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportResourceUsageIncrease(t *testing.T) {
+	cases := map[string]struct {
+		report       bool
+		wantIncrease float64
+	}{
+		"reporting resource metrics": {
+			report:       true,
+			wantIncrease: 4,
+		},
+		"not reporting resource metrics": {},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cqName := "usage-increase"
+			t.Cleanup(func() { metrics.ClearClusterQueueResourceMetrics(cqName) })
+			cache := New(utiltesting.NewFakeClient(), WithReportResourceMetrics(tc.report))
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue(cqName).
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			admission := utiltesting.MakeAdmission(cqName).Assignment(corev1.ResourceCPU, "default", "1").Obj()
+			newWorkload := func(name string) *kueue.Workload {
+				return utiltesting.MakeWorkload(name, "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Obj()
+			}
+
+			// A burst of admissions.
+			for _, name := range []string{"a", "b", "c"} {
+				if !cache.AddOrUpdateWorkload(log, utiltesting.MakeWorkload(name, "ns").
+					Request(corev1.ResourceCPU, "1").
+					ReserveQuota(admission).
+					Admitted(true).
+					Obj()) {
+					t.Fatalf("Failed adding workload %q", name)
+				}
+			}
+			// Updates of admitted workloads don't increase the usage.
+			admitted := utiltesting.MakeWorkload("a", "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Admitted(true).Obj()
+			if !cache.AddOrUpdateWorkload(log, admitted) {
+				t.Fatal("Failed updating workload")
+			}
+			if err := cache.UpdateWorkload(log, admitted, admitted.DeepCopy()); err != nil {
+				t.Fatalf("Failed updating workload: %v", err)
+			}
+			// Reserving quota doesn't increase the usage, until the workload is admitted.
+			reserving := newWorkload("d")
+			if !cache.AddOrUpdateWorkload(log, reserving) {
+				t.Fatal("Failed adding workload")
+			}
+			admittedLater := reserving.DeepCopy()
+			apimeta.SetStatusCondition(&admittedLater.Status.Conditions, metav1.Condition{
+				Type:   kueue.WorkloadAdmitted,
+				Status: metav1.ConditionTrue,
+				Reason: "Admitted",
+			})
+			if err := cache.UpdateWorkload(log, reserving, admittedLater); err != nil {
+				t.Fatalf("Failed updating workload: %v", err)
+			}
+
+			var got float64
+			for _, dp := range testingmetrics.CollectFilteredGaugeVec(metrics.ClusterQueueResourceUsageIncreaseTotal, map[string]string{"cluster_queue": cqName}) {
+				got += dp.Value
+			}
+			if got != tc.wantIncrease {
+				t.Errorf("Unexpected usage increase, want=%v, got=%v", tc.wantIncrease, got)
+			}
+		})
+	}
+}
+
+func TestReportAdmissionCheckWait(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cqName := "admission-check-wait"
+	t.Cleanup(func() { metrics.ClearClusterQueueMetrics(cqName) })
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue(cqName).
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admission := utiltesting.MakeAdmission(cqName).Assignment(corev1.ResourceCPU, "default", "1").Obj()
+	reservedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	reserving := func(name string) *kueue.Workload {
+		wl := utiltesting.MakeWorkload(name, "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Obj()
+		apimeta.FindStatusCondition(wl.Status.Conditions, kueue.WorkloadQuotaReserved).LastTransitionTime = metav1.NewTime(reservedAt)
+		return wl
+	}
+	admit := func(wl *kueue.Workload, wait time.Duration) *kueue.Workload {
+		admitted := wl.DeepCopy()
+		apimeta.SetStatusCondition(&admitted.Status.Conditions, metav1.Condition{
+			Type:               kueue.WorkloadAdmitted,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Admitted",
+			LastTransitionTime: metav1.NewTime(reservedAt.Add(wait)),
+		})
+		return admitted
+	}
+
+	// Admitted through an update of the workload.
+	a := reserving("a")
+	if !cache.AddOrUpdateWorkload(log, a) {
+		t.Fatal("Failed adding workload")
+	}
+	if err := cache.UpdateWorkload(log, a, admit(a, 10*time.Second)); err != nil {
+		t.Fatalf("Failed updating workload: %v", err)
+	}
+	// Admitted through a re-add of the workload, which is only observed once.
+	b := reserving("b")
+	if !cache.AddOrUpdateWorkload(log, b) {
+		t.Fatal("Failed adding workload")
+	}
+	for range 2 {
+		if !cache.AddOrUpdateWorkload(log, admit(b, 30*time.Second)) {
+			t.Fatal("Failed updating workload")
+		}
+	}
+	// Workloads added to the cache after their admission were not observed
+	// waiting for their admission checks.
+	if !cache.AddOrUpdateWorkload(log, admit(reserving("c"), time.Minute)) {
+		t.Fatal("Failed adding workload")
+	}
+
+	h := admissionCheckWaitHistogram(t, cqName)
+	if got := h.GetSampleCount(); got != 2 {
+		t.Errorf("Unexpected number of observations, want=2, got=%d", got)
+	}
+	if got := h.GetSampleSum(); got != 40 {
+		t.Errorf("Unexpected sum of observations, want=40, got=%v", got)
+	}
+}
+
+var registerMetrics sync.Once
+
+// admissionCheckWaitHistogram gathers the kueue_admission_check_wait_seconds
+// histogram of the ClusterQueue from the metrics registry.
+func admissionCheckWaitHistogram(t *testing.T, cqName string) *dto.Histogram {
+	t.Helper()
+	registerMetrics.Do(metrics.Register)
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed gathering the metrics: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != "kueue_admission_check_wait_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "cluster_queue" && l.GetValue() == cqName {
+					return m.GetHistogram()
+				}
+			}
+		}
+	}
+	t.Fatalf("Histogram not found for ClusterQueue %q", cqName)
+	return nil
+}
+
+func TestQuotaGranularity(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cache := New(utiltesting.NewFakeClient(), WithQuotaGranularity(resources.FlavorResourceQuantities{fr: 1_000}))
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	wl := utiltesting.MakeWorkload("wl", "ns").
+		Request(corev1.ResourceCPU, "1500m").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1500m").Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, wl) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	if got := snapshot.ClusterQueue("cq").ResourceNode.Usage[fr]; got != 2_000 {
+		t.Errorf("Unexpected usage, want=2000, got=%d", got)
+	}
+	if got := snapshot.ClusterQueue("cq").Available(fr); got != 2_000 {
+		t.Errorf("Unexpected available, want=2000, got=%d", got)
+	}
+	if err := cache.DeleteWorkload(log, wl); err != nil {
+		t.Fatalf("Failed deleting workload: %v", err)
+	}
+	if got := cache.hm.ClusterQueue("cq").resourceNode.Usage[fr]; got != 0 {
+		t.Errorf("Unexpected usage after deleting the workload, want=0, got=%d", got)
+	}
+}
+
+func TestPodSetAccounting(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		accounting workload.PodSetAccounting
+		want       int64
+	}{
+		"summed": {
+			accounting: workload.PodSetAccountingSum,
+			want:       5_000,
+		},
+		"max'd": {
+			accounting: workload.PodSetAccountingMax,
+			want:       3_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), WithPodSetAccounting(tc.accounting))
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			wl := utiltesting.MakeWorkload("wl", "ns").
+				PodSets(
+					*utiltesting.MakePodSet("launcher", 1).Request(corev1.ResourceCPU, "2").Obj(),
+					*utiltesting.MakePodSet("worker", 1).Request(corev1.ResourceCPU, "3").Obj(),
+				).
+				ReserveQuota(utiltesting.MakeAdmission("cq").
+					PodSets(
+						kueue.PodSetAssignment{
+							Name:          "launcher",
+							Flavors:       map[corev1.ResourceName]kueue.ResourceFlavorReference{corev1.ResourceCPU: "default"},
+							ResourceUsage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+							Count:         ptr.To[int32](1),
+						},
+						kueue.PodSetAssignment{
+							Name:          "worker",
+							Flavors:       map[corev1.ResourceName]kueue.ResourceFlavorReference{corev1.ResourceCPU: "default"},
+							ResourceUsage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+							Count:         ptr.To[int32](1),
+						},
+					).
+					Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatal("Failed adding workload")
+			}
+			if got := cache.hm.ClusterQueue("cq").resourceNode.Usage[fr]; got != tc.want {
+				t.Errorf("Unexpected usage, want=%d, got=%d", tc.want, got)
+			}
+			if err := cache.DeleteWorkload(log, wl); err != nil {
+				t.Fatalf("Failed deleting workload: %v", err)
+			}
+			if got := cache.hm.ClusterQueue("cq").resourceNode.Usage[fr]; got != 0 {
+				t.Errorf("Unexpected usage after deleting the workload, want=0, got=%d", got)
+			}
+		})
+	}
+}
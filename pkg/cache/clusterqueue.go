@@ -22,8 +22,10 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -39,6 +41,7 @@ import (
 	"sigs.k8s.io/kueue/pkg/resources"
 	utilac "sigs.k8s.io/kueue/pkg/util/admissioncheck"
 	"sigs.k8s.io/kueue/pkg/util/api"
+	utilresource "sigs.k8s.io/kueue/pkg/util/resource"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -70,7 +73,9 @@ type clusterQueue struct {
 	// localQueues by (namespace/name).
 	localQueues                                     map[string]*queue
 	podsReadyTracking                               bool
+	reportResourceMetrics                           bool
 	missingFlavors                                  []kueue.ResourceFlavorReference
+	duplicateFlavors                                []kueue.ResourceFlavorReference
 	missingAdmissionChecks                          []string
 	inactiveAdmissionChecks                         []string
 	multipleSingleInstanceControllersChecks         map[string][]string // key = controllerName
@@ -78,10 +83,12 @@ type clusterQueue struct {
 	multiKueueAdmissionChecks                       []string
 	provisioningAdmissionChecks                     []string
 	perFlavorMultiKueueAdmissionChecks              []string
+	multiKueueChecksWithoutHealthyClusters          []string
 	tasFlavors                                      map[kueue.ResourceFlavorReference]kueue.TopologyReference
 	admittedWorkloadsCount                          int
 	isStopped                                       bool
 	workloadInfoOptions                             []workload.InfoOption
+	workloadKeyFunc                                 WorkloadKeyFunc
 
 	resourceNode ResourceNode
 	hierarchy.ClusterQueue[*cohort]
@@ -89,6 +96,62 @@ type clusterQueue struct {
 	tasCache *tasCache
 
 	workloadsNotAccountedForTAS sets.Set[string]
+
+	// resourceTranslations holds, by resource, the factor by which the
+	// requests of the workloads are multiplied when computing their usage.
+	resourceTranslations map[corev1.ResourceName]resource.Quantity
+
+	// earmarks holds, by preemptor key, the usage released by preempted
+	// workloads which is reserved for their preemptor.
+	earmarks map[string]resources.FlavorResourceQuantities
+
+	// reclaimEvictionOrder is the order in which the workloads of other
+	// ClusterQueues are evicted when the ClusterQueue reclaims its quota.
+	reclaimEvictionOrder ReclaimEvictionOrder
+
+	// lenderBorrowingLimits limits how much the ClusterQueue can borrow
+	// from each of the ClusterQueues of its Cohort.
+	lenderBorrowingLimits LenderBorrowingLimits
+
+	// defaultPriority is the priority of the workloads without a priority
+	// class when ordering the preemption candidates.
+	defaultPriority *int32
+
+	// borrowingFraction caps how much the ClusterQueue can borrow to a
+	// fraction of the capacity lent to its Cohort by the other members.
+	borrowingFraction *float64
+
+	// reservationOutcomes counts how the quota reservations of the
+	// workloads waiting for their admission checks concluded.
+	reservationOutcomes ReservationOutcomes
+
+	// admissionCheckSemantics defines which AdmissionChecks must be Ready
+	// for the workloads to be admitted.
+	admissionCheckSemantics AdmissionCheckSemantics
+
+	// maxFlavorsToTry limits how many flavors of a ResourceGroup are
+	// tried for a resource in a single flavor assignment.
+	maxFlavorsToTry *int32
+
+	// preemptionBudget limits how many workloads can be preempted to
+	// admit the workloads of the ClusterQueue within a time window.
+	preemptionBudget *PreemptionBudget
+	// budgetedPreemptions holds, oldest first, the times of the
+	// preemptions charged to the preemption budget within its window.
+	budgetedPreemptions []time.Time
+
+	// invalidDefaultTolerations holds the errors found validating the
+	// default tolerations.
+	invalidDefaultTolerations []string
+
+	// stopWithoutHealthyClusters stops the admission of workloads while
+	// none of the worker clusters of its MultiKueue AdmissionCheck is
+	// healthy.
+	stopWithoutHealthyClusters bool
+
+	// resourceGroupPriorities holds, by covered resource, the priorities
+	// ordering the evaluation of the ResourceGroups.
+	resourceGroupPriorities map[corev1.ResourceName]int32
 }
 
 func (c *clusterQueue) GetName() kueue.ClusterQueueReference {
@@ -111,6 +174,13 @@ type queue struct {
 	admittedWorkloads  int
 	totalReserved      resources.FlavorResourceQuantities
 	admittedUsage      resources.FlavorResourceQuantities
+	// weight is the admission weight of the queue, 0 means the default of 1.
+	weight int64
+	// currentWeight is the running weight for the weighted round-robin.
+	currentWeight int64
+	// quotaCap limits the quota which the workloads of the queue can
+	// reserve, by flavor and resource.
+	quotaCap resources.FlavorResourceQuantities
 }
 
 func (c *clusterQueue) Active() bool {
@@ -125,7 +195,8 @@ var defaultPreemption = kueue.ClusterQueuePreemption{
 var defaultFlavorFungibility = kueue.FlavorFungibility{WhenCanBorrow: kueue.Borrow, WhenCanPreempt: kueue.TryNextFlavor}
 
 func (c *clusterQueue) updateClusterQueue(log logr.Logger, in *kueue.ClusterQueue, resourceFlavors map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor, admissionChecks map[string]AdmissionCheck, oldParent *cohort) error {
-	if c.updateQuotasAndResourceGroups(in.Spec.ResourceGroups) || oldParent != c.Parent() {
+	resourcesUpdated := c.updateQuotasAndResourceGroups(in.Spec.ResourceGroups) || oldParent != c.Parent()
+	if resourcesUpdated {
 		if oldParent != nil && oldParent != c.Parent() {
 			// ignore error when old Cohort has cycle.
 			_ = updateCohortTreeResources(oldParent)
@@ -141,6 +212,7 @@ func (c *clusterQueue) updateClusterQueue(log logr.Logger, in *kueue.ClusterQueu
 			updateClusterQueueResourceNode(c)
 		}
 	}
+	c.duplicateFlavors = findDuplicateFlavors(in.Spec.ResourceGroups)
 
 	nsSelector, err := metav1.LabelSelectorAsSelector(in.Spec.NamespaceSelector)
 	if err != nil {
@@ -175,6 +247,11 @@ func (c *clusterQueue) updateClusterQueue(log logr.Logger, in *kueue.ClusterQueu
 
 	c.FairWeight = parseFairWeight(in.Spec.FairSharing)
 
+	if resourcesUpdated && c.reportResourceMetrics {
+		// Drop the series of the previous Cohort and of the removed quotas.
+		metrics.ClearClusterQueueResourceOvercommit(string(c.Name))
+	}
+	c.reportResourceOvercommit()
 	return nil
 }
 
@@ -186,12 +263,31 @@ func createdResourceGroups(kueueRgs []kueue.ResourceGroup) []ResourceGroup {
 			Flavors:          make([]kueue.ResourceFlavorReference, 0, len(kueueRg.Flavors)),
 		}
 		for _, fIn := range kueueRg.Flavors {
-			rgs[i].Flavors = append(rgs[i].Flavors, fIn.Name)
+			// A duplicated flavor is only kept at its first position.
+			if !slices.Contains(rgs[i].Flavors, fIn.Name) {
+				rgs[i].Flavors = append(rgs[i].Flavors, fIn.Name)
+			}
 		}
 	}
 	return rgs
 }
 
+// findDuplicateFlavors returns the flavors which are listed more than once
+// in a resource group.
+func findDuplicateFlavors(kueueRgs []kueue.ResourceGroup) []kueue.ResourceFlavorReference {
+	var duplicates []kueue.ResourceFlavorReference
+	for _, kueueRg := range kueueRgs {
+		seen := sets.New[kueue.ResourceFlavorReference]()
+		for _, fIn := range kueueRg.Flavors {
+			if seen.Has(fIn.Name) && !slices.Contains(duplicates, fIn.Name) {
+				duplicates = append(duplicates, fIn.Name)
+			}
+			seen.Insert(fIn.Name)
+		}
+	}
+	return duplicates
+}
+
 // updateQuotasAndResourceGroups updates Quotas and ResourceGroups.
 // It returns true if any changes were made.
 func (c *clusterQueue) updateQuotasAndResourceGroups(in []kueue.ResourceGroup) bool {
@@ -207,27 +303,17 @@ func (c *clusterQueue) updateQuotasAndResourceGroups(in []kueue.ResourceGroup) b
 }
 
 func (c *clusterQueue) updateQueueStatus(log logr.Logger) {
-	if features.Enabled(features.TopologyAwareScheduling) &&
-		len(c.tasFlavors) > 0 &&
-		len(c.workloadsNotAccountedForTAS) > 0 &&
-		c.isTASSynced() {
-		log.V(2).Info("Delayed accounting for TAS usage for workloads", "count", len(c.workloadsNotAccountedForTAS))
-		// There are some workloads which are not accounted yet for TAS.
-		// We re-add them as not the tasCache is initialized (synced).
-		for k, w := range c.Workloads {
-			if c.workloadsNotAccountedForTAS.Has(k) {
-				c.addOrUpdateWorkload(log, w.Obj)
-				c.workloadsNotAccountedForTAS.Delete(k)
-			}
-		}
-	}
+	c.accountDelayedTASUsage(log)
 	status := active
 	if c.isStopped ||
 		len(c.missingFlavors) > 0 ||
+		len(c.duplicateFlavors) > 0 ||
 		len(c.missingAdmissionChecks) > 0 ||
 		len(c.inactiveAdmissionChecks) > 0 ||
 		len(c.multipleSingleInstanceControllersChecks) > 0 ||
 		len(c.flavorIndependentAdmissionCheckAppliedPerFlavor) > 0 ||
+		len(c.invalidDefaultTolerations) > 0 ||
+		c.hasNoHealthyClusters() ||
 		c.isTASViolated() ||
 		// one multikueue admission check is allowed
 		len(c.multiKueueAdmissionChecks) > 1 ||
@@ -244,6 +330,26 @@ func (c *clusterQueue) updateQueueStatus(log logr.Logger) {
 	}
 }
 
+// accountDelayedTASUsage accounts the TAS usage of the workloads added
+// before the tasCache was synced for all the TAS flavors of the
+// ClusterQueue.
+func (c *clusterQueue) accountDelayedTASUsage(log logr.Logger) {
+	if features.Enabled(features.TopologyAwareScheduling) &&
+		len(c.tasFlavors) > 0 &&
+		len(c.workloadsNotAccountedForTAS) > 0 &&
+		c.isTASSynced() {
+		log.V(2).Info("Delayed accounting for TAS usage for workloads", "count", len(c.workloadsNotAccountedForTAS))
+		// There are some workloads which are not accounted yet for TAS.
+		// We re-add them as not the tasCache is initialized (synced).
+		for k, w := range c.Workloads {
+			if c.workloadsNotAccountedForTAS.Has(k) {
+				c.addOrUpdateWorkload(log, w.Obj)
+				c.workloadsNotAccountedForTAS.Delete(k)
+			}
+		}
+	}
+}
+
 func (c *clusterQueue) isTASSynced() bool {
 	for tasFlavor := range c.tasFlavors {
 		if c.tasCache.Get(tasFlavor) == nil {
@@ -268,6 +374,10 @@ func (c *clusterQueue) inactiveReason() (string, string) {
 			reasons = append(reasons, kueue.ClusterQueueActiveReasonFlavorNotFound)
 			messages = append(messages, fmt.Sprintf("references missing ResourceFlavor(s): %v", c.missingFlavors))
 		}
+		if len(c.duplicateFlavors) > 0 {
+			reasons = append(reasons, kueue.ClusterQueueActiveReasonDuplicateFlavor)
+			messages = append(messages, fmt.Sprintf("references ResourceFlavor(s) more than once in a resource group: %v", c.duplicateFlavors))
+		}
 		if len(c.missingAdmissionChecks) > 0 {
 			reasons = append(reasons, kueue.ClusterQueueActiveReasonAdmissionCheckNotFound)
 			messages = append(messages, fmt.Sprintf("references missing AdmissionCheck(s): %v", c.missingAdmissionChecks))
@@ -300,6 +410,16 @@ func (c *clusterQueue) inactiveReason() (string, string) {
 			messages = append(messages, fmt.Sprintf("AdmissionCheck(s): %v cannot be set at flavor level", c.flavorIndependentAdmissionCheckAppliedPerFlavor))
 		}
 
+		if c.hasNoHealthyClusters() {
+			reasons = append(reasons, kueue.ClusterQueueActiveReasonMultiKueueNoHealthyClusters)
+			messages = append(messages, fmt.Sprintf("no healthy worker cluster for MultiKueue AdmissionCheck(s): %s", strings.Join(c.multiKueueChecksWithoutHealthyClusters, ",")))
+		}
+
+		if len(c.invalidDefaultTolerations) > 0 {
+			reasons = append(reasons, kueue.ClusterQueueActiveReasonInvalidDefaultTolerations)
+			messages = append(messages, fmt.Sprintf("has invalid default tolerations: %s", strings.Join(c.invalidDefaultTolerations, "; ")))
+		}
+
 		if features.Enabled(features.TopologyAwareScheduling) && len(c.tasFlavors) > 0 {
 			if len(c.multiKueueAdmissionChecks) > 0 {
 				reasons = append(reasons, kueue.ClusterQueueActiveReasonNotSupportedWithTopologyAwareScheduling)
@@ -371,6 +491,8 @@ func (c *clusterQueue) updateLabelKeys(flavors map[kueue.ResourceFlavorReference
 
 		if keys.Len() > 0 {
 			rg.LabelKeys = keys
+		} else {
+			rg.LabelKeys = nil
 		}
 	}
 }
@@ -385,6 +507,7 @@ func (c *clusterQueue) updateWithAdmissionChecks(log logr.Logger, checks map[str
 	var inactive []string
 	var flavorIndependentCheckOnFlavors []string
 	var perFlavorMultiKueueChecks []string
+	var withoutHealthyClusters []string
 	for acName, flavors := range c.AdmissionChecks {
 		if ac, found := checks[acName]; !found {
 			missing = append(missing, acName)
@@ -411,6 +534,9 @@ func (c *clusterQueue) updateWithAdmissionChecks(log logr.Logger, checks map[str
 				if flavors.Len() != 0 {
 					perFlavorMultiKueueChecks = append(perFlavorMultiKueueChecks, acName)
 				}
+				if ac.NoHealthyClusters {
+					withoutHealthyClusters = append(withoutHealthyClusters, acName)
+				}
 			}
 		}
 	}
@@ -420,6 +546,7 @@ func (c *clusterQueue) updateWithAdmissionChecks(log logr.Logger, checks map[str
 	slices.Sort(inactive)
 	slices.Sort(flavorIndependentCheckOnFlavors)
 	slices.Sort(perFlavorMultiKueueChecks)
+	slices.Sort(withoutHealthyClusters)
 	multiKueueChecks := sets.List(multiKueueAdmissionChecks)
 	provisioningChecks := sets.List(provisioningAdmissionChecks)
 
@@ -471,13 +598,18 @@ func (c *clusterQueue) updateWithAdmissionChecks(log logr.Logger, checks map[str
 		update = true
 	}
 
+	if !slices.Equal(c.multiKueueChecksWithoutHealthyClusters, withoutHealthyClusters) {
+		c.multiKueueChecksWithoutHealthyClusters = withoutHealthyClusters
+		update = true
+	}
+
 	if update {
 		c.updateQueueStatus(log)
 	}
 }
 
 func (c *clusterQueue) addWorkload(log logr.Logger, w *kueue.Workload) error {
-	k := workload.Key(w)
+	k := c.workloadKey(w)
 	if _, exist := c.Workloads[k]; exist {
 		return errors.New("workload already exists in ClusterQueue")
 	}
@@ -486,11 +618,14 @@ func (c *clusterQueue) addWorkload(log logr.Logger, w *kueue.Workload) error {
 }
 
 func (c *clusterQueue) addOrUpdateWorkload(log logr.Logger, w *kueue.Workload) {
-	k := workload.Key(w)
-	if _, exist := c.Workloads[k]; exist {
+	k := c.workloadKey(w)
+	generation := c.AllocatableResourceGeneration
+	if old, exist := c.Workloads[k]; exist {
+		// Keep the generation the workload was first admitted under.
+		generation = old.AdmittedGeneration()
 		c.deleteWorkload(log, w)
 	}
-	wi := workload.NewInfo(w, c.workloadInfoOptions...)
+	wi := workload.NewInfo(w, append(c.infoOptions(), workload.WithAdmittedGeneration(generation))...)
 	c.Workloads[k] = wi
 	c.updateWorkloadUsage(log, wi, 1)
 	if c.podsReadyTracking && !apimeta.IsStatusConditionTrue(w.Status.Conditions, kueue.WorkloadPodsReady) {
@@ -501,11 +636,11 @@ func (c *clusterQueue) addOrUpdateWorkload(log logr.Logger, w *kueue.Workload) {
 
 func (c *clusterQueue) forgetWorkload(log logr.Logger, w *kueue.Workload) {
 	c.deleteWorkload(log, w)
-	delete(c.workloadsNotAccountedForTAS, workload.Key(w))
+	delete(c.workloadsNotAccountedForTAS, c.workloadKey(w))
 }
 
 func (c *clusterQueue) deleteWorkload(log logr.Logger, w *kueue.Workload) {
-	k := workload.Key(w)
+	k := c.workloadKey(w)
 	wi, exist := c.Workloads[k]
 	if !exist {
 		return
@@ -563,13 +698,72 @@ func (c *clusterQueue) updateWorkloadUsage(log logr.Logger, wi *workload.Info, m
 			lq.reportActiveWorkloads()
 		}
 	}
+	c.reportResourceOvercommit()
+}
+
+// admittedUsageOf returns the usage of the workload, if it is admitted in
+// the ClusterQueue.
+func (c *clusterQueue) admittedUsageOf(k string) resources.FlavorResourceQuantities {
+	if wi, found := c.Workloads[k]; found && workload.IsAdmitted(wi.Obj) {
+		return wi.FlavorResourceUsage()
+	}
+	return nil
+}
+
+// reportUsageIncrease reports how much the admitted usage of the workload
+// increased from its previous admitted usage.
+func (c *clusterQueue) reportUsageIncrease(k string, prevAdmittedUsage resources.FlavorResourceQuantities) {
+	var cohortName kueue.CohortReference
+	if c.HasParent() {
+		cohortName = c.Parent().Name
+	}
+	for fr, q := range c.admittedUsageOf(k) {
+		if increase := q - prevAdmittedUsage[fr]; increase > 0 {
+			quantity := resources.ResourceQuantity(fr.Resource, increase)
+			metrics.ReportClusterQueueResourceUsageIncrease(cohortName, string(c.Name), string(fr.Flavor), string(fr.Resource), utilresource.QuantityToFloat(&quantity))
+		}
+	}
+}
+
+// reportResourceOvercommit reports, for each flavor and resource with
+// quota, the usage of the ClusterQueue above the nominal quota.
+func (c *clusterQueue) reportResourceOvercommit() {
+	if !c.reportResourceMetrics {
+		return
+	}
+	var cohortName kueue.CohortReference
+	if c.HasParent() {
+		cohortName = c.Parent().Name
+	}
+	for fr, quota := range c.resourceNode.Quotas {
+		quantity := resources.ResourceQuantity(fr.Resource, max(0, c.resourceNode.Usage[fr]-quota.Nominal))
+		metrics.ReportClusterQueueResourceOvercommit(cohortName, string(c.Name), string(fr.Flavor), string(fr.Resource), utilresource.QuantityToFloat(&quantity))
+	}
+}
+
+// reservingNotAdmitted indicates whether the workload is reserving quota
+// in the ClusterQueue without being admitted yet.
+func (c *clusterQueue) reservingNotAdmitted(k string) bool {
+	wi, found := c.Workloads[k]
+	return found && !workload.IsAdmitted(wi.Obj)
+}
+
+// reportAdmissionCheckWait reports the time the workload waited for its
+// admission checks, from its quota reservation until its admission.
+func (c *clusterQueue) reportAdmissionCheckWait(wl *kueue.Workload) {
+	reserved := apimeta.FindStatusCondition(wl.Status.Conditions, kueue.WorkloadQuotaReserved)
+	admitted := apimeta.FindStatusCondition(wl.Status.Conditions, kueue.WorkloadAdmitted)
+	if reserved == nil || admitted == nil {
+		return
+	}
+	metrics.ReportAdmissionCheckWait(c.Name, max(0, admitted.LastTransitionTime.Sub(reserved.LastTransitionTime.Time)))
 }
 
 func (c *clusterQueue) updateWorkloadTASUsage(log logr.Logger, wi *workload.Info, m int64) {
 	if !features.Enabled(features.TopologyAwareScheduling) || !wi.IsUsingTAS() {
 		return
 	}
-	key := workload.Key(wi.Obj)
+	key := c.workloadKey(wi.Obj)
 	log = log.WithValues("workload", key)
 	if !c.isTASSynced() {
 		log.V(2).Info("Delaying accounting of the TAS usage, because TAS cache is not synced yet")
@@ -598,6 +792,17 @@ func (c *clusterQueue) updateWorkloadTASUsage(log logr.Logger, wi *workload.Info
 	c.workloadsNotAccountedForTAS.Delete(key)
 }
 
+// rgByResource returns the ResourceGroup which contains capacity for the
+// resource, or nil if the ClusterQueue doesn't provide this resource.
+func (c *clusterQueue) rgByResource(resource corev1.ResourceName) *ResourceGroup {
+	for i := range c.ResourceGroups {
+		if c.ResourceGroups[i].CoveredResources.Has(resource) {
+			return &c.ResourceGroups[i]
+		}
+	}
+	return nil
+}
+
 func updateFlavorUsage(newUsage resources.FlavorResourceQuantities, oldUsage resources.FlavorResourceQuantities, m int64) {
 	for fr, q := range newUsage {
 		oldUsage[fr] += q * m
@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utilac "sigs.k8s.io/kueue/pkg/util/admissioncheck"
+)
+
+// ClusterQueueDrift compares the configuration of the ClusterQueue in the
+// cache with the desired spec, and returns a description of each
+// difference: in the Cohort, the resource groups, the quotas, the
+// preemption policies, the fair sharing weight and the admission checks.
+// It returns nil if there are no differences.
+func (c *Cache) ClusterQueueDrift(desired *kueue.ClusterQueue) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.hm.ClusterQueue(kueue.ClusterQueueReference(desired.Name))
+	if cq == nil {
+		return []string{"clusterQueue not found in the cache"}
+	}
+	var drift []string
+
+	var cohortName kueue.CohortReference
+	if cq.HasParent() {
+		cohortName = cq.Parent().Name
+	}
+	if cohortName != desired.Spec.Cohort {
+		drift = append(drift, fmt.Sprintf("cohort: %q -> %q", cohortName, desired.Spec.Cohort))
+	}
+
+	desiredRGs := createdResourceGroups(desired.Spec.ResourceGroups)
+	if !slices.EqualFunc(cq.ResourceGroups, desiredRGs, func(a, b ResourceGroup) bool {
+		return a.CoveredResources.Equal(b.CoveredResources) && slices.Equal(a.Flavors, b.Flavors)
+	}) {
+		drift = append(drift, "resourceGroups: covered resources or flavors changed")
+	}
+	desiredQuotas := createResourceQuotas(desired.Spec.ResourceGroups)
+	frs := make([]resources.FlavorResource, 0, len(desiredQuotas))
+	for fr := range cq.resourceNode.Quotas {
+		frs = append(frs, fr)
+	}
+	for fr := range desiredQuotas {
+		if _, found := cq.resourceNode.Quotas[fr]; !found {
+			frs = append(frs, fr)
+		}
+	}
+	slices.SortFunc(frs, func(a, b resources.FlavorResource) int {
+		return cmp.Or(cmp.Compare(a.Flavor, b.Flavor), cmp.Compare(a.Resource, b.Resource))
+	})
+	for _, fr := range frs {
+		current, inCache := cq.resourceNode.Quotas[fr]
+		wanted, inDesired := desiredQuotas[fr]
+		switch {
+		case !inDesired:
+			drift = append(drift, fmt.Sprintf("quota %s/%s: removed", fr.Flavor, fr.Resource))
+		case !inCache:
+			drift = append(drift, fmt.Sprintf("quota %s/%s: added", fr.Flavor, fr.Resource))
+		case !equality.Semantic.DeepEqual(current, wanted):
+			drift = append(drift, fmt.Sprintf("quota %s/%s: changed", fr.Flavor, fr.Resource))
+		}
+	}
+
+	desiredPreemption := defaultPreemption
+	if desired.Spec.Preemption != nil {
+		desiredPreemption = *desired.Spec.Preemption
+	}
+	if !equality.Semantic.DeepEqual(cq.Preemption, desiredPreemption) {
+		drift = append(drift, "preemption: changed")
+	}
+
+	if desiredWeight := parseFairWeight(desired.Spec.FairSharing); cq.FairWeight.Cmp(desiredWeight) != 0 {
+		drift = append(drift, fmt.Sprintf("fairWeight: %s -> %s", cq.FairWeight.String(), desiredWeight.String()))
+	}
+
+	desiredChecks := utilac.NewAdmissionChecks(desired)
+	checks := sets.KeySet(cq.AdmissionChecks).Union(sets.KeySet(desiredChecks))
+	for _, check := range sets.List(checks) {
+		current, inCache := cq.AdmissionChecks[check]
+		wanted, inDesired := desiredChecks[check]
+		switch {
+		case !inDesired:
+			drift = append(drift, fmt.Sprintf("admissionCheck %s: removed", check))
+		case !inCache:
+			drift = append(drift, fmt.Sprintf("admissionCheck %s: added", check))
+		case !current.Equal(wanted):
+			drift = append(drift, fmt.Sprintf("admissionCheck %s: flavors changed", check))
+		}
+	}
+	return drift
+}
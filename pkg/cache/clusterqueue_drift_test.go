@@ -0,0 +1,112 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestClusterQueueDrift(t *testing.T) {
+	base := func() *utiltesting.ClusterQueueWrapper {
+		return utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "4").
+				Resource(corev1.ResourceMemory, "4Gi").
+				Obj()).
+			AdmissionChecks("check")
+	}
+	cases := map[string]struct {
+		desired *kueue.ClusterQueue
+		want    []string
+	}{
+		"identical": {
+			desired: base().Obj(),
+		},
+		"different cohort and quota": {
+			desired: utiltesting.MakeClusterQueue("cq").
+				Cohort("other").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+					Resource(corev1.ResourceCPU, "8").
+					Resource(corev1.ResourceMemory, "4Gi").
+					Obj()).
+				AdmissionChecks("check").
+				Obj(),
+			want: []string{
+				`cohort: "cohort" -> "other"`,
+				"quota default/cpu: changed",
+			},
+		},
+		"different resource groups": {
+			desired: utiltesting.MakeClusterQueue("cq").
+				Cohort("cohort").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+					Resource(corev1.ResourceCPU, "4").
+					Obj()).
+				AdmissionChecks("check").
+				Obj(),
+			want: []string{
+				"resourceGroups: covered resources or flavors changed",
+				"quota default/memory: removed",
+			},
+		},
+		"different preemption, weight and admission checks": {
+			desired: utiltesting.MakeClusterQueue("cq").
+				Cohort("cohort").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+					Resource(corev1.ResourceCPU, "4").
+					Resource(corev1.ResourceMemory, "4Gi").
+					Obj()).
+				Preemption(kueue.ClusterQueuePreemption{
+					WithinClusterQueue: kueue.PreemptionPolicyLowerPriority,
+				}).
+				FairWeight(resource.MustParse("2")).
+				AdmissionChecks("other-check").
+				Obj(),
+			want: []string{
+				"preemption: changed",
+				"fairWeight: 1 -> 2",
+				"admissionCheck check: removed",
+				"admissionCheck other-check: added",
+			},
+		},
+		"unknown ClusterQueue": {
+			desired: utiltesting.MakeClusterQueue("missing").Obj(),
+			want:    []string{"clusterQueue not found in the cache"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			if err := cache.AddClusterQueue(ctx, base().Obj()); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, cache.ClusterQueueDrift(tc.desired)); diff != "" {
+				t.Errorf("Unexpected drift (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -17,6 +17,11 @@ limitations under the License.
 package cache
 
 import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
@@ -49,11 +54,64 @@ type ClusterQueueSnapshot struct {
 	// deleted, or the resource groups are changed.
 	AllocatableResourceGeneration int64
 
+	// ResourceNode.Usage holds the usage of all the workloads reserving
+	// quota, whether they are admitted or not, including the quota earmarked
+	// for preemptors and the usage simulated during scheduling. Unlike the
+	// cache's AdmittedUsage, it is not limited to admitted workloads.
 	ResourceNode ResourceNode
 	hierarchy.ClusterQueue[*CohortSnapshot]
 
 	TASFlavors map[kueue.ResourceFlavorReference]*TASFlavorSnapshot
 	tasOnly    bool
+
+	// Preempting holds the keys of the workloads which were selected for
+	// preemption and still hold their quota.
+	Preempting sets.Set[string]
+	// Earmarks holds, by preemptor key, the usage released by preempted
+	// workloads which is reserved for their preemptor. It is included in
+	// the ResourceNode usage.
+	Earmarks map[string]resources.FlavorResourceQuantities
+
+	// ReclaimEvictionOrder is the order in which the workloads of other
+	// ClusterQueues are evicted when the ClusterQueue reclaims its quota.
+	// Empty means ReclaimEvictionOrderPriority.
+	ReclaimEvictionOrder ReclaimEvictionOrder
+
+	// LenderBorrowingLimits limits how much the ClusterQueue can borrow
+	// from each of the ClusterQueues of its Cohort.
+	LenderBorrowingLimits LenderBorrowingLimits
+
+	// DefaultPriority is the priority of the workloads without a priority
+	// class when the scheduler and the preemption compare priorities. Nil
+	// means the priority of the workloads.
+	DefaultPriority *int32
+
+	// BorrowingFraction caps how much the ClusterQueue can borrow to a
+	// fraction of the capacity lent to its Cohort by the other members.
+	// Nil means no cap.
+	BorrowingFraction *float64
+
+	// MaxFlavorsToTry limits how many flavors of a ResourceGroup are tried
+	// for a resource of a PodSet in a single flavor assignment. Nil means
+	// all the flavors are tried.
+	MaxFlavorsToTry *int32
+
+	// ResourceGroupPriorities holds, by covered resource, the priorities
+	// ordering the evaluation of the ResourceGroups.
+	ResourceGroupPriorities map[corev1.ResourceName]int32
+
+	// UsageAccounting defines how the requests of the workloads are
+	// accounted as usage of the quota of the ClusterQueue.
+	UsageAccounting workload.UsageAccounting
+
+	// fairSharingUsageMode is the usage counting toward the share.
+	fairSharingUsageMode FairSharingUsage
+	// unadmittedUsage is the usage of the workloads reserving quota
+	// which are not admitted yet. It is only tracked with
+	// FairSharingAdmittedUsage.
+	unadmittedUsage resources.FlavorResourceQuantities
+	// workloadKeyFunc computes the keys of the Workloads.
+	workloadKeyFunc WorkloadKeyFunc
 }
 
 // RGByResource returns the ResourceGroup which contains capacity
@@ -75,12 +133,14 @@ func (c *ClusterQueueSnapshot) SimulateWorkloadRemoval(workloads []*workload.Inf
 	for _, w := range workloads {
 		usage = append(usage, w.Usage())
 	}
-	for _, u := range usage {
+	for i, u := range usage {
 		c.RemoveUsage(u)
+		c.updateUnadmittedUsage(workloads[i], -1)
 	}
 	return func() {
-		for _, u := range usage {
+		for i, u := range usage {
 			c.AddUsage(u)
+			c.updateUnadmittedUsage(workloads[i], 1)
 		}
 	}
 }
@@ -147,6 +207,623 @@ func (c *ClusterQueueSnapshot) Fits(usage workload.Usage) bool {
 	return true
 }
 
+// ResumeImpact returns the impact on the quota of resuming the suspended
+// workload: whether it fits in the currently available capacity, whether
+// it would need to borrow from the Cohort, and whether it would only fit
+// after preempting other workloads. The flavors assigned to the workload
+// are used; if it has none, they are picked as by resumeAssignment.
+func (c *ClusterQueueSnapshot) ResumeImpact(wl *workload.Info) (fits bool, needsBorrow bool, needsPreempt bool) {
+	var usage resources.FlavorResourceQuantities
+	if slices.ContainsFunc(wl.TotalRequests, func(ps workload.PodSetResources) bool { return len(ps.Flavors) > 0 }) {
+		usage = wl.FlavorResourceUsage()
+	} else {
+		var covered bool
+		if usage, covered = c.resumeAssignment(wl); !covered {
+			return false, false, false
+		}
+	}
+	fits = true
+	fitsWithPreemption := true
+	for fr, q := range usage {
+		if c.BorrowingWith(fr, q) {
+			needsBorrow = true
+		}
+		if c.Available(fr) < q {
+			fits = false
+		}
+		if c.PotentialAvailable(fr) < q {
+			fitsWithPreemption = false
+		}
+	}
+	return fits, needsBorrow, !fits && fitsWithPreemption
+}
+
+// resumeAssignment returns the usage of the workload under the flavors it
+// would be assigned when resumed. For each ResourceGroup, the flavor
+// fitting in the currently available capacity while borrowing the least is
+// picked, as in MinBorrowAssignment; if none fits, the first flavor which
+// would fit after preemptions, or else the first flavor. It returns false
+// if some requested resource is not covered by the ClusterQueue.
+func (c *ClusterQueueSnapshot) resumeAssignment(wl *workload.Info) (resources.FlavorResourceQuantities, bool) {
+	requests := c.combinedRequests(wl)
+	assignment := make(resources.FlavorResourceQuantities, len(requests))
+	for i := range c.ResourceGroups {
+		rg := &c.ResourceGroups[i]
+		if !requestsAnyOf(requests, rg) {
+			continue
+		}
+		var best resources.FlavorResourceQuantities
+		var bestBorrowed int64
+		for _, fName := range rg.Flavors {
+			usage, borrowed, fits := c.flavorUsage(fName, rg, requests)
+			if fits && (best == nil || borrowed < bestBorrowed) {
+				best, bestBorrowed = usage, borrowed
+			}
+		}
+		if best == nil && len(rg.Flavors) > 0 {
+			fName := rg.Flavors[0]
+			if idx := slices.IndexFunc(rg.Flavors, func(f kueue.ResourceFlavorReference) bool {
+				return c.fitsPotentialCapacity(f, rg, requests)
+			}); idx >= 0 {
+				fName = rg.Flavors[idx]
+			}
+			best = make(resources.FlavorResourceQuantities)
+			for rName, q := range requests {
+				if rg.CoveredResources.Has(rName) {
+					fr := resources.FlavorResource{Flavor: fName, Resource: rName}
+					best[fr] = c.UsageAccounting.Quota(fr, q)
+				}
+			}
+		}
+		maps.Copy(assignment, best)
+	}
+	if len(assignment) < len(requests) {
+		// Some resources are not covered by the ClusterQueue.
+		return nil, false
+	}
+	return assignment, true
+}
+
+// MinBorrowAssignment returns the usage of the workload under the flavor
+// assignment which fits in the currently available capacity while
+// borrowing the least from the Cohort. All the resources of a
+// ResourceGroup are assigned the same flavor, and the amounts borrowed for
+// all the resources are added up. Among assignments borrowing the same
+// amount, the flavors earlier in the ResourceGroup are preferred. It
+// returns false if no assignment fits.
+func (c *ClusterQueueSnapshot) MinBorrowAssignment(wl *workload.Info) (resources.FlavorResourceQuantities, bool) {
+	requests := c.combinedRequests(wl)
+	assignment := make(resources.FlavorResourceQuantities, len(requests))
+	for i := range c.ResourceGroups {
+		rg := &c.ResourceGroups[i]
+		var best resources.FlavorResourceQuantities
+		var bestBorrowed int64
+		for _, fName := range rg.Flavors {
+			usage, borrowed, fits := c.flavorUsage(fName, rg, requests)
+			if fits && (best == nil || borrowed < bestBorrowed) {
+				best, bestBorrowed = usage, borrowed
+			}
+		}
+		if best == nil {
+			return nil, false
+		}
+		for fr, q := range best {
+			assignment[fr] = q
+		}
+	}
+	if len(assignment) < len(requests) {
+		// Some resources are not covered by the ClusterQueue.
+		return nil, false
+	}
+	return assignment, true
+}
+
+// combinedRequests returns the requests of the PodSets of the workload,
+// combined as the ClusterQueue accounts them. The quota they use in a
+// flavor is given by c.UsageAccounting.Quota.
+func (c *ClusterQueueSnapshot) combinedRequests(wl *workload.Info) resources.Requests {
+	requests := make(resources.Requests)
+	for _, ps := range wl.TotalRequests {
+		for rName, q := range ps.Requests {
+			requests[rName] = c.UsageAccounting.Combine(requests[rName], q)
+		}
+	}
+	return requests
+}
+
+// flavorUsage returns the usage of the requests for the resources of the
+// ResourceGroup in the flavor, the amount the requests would add to the
+// borrowing and whether they fit in the available capacity.
+func (c *ClusterQueueSnapshot) flavorUsage(fName kueue.ResourceFlavorReference, rg *ResourceGroup, requests resources.Requests) (resources.FlavorResourceQuantities, int64, bool) {
+	usage := make(resources.FlavorResourceQuantities)
+	var borrowed int64
+	for rName, q := range requests {
+		if !rg.CoveredResources.Has(rName) {
+			continue
+		}
+		fr := resources.FlavorResource{Flavor: fName, Resource: rName}
+		q = c.UsageAccounting.Quota(fr, q)
+		if c.Available(fr) < q {
+			return nil, 0, false
+		}
+		usage[fr] = q
+		// Only count what the request adds to the current borrowing.
+		current, nominal := c.ResourceNode.Usage[fr], c.QuotaFor(fr).Nominal
+		borrowed += max(0, current+q-nominal) - max(0, current-nominal)
+	}
+	return usage, borrowed, true
+}
+
+// MinQuotaIncreaseToFit returns the smallest increase of the nominal
+// quotas which would let the workload fit in the ClusterQueue without
+// borrowing. For each ResourceGroup, the flavor requiring the smallest
+// total increase is chosen, preferring the earlier flavors on ties. Only
+// the flavors and resources which need an increase are included, so the
+// result is empty if the workload already fits within the nominal quota.
+// It returns nil if some requested resource is not covered by the
+// ClusterQueue, as no quota increase would make the workload fit.
+func (c *ClusterQueueSnapshot) MinQuotaIncreaseToFit(wl *workload.Info) resources.FlavorResourceQuantities {
+	requests := c.combinedRequests(wl)
+	increase := make(resources.FlavorResourceQuantities)
+	covered := 0
+	for i := range c.ResourceGroups {
+		rg := &c.ResourceGroups[i]
+		var best resources.FlavorResourceQuantities
+		var bestTotal int64
+		for _, fName := range rg.Flavors {
+			flavorIncrease := make(resources.FlavorResourceQuantities)
+			var total int64
+			for rName, q := range requests {
+				if !rg.CoveredResources.Has(rName) {
+					continue
+				}
+				fr := resources.FlavorResource{Flavor: fName, Resource: rName}
+				if missing := c.ResourceNode.Usage[fr] + c.UsageAccounting.Quota(fr, q) - c.QuotaFor(fr).Nominal; missing > 0 {
+					flavorIncrease[fr] = missing
+					total += missing
+				}
+			}
+			if best == nil || total < bestTotal {
+				best, bestTotal = flavorIncrease, total
+			}
+		}
+		for rName := range requests {
+			if rg.CoveredResources.Has(rName) {
+				covered++
+			}
+		}
+		for fr, q := range best {
+			increase[fr] = q
+		}
+	}
+	if covered < len(requests) {
+		return nil
+	}
+	return increase
+}
+
+// BindingConstraint returns the flavor and resource which prevents the
+// workload from being admitted with the currently available capacity,
+// including the capacity which can be borrowed from the Cohort. For each
+// ResourceGroup, the flavor lacking the fewest resources is considered,
+// preferring the earlier flavors on ties. The binding constraint is the
+// resource lacking the largest fraction of its request. It returns false
+// if the workload fits, or if some requested resource is not covered by
+// the ClusterQueue.
+func (c *ClusterQueueSnapshot) BindingConstraint(wl *workload.Info) (resources.FlavorResource, bool) {
+	requests := c.combinedRequests(wl)
+	var constraint resources.FlavorResource
+	var constraintShortage float64
+	covered := 0
+	for i := range c.ResourceGroups {
+		rg := &c.ResourceGroups[i]
+		var rgConstraint resources.FlavorResource
+		var rgShortage float64
+		bestMissing := -1
+		for _, fName := range rg.Flavors {
+			var flavorConstraint resources.FlavorResource
+			var flavorShortage float64
+			missing := 0
+			for rName, q := range requests {
+				if !rg.CoveredResources.Has(rName) || q <= 0 {
+					continue
+				}
+				fr := resources.FlavorResource{Flavor: fName, Resource: rName}
+				q = c.UsageAccounting.Quota(fr, q)
+				if lacking := q - c.Available(fr); lacking > 0 {
+					missing++
+					if shortage := float64(lacking) / float64(q); shortage > flavorShortage {
+						flavorConstraint, flavorShortage = fr, shortage
+					}
+				}
+			}
+			if bestMissing == -1 || missing < bestMissing {
+				bestMissing, rgConstraint, rgShortage = missing, flavorConstraint, flavorShortage
+			}
+		}
+		for rName := range requests {
+			if rg.CoveredResources.Has(rName) {
+				covered++
+			}
+		}
+		if rgShortage > constraintShortage {
+			constraint, constraintShortage = rgConstraint, rgShortage
+		}
+	}
+	if covered < len(requests) || constraintShortage == 0 {
+		return resources.FlavorResource{}, false
+	}
+	return constraint, true
+}
+
+// MaxPreemptionFreeRequest returns the largest request of the resource
+// which could be admitted in the ClusterQueue without preempting any
+// workload, using the free quota and the quota which can be borrowed from
+// the Cohort. It is the largest available capacity among the flavors of
+// the ResourceGroup covering the resource, or 0 if the ClusterQueue
+// doesn't cover the resource.
+func (c *ClusterQueueSnapshot) MaxPreemptionFreeRequest(resource corev1.ResourceName) int64 {
+	rg := c.RGByResource(resource)
+	if rg == nil {
+		return 0
+	}
+	var maxRequest int64
+	for _, fName := range rg.Flavors {
+		maxRequest = max(maxRequest, c.Available(resources.FlavorResource{Flavor: fName, Resource: resource}))
+	}
+	return maxRequest
+}
+
+// PreemptionDeficit returns, for each flavor and resource assigned to the
+// workload, how much usage must be freed by preemptions to admit it,
+// beyond the free quota and the quota which can be borrowed from the
+// Cohort. Only the flavors and resources with a deficit are included, so
+// the result is empty if the workload fits without preemptions. The
+// workload is expected to have its flavors assigned.
+func (c *ClusterQueueSnapshot) PreemptionDeficit(wl *workload.Info) resources.FlavorResourceQuantities {
+	deficit := make(resources.FlavorResourceQuantities)
+	for fr, q := range wl.FlavorResourceUsage() {
+		if missing := q - c.Available(fr); missing > 0 {
+			deficit[fr] = missing
+		}
+	}
+	return deficit
+}
+
+// MinPriorityToPreempt returns the lowest priority with which a workload
+// requiring the quota could fit in the ClusterQueue by preempting the
+// workloads of the ClusterQueue with a strictly lower priority, using the
+// DefaultPriority for the workloads without a priority class. When the
+// quota is available without preemptions, it returns math.MinInt32. It
+// returns false if the quota doesn't fit even after preempting all the
+// workloads of the ClusterQueue.
+func (c *ClusterQueueSnapshot) MinPriorityToPreempt(required resources.FlavorResourceQuantities) (int32, bool) {
+	fits := func() bool {
+		for fr, q := range required {
+			if c.Available(fr) < q {
+				return false
+			}
+		}
+		return true
+	}
+	if fits() {
+		return math.MinInt32, true
+	}
+	candidates := slices.Collect(maps.Values(c.Workloads))
+	slices.SortFunc(candidates, func(a, b *workload.Info) int {
+		return cmp.Compare(c.WorkloadPriority(a), c.WorkloadPriority(b))
+	})
+	var removed []*workload.Info
+	defer func() {
+		for _, wi := range removed {
+			c.AddUsage(wi.Usage())
+		}
+	}()
+	for i, wi := range candidates {
+		c.RemoveUsage(wi.Usage())
+		removed = append(removed, wi)
+		p := c.WorkloadPriority(wi)
+		if i+1 < len(candidates) && c.WorkloadPriority(candidates[i+1]) == p {
+			// The workloads with the same priority are preempted together.
+			continue
+		}
+		if fits() {
+			if p == math.MaxInt32 {
+				return 0, false
+			}
+			return p + 1, true
+		}
+	}
+	return 0, false
+}
+
+// GuaranteedShortfall returns, by flavor and resource, how much of the
+// unused nominal quota of the ClusterQueue is currently unavailable because
+// other members of its Cohort borrow it. This is the quota the
+// ClusterQueue would need to reclaim to use its nominal quota. Only the
+// flavors and resources with a shortfall are included.
+func (c *ClusterQueueSnapshot) GuaranteedShortfall() resources.FlavorResourceQuantities {
+	shortfall := make(resources.FlavorResourceQuantities)
+	for fr, quota := range c.ResourceNode.Quotas {
+		unused := quota.Nominal - c.ResourceNode.Usage[fr]
+		if missing := unused - c.Available(fr); unused > 0 && missing > 0 {
+			shortfall[fr] = missing
+		}
+	}
+	return shortfall
+}
+
+// FitsWithinGuaranteed indicates whether the request fits, along with the
+// current usage of the ClusterQueue, within its guaranteed quota, which is
+// the part of its nominal quota that it doesn't lend to its Cohort. Such a
+// request is always admissible, without relying on capacity which other
+// members could be borrowing. Without a Cohort, the whole nominal quota is
+// guaranteed.
+func (c *ClusterQueueSnapshot) FitsWithinGuaranteed(req resources.FlavorResourceQuantities) bool {
+	for fr, q := range req {
+		if q <= 0 {
+			continue
+		}
+		guaranteed := c.QuotaFor(fr).Nominal
+		if c.HasParent() {
+			guaranteed = c.ResourceNode.guaranteedQuota(fr)
+		}
+		if c.ResourceNode.Usage[fr]+q > guaranteed {
+			return false
+		}
+	}
+	return true
+}
+
+// ConsolidationCandidates returns the keys of the admitted workloads which
+// could be moved to the other flavors in use for the resource, so that the
+// flavor they are assigned to would be entirely freed. The flavor with the
+// lowest usage whose usage fits in the unused nominal quota of the other
+// flavors in use is chosen. Flavors used by workloads which are not
+// admitted yet are not considered. It is advisory: the workloads are
+// only compared on the resource, and nil is returned if no flavor can be
+// freed.
+func (c *ClusterQueueSnapshot) ConsolidationCandidates(resource corev1.ResourceName) []string {
+	rg := c.RGByResource(resource)
+	if rg == nil {
+		return nil
+	}
+	workloadsOnFlavor := make(map[kueue.ResourceFlavorReference][]string)
+	pinned := sets.New[kueue.ResourceFlavorReference]()
+	for key, wi := range c.Workloads {
+		for fr := range wi.FlavorResourceUsage() {
+			if fr.Resource != resource {
+				continue
+			}
+			if !workload.IsAdmitted(wi.Obj) {
+				pinned.Insert(fr.Flavor)
+				continue
+			}
+			workloadsOnFlavor[fr.Flavor] = append(workloadsOnFlavor[fr.Flavor], key)
+		}
+	}
+	var inUse []resources.FlavorResource
+	for _, flavor := range rg.Flavors {
+		fr := resources.FlavorResource{Flavor: flavor, Resource: resource}
+		if c.ResourceNode.Usage[fr] > 0 {
+			inUse = append(inUse, fr)
+		}
+	}
+	var chosen *resources.FlavorResource
+	for i, fr := range inUse {
+		usage := c.ResourceNode.Usage[fr]
+		if pinned.Has(fr.Flavor) || (chosen != nil && usage >= c.ResourceNode.Usage[*chosen]) {
+			continue
+		}
+		var free int64
+		for j, other := range inUse {
+			if j != i {
+				free += max(0, c.QuotaFor(other).Nominal-c.ResourceNode.Usage[other])
+			}
+		}
+		if usage <= free {
+			chosen = &inUse[i]
+		}
+	}
+	if chosen == nil {
+		return nil
+	}
+	candidates := workloadsOnFlavor[chosen.Flavor]
+	slices.Sort(candidates)
+	return candidates
+}
+
+// IsPermanentlyUnschedulable indicates whether the workload could never
+// be admitted in the ClusterQueue, even after preempting all the other
+// workloads, because it requests a resource the ClusterQueue doesn't
+// cover, or because, for some PodSet and ResourceGroup, the requests of
+// the PodSet exceed the capacity of every flavor, including the capacity
+// which could be borrowed from the Cohort. The PodSets are evaluated
+// separately, as they can be assigned different flavors.
+func (c *ClusterQueueSnapshot) IsPermanentlyUnschedulable(wl *workload.Info) bool {
+	for _, ps := range wl.TotalRequests {
+		for rName := range ps.Requests {
+			if c.RGByResource(rName) == nil {
+				return true
+			}
+		}
+		for i := range c.ResourceGroups {
+			rg := &c.ResourceGroups[i]
+			if !requestsAnyOf(ps.Requests, rg) {
+				continue
+			}
+			if !slices.ContainsFunc(rg.Flavors, func(fName kueue.ResourceFlavorReference) bool {
+				return c.fitsPotentialCapacity(fName, rg, ps.Requests)
+			}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fitsPotentialCapacity indicates whether the quota used by the requests
+// for the resources of the ResourceGroup fits in the potential capacity of
+// the flavor.
+func (c *ClusterQueueSnapshot) fitsPotentialCapacity(fName kueue.ResourceFlavorReference, rg *ResourceGroup, requests resources.Requests) bool {
+	for rName, q := range requests {
+		if !rg.CoveredResources.Has(rName) {
+			continue
+		}
+		fr := resources.FlavorResource{Flavor: fName, Resource: rName}
+		if c.PotentialAvailable(fr) < c.UsageAccounting.Quota(fr, q) {
+			return false
+		}
+	}
+	return true
+}
+
+// WorkloadInfo returns a copy of the Info of the workload with the given
+// key, and whether the workload is reserving quota in the ClusterQueue.
+// Modifying the returned Info doesn't affect the snapshot.
+func (c *ClusterQueueSnapshot) WorkloadInfo(key string) (*workload.Info, bool) {
+	wi, found := c.Workloads[key]
+	if !found {
+		return nil, false
+	}
+	return wi.Clone(), true
+}
+
+// HowManyFit returns how many workloads with the given usage could be
+// admitted in the ClusterQueue with the currently available capacity,
+// including the capacity which can be borrowed from the Cohort. It is
+// limited by the tightest flavor and resource. It returns 0 when the
+// usage is empty.
+func (c *ClusterQueueSnapshot) HowManyFit(perWorkload resources.FlavorResourceQuantities) int {
+	count := -1
+	for fr, q := range perWorkload {
+		if q <= 0 {
+			continue
+		}
+		fit := int(c.Available(fr) / q)
+		if count < 0 || fit < count {
+			count = fit
+		}
+	}
+	return max(0, count)
+}
+
+// ReservedUsage returns the total usage of the workloads reserving quota
+// in the ClusterQueue, including the ones which are not admitted yet.
+func (c *ClusterQueueSnapshot) ReservedUsage() resources.FlavorResourceQuantities {
+	usage := make(resources.FlavorResourceQuantities)
+	for _, wi := range c.Workloads {
+		updateFlavorUsage(wi.FlavorResourceUsage(), usage, 1)
+	}
+	return usage
+}
+
+// DistinctFlavorCount returns the number of unique flavors across all the
+// ResourceGroups of the ClusterQueue.
+func (c *ClusterQueueSnapshot) DistinctFlavorCount() int {
+	flavors := sets.New[kueue.ResourceFlavorReference]()
+	for _, rg := range c.ResourceGroups {
+		flavors.Insert(rg.Flavors...)
+	}
+	return flavors.Len()
+}
+
+// PodsOnFlavor returns the number of pods of the workloads admitted in
+// the ClusterQueue which are assigned to the flavor. The pods of a PodSet
+// count once if any of their resources is assigned to the flavor.
+func (c *ClusterQueueSnapshot) PodsOnFlavor(flavor kueue.ResourceFlavorReference) int {
+	var pods int
+	for _, wi := range c.Workloads {
+		if !workload.IsAdmitted(wi.Obj) {
+			continue
+		}
+		for _, ps := range wi.TotalRequests {
+			for _, f := range ps.Flavors {
+				if f == flavor {
+					pods += int(ps.Count)
+					break
+				}
+			}
+		}
+	}
+	return pods
+}
+
+// ResourceUtilization holds the usage and nominal quota of a resource.
+type ResourceUtilization struct {
+	Usage   int64
+	Nominal int64
+}
+
+// UtilizationSample returns, for each resource of the ClusterQueue, its
+// current usage and nominal quota summed across the flavors, so that the
+// utilization can be sampled over time.
+func (c *ClusterQueueSnapshot) UtilizationSample() map[corev1.ResourceName]ResourceUtilization {
+	sample := make(map[corev1.ResourceName]ResourceUtilization)
+	for fr, quota := range c.ResourceNode.Quotas {
+		u := sample[fr.Resource]
+		u.Nominal += quota.Nominal
+		sample[fr.Resource] = u
+	}
+	for fr, usage := range c.ResourceNode.Usage {
+		u := sample[fr.Resource]
+		u.Usage += usage
+		sample[fr.Resource] = u
+	}
+	return sample
+}
+
+// OrphanUsage returns the flavors and resources which have usage in the
+// ClusterQueue but no quota, sorted by flavor and resource. Such usage is
+// accounted against undefined quota, for example when the workloads were
+// admitted before the quota was removed from the ClusterQueue.
+func (c *ClusterQueueSnapshot) OrphanUsage() []resources.FlavorResource {
+	var orphaned []resources.FlavorResource
+	for fr, q := range c.ResourceNode.Usage {
+		if _, found := c.ResourceNode.Quotas[fr]; !found && q != 0 {
+			orphaned = append(orphaned, fr)
+		}
+	}
+	slices.SortFunc(orphaned, func(a, b resources.FlavorResource) int {
+		return cmp.Or(cmp.Compare(a.Flavor, b.Flavor), cmp.Compare(a.Resource, b.Resource))
+	})
+	return orphaned
+}
+
+// OverAdmitted returns the flavors and resources for which the usage of
+// the ClusterQueue exceeds the most it could ever be admitted: its nominal
+// quota plus what it can borrow from its Cohort, as in PotentialAvailable.
+// They are sorted by flavor and resource. A non-empty result indicates an
+// accounting bug, or that the quota was reduced after the admissions.
+func (c *ClusterQueueSnapshot) OverAdmitted() []resources.FlavorResource {
+	var over []resources.FlavorResource
+	for fr, q := range c.ResourceNode.Usage {
+		if q > c.PotentialAvailable(fr) {
+			over = append(over, fr)
+		}
+	}
+	slices.SortFunc(over, func(a, b resources.FlavorResource) int {
+		return cmp.Or(cmp.Compare(a.Flavor, b.Flavor), cmp.Compare(a.Resource, b.Resource))
+	})
+	return over
+}
+
+// EffectiveCapacity returns, for each flavor and resource with quota in
+// the ClusterQueue, the capacity it can use. It is the nominal quota
+// when includeBorrowable is false. Otherwise, the current borrowing
+// headroom is added: the quota already borrowed from the Cohort and
+// the quota which can still be borrowed.
+func (c *ClusterQueueSnapshot) EffectiveCapacity(includeBorrowable bool) resources.FlavorResourceQuantities {
+	capacity := make(resources.FlavorResourceQuantities, len(c.ResourceNode.Quotas))
+	for fr, quota := range c.ResourceNode.Quotas {
+		capacity[fr] = quota.Nominal
+		if includeBorrowable {
+			capacity[fr] = max(quota.Nominal, c.ResourceNode.Usage[fr]+c.Available(fr))
+		}
+	}
+	return capacity
+}
+
 func (c *ClusterQueueSnapshot) QuotaFor(fr resources.FlavorResource) ResourceQuota {
 	return c.ResourceNode.Quotas[fr]
 }
@@ -195,7 +872,7 @@ func (c *ClusterQueueSnapshot) parentHRN() hierarchicalResourceNode {
 }
 
 func (c *ClusterQueueSnapshot) DominantResourceShare() int {
-	share, _ := dominantResourceShare(c, nil)
+	share, _ := dominantResourceShare(c, nil, c.fairSharingUsageMode)
 	return share
 }
 
@@ -221,3 +898,15 @@ func (c *ClusterQueueSnapshot) FindTopologyAssignmentsForWorkload(
 func (c *ClusterQueueSnapshot) IsTASOnly() bool {
 	return c.tasOnly
 }
+
+// FlavorScopedAdmissionChecks returns a copy of the AdmissionChecks which
+// only apply to a subset of the ResourceFlavors.
+func (c *ClusterQueueSnapshot) FlavorScopedAdmissionChecks() map[string]sets.Set[kueue.ResourceFlavorReference] {
+	scoped := make(map[string]sets.Set[kueue.ResourceFlavorReference])
+	for ac, flavors := range c.AdmissionChecks {
+		if flavors.Len() > 0 {
+			scoped[ac] = flavors.Clone()
+		}
+	}
+	return scoped
+}
@@ -0,0 +1,1696 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestFlavorScopedAdmissionChecks(t *testing.T) {
+	cases := map[string]struct {
+		admissionChecks map[string]sets.Set[kueue.ResourceFlavorReference]
+		want            map[string]sets.Set[kueue.ResourceFlavorReference]
+	}{
+		"no admission checks": {
+			want: map[string]sets.Set[kueue.ResourceFlavorReference]{},
+		},
+		"only global checks": {
+			admissionChecks: map[string]sets.Set[kueue.ResourceFlavorReference]{
+				"ac1": sets.New[kueue.ResourceFlavorReference](),
+				"ac2": nil,
+			},
+			want: map[string]sets.Set[kueue.ResourceFlavorReference]{},
+		},
+		"global and scoped checks": {
+			admissionChecks: map[string]sets.Set[kueue.ResourceFlavorReference]{
+				"global": sets.New[kueue.ResourceFlavorReference](),
+				"scoped": sets.New[kueue.ResourceFlavorReference]("f1", "f2"),
+				"single": sets.New[kueue.ResourceFlavorReference]("f3"),
+			},
+			want: map[string]sets.Set[kueue.ResourceFlavorReference]{
+				"scoped": sets.New[kueue.ResourceFlavorReference]("f1", "f2"),
+				"single": sets.New[kueue.ResourceFlavorReference]("f3"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cq := &ClusterQueueSnapshot{AdmissionChecks: tc.admissionChecks}
+			got := cq.FlavorScopedAdmissionChecks()
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Unexpected flavor-scoped admission checks (-want,+got):\n%s", diff)
+			}
+			for ac, flavors := range got {
+				flavors.Insert("modified")
+				if tc.admissionChecks[ac].Has("modified") {
+					t.Errorf("Modifying the result for %q changed the snapshot", ac)
+				}
+			}
+		})
+	}
+}
+
+func TestResumeImpact(t *testing.T) {
+	// rayWorkload returns a suspended RayCluster workload with a head and
+	// two workers, previously assigned to the default flavor.
+	rayWorkload := func(workerCPU int64) *workload.Info {
+		return &workload.Info{
+			Obj: utiltesting.MakeWorkload("raycluster", "ns").Active(false).Obj(),
+			TotalRequests: []workload.PodSetResources{
+				{
+					Name:     "head",
+					Count:    1,
+					Requests: resources.Requests{corev1.ResourceCPU: 1_000},
+					Flavors:  map[corev1.ResourceName]kueue.ResourceFlavorReference{corev1.ResourceCPU: "default"},
+				},
+				{
+					Name:     "workers",
+					Count:    2,
+					Requests: resources.Requests{corev1.ResourceCPU: 2 * workerCPU},
+					Flavors:  map[corev1.ResourceName]kueue.ResourceFlavorReference{corev1.ResourceCPU: "default"},
+				},
+			},
+		}
+	}
+	// unadmittedWorkload returns a suspended workload which was never
+	// assigned flavors.
+	unadmittedWorkload := func(cpu string) *workload.Info {
+		return workload.NewInfo(utiltesting.MakeWorkload("raycluster", "ns").
+			Active(false).
+			Request(corev1.ResourceCPU, cpu).
+			Obj())
+	}
+	admitted := func(name, cq, flavor, cpu string) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, cpu).
+			ReserveQuota(utiltesting.MakeAdmission(cq).Assignment(corev1.ResourceCPU, kueue.ResourceFlavorReference(flavor), cpu).Obj()).
+			Admitted(true).
+			Obj()
+	}
+
+	cases := map[string]struct {
+		workloads        []*kueue.Workload
+		wl               *workload.Info
+		wantFits         bool
+		wantNeedsBorrow  bool
+		wantNeedsPreempt bool
+	}{
+		"fits": {
+			wl:       rayWorkload(1_000),
+			wantFits: true,
+		},
+		"needs borrowing": {
+			workloads:       []*kueue.Workload{admitted("existing", "cq", "default", "2")},
+			wl:              rayWorkload(1_000),
+			wantFits:        true,
+			wantNeedsBorrow: true,
+		},
+		"needs preemption": {
+			workloads: []*kueue.Workload{
+				admitted("existing", "cq", "default", "2"),
+				admitted("other", "other", "default", "4"),
+			},
+			wl:               rayWorkload(1_000),
+			wantNeedsBorrow:  true,
+			wantNeedsPreempt: true,
+		},
+		"never fits": {
+			wl:              rayWorkload(5_000),
+			wantNeedsBorrow: true,
+		},
+		"unadmitted workload fits": {
+			wl:       unadmittedWorkload("3"),
+			wantFits: true,
+		},
+		"unadmitted workload is assigned the flavor which doesn't borrow": {
+			workloads: []*kueue.Workload{admitted("existing", "cq", "default", "2")},
+			wl:        unadmittedWorkload("3"),
+			wantFits:  true,
+		},
+		"unadmitted workload needs borrowing": {
+			workloads: []*kueue.Workload{
+				admitted("existing", "cq", "default", "2"),
+				admitted("existing-spot", "cq", "spot", "2"),
+			},
+			wl:              unadmittedWorkload("3"),
+			wantFits:        true,
+			wantNeedsBorrow: true,
+		},
+		"unadmitted workload needs preemption": {
+			workloads: []*kueue.Workload{
+				admitted("existing", "cq", "default", "2"),
+				admitted("existing-spot", "cq", "spot", "4"),
+				admitted("other", "other", "default", "4"),
+			},
+			wl:               unadmittedWorkload("3"),
+			wantNeedsBorrow:  true,
+			wantNeedsPreempt: true,
+		},
+		"unadmitted workload requesting an uncovered resource": {
+			wl: workload.NewInfo(utiltesting.MakeWorkload("raycluster", "ns").
+				Active(false).
+				Request(corev1.ResourceMemory, "1Gi").
+				Obj()),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq").
+					Cohort("cohort").
+					ResourceGroup(
+						*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj(),
+						*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4").Obj(),
+					).
+					Obj(),
+				utiltesting.MakeClusterQueue("other").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for _, wl := range tc.workloads {
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			fits, needsBorrow, needsPreempt := snapshot.ClusterQueue("cq").ResumeImpact(tc.wl)
+			if fits != tc.wantFits {
+				t.Errorf("Unexpected fits, want=%v, got=%v", tc.wantFits, fits)
+			}
+			if needsBorrow != tc.wantNeedsBorrow {
+				t.Errorf("Unexpected needsBorrow, want=%v, got=%v", tc.wantNeedsBorrow, needsBorrow)
+			}
+			if needsPreempt != tc.wantNeedsPreempt {
+				t.Errorf("Unexpected needsPreempt, want=%v, got=%v", tc.wantNeedsPreempt, needsPreempt)
+			}
+		})
+	}
+}
+
+func TestReservedUsage(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+			Resource(corev1.ResourceCPU, "10").
+			Resource(corev1.ResourceMemory, "10Gi").
+			Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("admitted", "ns").
+			Request(corev1.ResourceCPU, "2").
+			Request(corev1.ResourceMemory, "1Gi").
+			ReserveQuota(utiltesting.MakeAdmission("cq").
+				Assignment(corev1.ResourceCPU, "default", "2").
+				Assignment(corev1.ResourceMemory, "default", "1Gi").
+				Obj()).
+			Admitted(true).
+			Obj(),
+		utiltesting.MakeWorkload("reserving", "ns").
+			Request(corev1.ResourceCPU, "3").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "3").Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	want := resources.FlavorResourceQuantities{
+		{Flavor: "default", Resource: corev1.ResourceCPU}:    5_000,
+		{Flavor: "default", Resource: corev1.ResourceMemory}: utiltesting.Gi,
+	}
+	if diff := cmp.Diff(want, snapshot.ClusterQueue("cq").ReservedUsage()); diff != "" {
+		t.Errorf("Unexpected reserved usage (-want,+got):\n%s", diff)
+	}
+	wantAdmitted := resources.FlavorResourceQuantities{
+		{Flavor: "default", Resource: corev1.ResourceCPU}:    2_000,
+		{Flavor: "default", Resource: corev1.ResourceMemory}: utiltesting.Gi,
+	}
+	if diff := cmp.Diff(wantAdmitted, cache.hm.ClusterQueue("cq").AdmittedUsage); diff != "" {
+		t.Errorf("Unexpected admitted usage (-want,+got):\n%s", diff)
+	}
+}
+
+func TestDistinctFlavorCount(t *testing.T) {
+	cases := map[string]struct {
+		cq   *kueue.ClusterQueue
+		want int
+	}{
+		"no resource groups": {
+			cq:   utiltesting.MakeClusterQueue("cq").Obj(),
+			want: 0,
+		},
+		"distinct flavors across groups": {
+			cq: utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "10").Obj(),
+					*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "10").Obj(),
+				).
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("gpu").Resource("example.com/gpu", "2").Obj()).
+				Obj(),
+			want: 3,
+		},
+		"overlapping flavors across groups": {
+			cq: utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "10").Obj(),
+					*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "10").Obj(),
+				).
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("spot").Resource("example.com/gpu", "2").Obj(),
+					*utiltesting.MakeFlavorQuotas("gpu").Resource("example.com/gpu", "2").Obj(),
+				).
+				Obj(),
+			want: 3,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			for _, rf := range []string{"on-demand", "spot", "gpu"} {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor(rf).Obj())
+			}
+			if err := cache.AddClusterQueue(ctx, tc.cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.ClusterQueue("cq").DistinctFlavorCount(); got != tc.want {
+				t.Errorf("Unexpected distinct flavor count, want=%d, got=%d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPodsOnFlavor(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("gpu").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "100").Obj(),
+			*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "100").Obj(),
+		).
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("gpu").Resource("example.com/gpu", "10").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	for _, wl := range []*kueue.Workload{
+		// The driver runs on on-demand and the workers on spot.
+		utiltesting.MakeWorkload("split", "ns").
+			PodSets(
+				*utiltesting.MakePodSet("driver", 1).Request(corev1.ResourceCPU, "1").Obj(),
+				*utiltesting.MakePodSet("workers", 4).Request(corev1.ResourceCPU, "2").Obj(),
+			).
+			ReserveQuota(utiltesting.MakeAdmission("cq", "driver", "workers").
+				AssignmentWithIndex(0, corev1.ResourceCPU, "on-demand", "1").
+				AssignmentPodCountWithIndex(0, 1).
+				AssignmentWithIndex(1, corev1.ResourceCPU, "spot", "8").
+				AssignmentPodCountWithIndex(1, 4).
+				Obj()).
+			Admitted(true).
+			Obj(),
+		// The pods use both the spot and gpu flavors.
+		utiltesting.MakeWorkload("gpu", "ns").
+			PodSets(*utiltesting.MakePodSet("main", 3).
+				Request(corev1.ResourceCPU, "1").
+				Request("example.com/gpu", "1").
+				Obj()).
+			ReserveQuota(utiltesting.MakeAdmission("cq").
+				Assignment(corev1.ResourceCPU, "spot", "3").
+				Assignment("example.com/gpu", "gpu", "3").
+				AssignmentPodCount(3).
+				Obj()).
+			Admitted(true).
+			Obj(),
+		// Reserving quota without being admitted.
+		utiltesting.MakeWorkload("reserving", "ns").
+			PodSets(*utiltesting.MakePodSet("main", 5).Request(corev1.ResourceCPU, "1").Obj()).
+			ReserveQuota(utiltesting.MakeAdmission("cq").
+				Assignment(corev1.ResourceCPU, "on-demand", "5").
+				AssignmentPodCount(5).
+				Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	want := map[kueue.ResourceFlavorReference]int{
+		"on-demand": 1,
+		"spot":      7,
+		"gpu":       3,
+		"other":     0,
+	}
+	for flavor, wantPods := range want {
+		if got := snapshot.ClusterQueue("cq").PodsOnFlavor(flavor); got != wantPods {
+			t.Errorf("Unexpected pods on flavor %q, want=%d, got=%d", flavor, wantPods, got)
+		}
+	}
+}
+
+func TestUtilizationSample(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("gpu").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "100").Obj(),
+			*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "50").Obj(),
+		).
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("gpu").Resource("example.com/gpu", "10").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("split", "ns").
+			PodSets(
+				*utiltesting.MakePodSet("driver", 1).Request(corev1.ResourceCPU, "1").Obj(),
+				*utiltesting.MakePodSet("workers", 4).Request(corev1.ResourceCPU, "2").Obj(),
+			).
+			ReserveQuota(utiltesting.MakeAdmission("cq", "driver", "workers").
+				AssignmentWithIndex(0, corev1.ResourceCPU, "on-demand", "1").
+				AssignmentPodCountWithIndex(0, 1).
+				AssignmentWithIndex(1, corev1.ResourceCPU, "spot", "8").
+				AssignmentPodCountWithIndex(1, 4).
+				Obj()).
+			Admitted(true).
+			Obj(),
+		utiltesting.MakeWorkload("gpu", "ns").
+			PodSets(*utiltesting.MakePodSet("main", 3).
+				Request(corev1.ResourceCPU, "1").
+				Request("example.com/gpu", "1").
+				Obj()).
+			ReserveQuota(utiltesting.MakeAdmission("cq").
+				Assignment(corev1.ResourceCPU, "spot", "3").
+				Assignment("example.com/gpu", "gpu", "3").
+				AssignmentPodCount(3).
+				Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	want := map[corev1.ResourceName]ResourceUtilization{
+		corev1.ResourceCPU: {Usage: 12_000, Nominal: 150_000},
+		"example.com/gpu":  {Usage: 3, Nominal: 10},
+	}
+	if diff := cmp.Diff(want, snapshot.ClusterQueue("cq").UtilizationSample()); diff != "" {
+		t.Errorf("Unexpected utilization sample (-want,+got):\n%s", diff)
+	}
+}
+
+func TestOverAdmitted(t *testing.T) {
+	cases := map[string]struct {
+		nominal string
+		want    []resources.FlavorResource
+	}{
+		"healthy, borrowing within the cohort capacity": {
+			nominal: "4",
+		},
+		"over-admitted after the quota was reduced": {
+			nominal: "1",
+			want: []resources.FlavorResource{
+				{Flavor: "default", Resource: corev1.ResourceCPU},
+				{Flavor: "default", Resource: corev1.ResourceMemory},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := func(nominal string) *kueue.ClusterQueue {
+				return utiltesting.MakeClusterQueue("cq").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, nominal).
+						Resource(corev1.ResourceMemory, nominal+"Gi").
+						Obj()).
+					Obj()
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				cq("4"),
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "2").
+						Resource(corev1.ResourceMemory, "2Gi").
+						Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			wl := utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "5").
+				Request(corev1.ResourceMemory, "5Gi").
+				ReserveQuota(utiltesting.MakeAdmission("cq").
+					Assignment(corev1.ResourceCPU, "default", "5").
+					Assignment(corev1.ResourceMemory, "default", "5Gi").
+					Obj()).
+				Admitted(true).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatal("Failed adding workload")
+			}
+			if err := cache.UpdateClusterQueue(log, cq(tc.nominal)); err != nil {
+				t.Fatalf("Failed updating ClusterQueue: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot.ClusterQueue("cq").OverAdmitted()); diff != "" {
+				t.Errorf("Unexpected over-admitted resources (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkloadInfo(t *testing.T) {
+	wl := utiltesting.MakeWorkload("wl", "ns").
+		Request(corev1.ResourceCPU, "2").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "2").Obj()).
+		Obj()
+	wi := workload.NewInfo(wl)
+	cq := &ClusterQueueSnapshot{
+		Workloads: map[string]*workload.Info{workload.Key(wl): wi},
+	}
+
+	if _, found := cq.WorkloadInfo("ns/missing"); found {
+		t.Error("Expected the missing workload not to be found")
+	}
+
+	got, found := cq.WorkloadInfo(workload.Key(wl))
+	if !found {
+		t.Fatal("Expected the workload to be found")
+	}
+	if diff := cmp.Diff(wi, got); diff != "" {
+		t.Errorf("Unexpected workload info (-want,+got):\n%s", diff)
+	}
+
+	got.Obj.Name = "modified"
+	got.TotalRequests[0].Requests[corev1.ResourceCPU] = 5_000
+	got.TotalRequests[0].Flavors[corev1.ResourceCPU] = "spot"
+	want := workload.NewInfo(wl)
+	if diff := cmp.Diff(want, cq.Workloads[workload.Key(wl)]); diff != "" {
+		t.Errorf("Unexpected change in the snapshot (-want,+got):\n%s", diff)
+	}
+}
+
+func TestEffectiveCapacity(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("borrower").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj(),
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4", "1").Obj(),
+			).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "6").Obj(),
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "6").Obj(),
+			).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("borrowing", "ns").
+			Request(corev1.ResourceCPU, "5").
+			ReserveQuota(utiltesting.MakeAdmission("borrower").Assignment(corev1.ResourceCPU, "default", "5").Obj()).
+			Obj(),
+		utiltesting.MakeWorkload("lender-usage", "ns").
+			Request(corev1.ResourceCPU, "2").
+			ReserveQuota(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "default", "2").Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[string]struct {
+		includeBorrowable bool
+		want              resources.FlavorResourceQuantities
+	}{
+		"nominal only": {
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: corev1.ResourceCPU}: 4_000,
+				{Flavor: "spot", Resource: corev1.ResourceCPU}:    4_000,
+			},
+		},
+		"including borrowable": {
+			includeBorrowable: true,
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: corev1.ResourceCPU}: 8_000,
+				{Flavor: "spot", Resource: corev1.ResourceCPU}:    5_000,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := snapshot.ClusterQueue("borrower").EffectiveCapacity(tc.includeBorrowable)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected effective capacity (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHowManyFit(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "32").
+				Resource("example.com/gpu", "4").
+				Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "0").
+				Resource("example.com/gpu", "2").
+				Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	wl := utiltesting.MakeWorkload("running", "ns").
+		Request(corev1.ResourceCPU, "4").
+		Request("example.com/gpu", "1").
+		ReserveQuota(utiltesting.MakeAdmission("cq").
+			Assignment(corev1.ResourceCPU, "default", "4").
+			Assignment("example.com/gpu", "default", "1").
+			Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, wl) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	gpu := resources.FlavorResource{Flavor: "default", Resource: "example.com/gpu"}
+	cases := map[string]struct {
+		perWorkload resources.FlavorResourceQuantities
+		want        int
+	}{
+		"gpu is the limiting factor, including borrowing": {
+			perWorkload: resources.FlavorResourceQuantities{cpu: 2_000, gpu: 1},
+			want:        5,
+		},
+		"gpu limits workloads requesting two gpus": {
+			perWorkload: resources.FlavorResourceQuantities{cpu: 1_000, gpu: 2},
+			want:        2,
+		},
+		"cpu is the limiting factor": {
+			perWorkload: resources.FlavorResourceQuantities{cpu: 8_000, gpu: 1},
+			want:        3,
+		},
+		"does not fit": {
+			perWorkload: resources.FlavorResourceQuantities{gpu: 6},
+			want:        0,
+		},
+		"unknown flavor": {
+			perWorkload: resources.FlavorResourceQuantities{{Flavor: "spot", Resource: "example.com/gpu"}: 1},
+			want:        0,
+		},
+		"empty usage": {
+			want: 0,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := snapshot.ClusterQueue("cq").HowManyFit(tc.perWorkload); got != tc.want {
+				t.Errorf("Unexpected number of workloads which fit, want=%d, got=%d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOrphanUsage(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	if got := snapshot.ClusterQueue("cq").OrphanUsage(); len(got) != 0 {
+		t.Errorf("Unexpected orphan usage without workloads: %v", got)
+	}
+
+	// The workload was admitted with flavors and resources which are not
+	// in the ClusterQueue.
+	wl := utiltesting.MakeWorkload("wl", "ns").
+		Request(corev1.ResourceCPU, "2").
+		Request(corev1.ResourceMemory, "1Gi").
+		ReserveQuota(utiltesting.MakeAdmission("cq").
+			Assignment(corev1.ResourceCPU, "spot", "2").
+			Assignment(corev1.ResourceMemory, "default", "1Gi").
+			Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, wl) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err = cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	want := []resources.FlavorResource{
+		{Flavor: "default", Resource: corev1.ResourceMemory},
+		{Flavor: "spot", Resource: corev1.ResourceCPU},
+	}
+	if diff := cmp.Diff(want, snapshot.ClusterQueue("cq").OrphanUsage()); diff != "" {
+		t.Errorf("Unexpected orphan usage (-want,+got):\n%s", diff)
+	}
+}
+
+func TestMinBorrowAssignment(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "2").Obj(),
+				*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+			).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "10").Obj(),
+				*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+			).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[string]struct {
+		wl             *kueue.Workload
+		wantAssignment resources.FlavorResourceQuantities
+		wantFits       bool
+	}{
+		"both flavors fit, the second one borrows less": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "6").Obj(),
+			wantAssignment: resources.FlavorResourceQuantities{
+				{Flavor: "on-demand", Resource: corev1.ResourceCPU}: 6_000,
+			},
+			wantFits: true,
+		},
+		"no flavor borrows, the first one is preferred": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "2").Obj(),
+			wantAssignment: resources.FlavorResourceQuantities{
+				{Flavor: "spot", Resource: corev1.ResourceCPU}: 2_000,
+			},
+			wantFits: true,
+		},
+		"only the first flavor fits": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "10").Obj(),
+			wantAssignment: resources.FlavorResourceQuantities{
+				{Flavor: "spot", Resource: corev1.ResourceCPU}: 10_000,
+			},
+			wantFits: true,
+		},
+		"does not fit": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "20").Obj(),
+		},
+		"resource not covered": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request("example.com/gpu", "1").Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotAssignment, gotFits := snapshot.ClusterQueue("cq").MinBorrowAssignment(workload.NewInfo(tc.wl))
+			if gotFits != tc.wantFits {
+				t.Errorf("Unexpected fits, want=%v, got=%v", tc.wantFits, gotFits)
+			}
+			if diff := cmp.Diff(tc.wantAssignment, gotAssignment); diff != "" {
+				t.Errorf("Unexpected assignment (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinBorrowAssignmentWhileBorrowing(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "2").Obj(),
+				*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+			).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "10").Obj(),
+				*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+			).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	// The ClusterQueue already borrows 2 spot CPUs and uses its whole
+	// on-demand quota, so one more CPU adds as much borrowing in either
+	// flavor, and the first flavor is preferred.
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("spot", "ns").
+			Request(corev1.ResourceCPU, "4").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "spot", "4").Obj()).
+			Obj(),
+		utiltesting.MakeWorkload("on-demand", "ns").
+			Request(corev1.ResourceCPU, "4").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "on-demand", "4").Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	gotAssignment, gotFits := snapshot.ClusterQueue("cq").MinBorrowAssignment(workload.NewInfo(
+		utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "1").Obj()))
+	if !gotFits {
+		t.Errorf("Unexpected fits, want=true, got=false")
+	}
+	wantAssignment := resources.FlavorResourceQuantities{
+		{Flavor: "spot", Resource: corev1.ResourceCPU}: 1_000,
+	}
+	if diff := cmp.Diff(wantAssignment, gotAssignment); diff != "" {
+		t.Errorf("Unexpected assignment (-want,+got):\n%s", diff)
+	}
+}
+
+func TestRequestsUsageAccounting(t *testing.T) {
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		opts                []Option
+		nominal             string
+		podSets             []kueue.PodSet
+		wantAssignment      resources.FlavorResourceQuantities
+		wantFits            bool
+		wantIncrease        resources.FlavorResourceQuantities
+		wantConstraintFound bool
+	}{
+		"max podset accounting": {
+			opts:    []Option{WithPodSetAccounting(workload.PodSetAccountingMax)},
+			nominal: "4",
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("driver", 1).Request(corev1.ResourceCPU, "3").Obj(),
+				*utiltesting.MakePodSet("workers", 1).Request(corev1.ResourceCPU, "3").Obj(),
+			},
+			wantAssignment: resources.FlavorResourceQuantities{cpu: 3_000},
+			wantFits:       true,
+			wantIncrease:   resources.FlavorResourceQuantities{},
+		},
+		"quota granularity": {
+			opts:    []Option{WithQuotaGranularity(resources.FlavorResourceQuantities{cpu: 2_000})},
+			nominal: "3",
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("main", 1).Request(corev1.ResourceCPU, "3").Obj(),
+			},
+			wantIncrease:        resources.FlavorResourceQuantities{cpu: 1_000},
+			wantConstraintFound: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), tc.opts...)
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, tc.nominal).Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cqSnapshot := snapshot.ClusterQueue("cq")
+			wl := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").PodSets(tc.podSets...).Obj())
+
+			gotAssignment, gotFits := cqSnapshot.MinBorrowAssignment(wl)
+			if gotFits != tc.wantFits {
+				t.Errorf("Unexpected fits, want=%v, got=%v", tc.wantFits, gotFits)
+			}
+			if diff := cmp.Diff(tc.wantAssignment, gotAssignment); diff != "" {
+				t.Errorf("Unexpected assignment (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantIncrease, cqSnapshot.MinQuotaIncreaseToFit(wl)); diff != "" {
+				t.Errorf("Unexpected quota increase (-want,+got):\n%s", diff)
+			}
+			if constraint, found := cqSnapshot.BindingConstraint(wl); found != tc.wantConstraintFound {
+				t.Errorf("Unexpected binding constraint, want found=%v, got %v (found=%v)", tc.wantConstraintFound, constraint, found)
+			}
+		})
+	}
+}
+
+func TestBindingConstraint(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("a100").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("h100").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("a100").
+				Resource(corev1.ResourceCPU, "32").
+				Resource("example.com/gpu", "2").
+				Obj(),
+			*utiltesting.MakeFlavorQuotas("h100").
+				Resource(corev1.ResourceCPU, "8").
+				Resource("example.com/gpu", "3").
+				Obj(),
+		).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[string]struct {
+		wl             *kueue.Workload
+		wantConstraint resources.FlavorResource
+		wantFound      bool
+	}{
+		"gpu is binding despite plenty of cpu": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "4").
+				Request("example.com/gpu", "4").
+				Obj(),
+			wantConstraint: resources.FlavorResource{Flavor: "a100", Resource: "example.com/gpu"},
+			wantFound:      true,
+		},
+		"the flavor lacking fewer resources is considered": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "40").
+				Request("example.com/gpu", "3").
+				Obj(),
+			wantConstraint: resources.FlavorResource{Flavor: "h100", Resource: corev1.ResourceCPU},
+			wantFound:      true,
+		},
+		"fits": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "4").
+				Request("example.com/gpu", "1").
+				Obj(),
+		},
+		"resource not covered": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceMemory, "1Gi").
+				Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotConstraint, gotFound := snapshot.ClusterQueue("cq").BindingConstraint(workload.NewInfo(tc.wl))
+			if gotFound != tc.wantFound {
+				t.Errorf("Unexpected found, want=%v, got=%v", tc.wantFound, gotFound)
+			}
+			if diff := cmp.Diff(tc.wantConstraint, gotConstraint); diff != "" {
+				t.Errorf("Unexpected binding constraint (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMaxPreemptionFreeRequest(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4").Obj(),
+			).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "2").Obj(),
+			).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("own", "ns").
+			Request(corev1.ResourceCPU, "3").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "on-demand", "3").Obj()).
+			Obj(),
+		utiltesting.MakeWorkload("lender-on-demand", "ns").
+			Request(corev1.ResourceCPU, "4").
+			ReserveQuota(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "on-demand", "4").Obj()).
+			Obj(),
+		utiltesting.MakeWorkload("lender-spot", "ns").
+			Request(corev1.ResourceCPU, "1").
+			ReserveQuota(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "spot", "1").Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	cq := snapshot.ClusterQueue("cq")
+
+	// spot has 4 free in the ClusterQueue and 1 which can be borrowed.
+	if got := cq.MaxPreemptionFreeRequest(corev1.ResourceCPU); got != 5_000 {
+		t.Errorf("Unexpected preemption-free request, want=5000, got=%d", got)
+	}
+	// Preempting the workloads would allow up to the whole Cohort capacity.
+	if got := cq.PotentialAvailable(resources.FlavorResource{Flavor: "on-demand", Resource: corev1.ResourceCPU}); got != 8_000 {
+		t.Errorf("Unexpected potential available, want=8000, got=%d", got)
+	}
+	if got := cq.MaxPreemptionFreeRequest(corev1.ResourceMemory); got != 0 {
+		t.Errorf("Unexpected preemption-free request for an uncovered resource: %d", got)
+	}
+}
+
+func TestPreemptionDeficit(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "4").
+				Resource(corev1.ResourceMemory, "4Gi").
+				Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	running := utiltesting.MakeWorkload("running", "ns").
+		Request(corev1.ResourceCPU, "3").
+		Request(corev1.ResourceMemory, "3Gi").
+		ReserveQuota(utiltesting.MakeAdmission("cq").
+			Assignment(corev1.ResourceCPU, "default", "3").
+			Assignment(corev1.ResourceMemory, "default", "3Gi").
+			Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, running) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[string]struct {
+		cpu    string
+		memory string
+		want   resources.FlavorResourceQuantities
+	}{
+		"fits in the free and borrowable quota": {
+			cpu:    "3",
+			memory: "1Gi",
+			want:   resources.FlavorResourceQuantities{},
+		},
+		"partial free capacity reduces the deficit": {
+			cpu:    "5",
+			memory: "2Gi",
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: corev1.ResourceCPU}:    2_000,
+				{Flavor: "default", Resource: corev1.ResourceMemory}: utiltesting.Gi,
+			},
+		},
+		"no free capacity": {
+			cpu:    "3",
+			memory: "5Gi",
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: corev1.ResourceMemory}: 4 * utiltesting.Gi,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, tc.cpu).
+				Request(corev1.ResourceMemory, tc.memory).
+				ReserveQuota(utiltesting.MakeAdmission("cq").
+					Assignment(corev1.ResourceCPU, "default", tc.cpu).
+					Assignment(corev1.ResourceMemory, "default", tc.memory).
+					Obj()).
+				Obj())
+			if diff := cmp.Diff(tc.want, snapshot.ClusterQueue("cq").PreemptionDeficit(wl)); diff != "" {
+				t.Errorf("Unexpected preemption deficit (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinPriorityToPreempt(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "6").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	for _, w := range []struct {
+		name     string
+		priority int32
+		cpu      string
+	}{
+		{name: "low-a", priority: 1, cpu: "1"},
+		{name: "low-b", priority: 1, cpu: "1"},
+		{name: "mid", priority: 5, cpu: "2"},
+		{name: "high", priority: 10, cpu: "1"},
+	} {
+		wl := utiltesting.MakeWorkload(w.name, "ns").
+			Priority(w.priority).
+			Request(corev1.ResourceCPU, w.cpu).
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", w.cpu).Obj()).
+			Obj()
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %s", w.name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	cqSnapshot := snapshot.ClusterQueue("cq")
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+
+	cases := map[string]struct {
+		required     int64
+		wantPriority int32
+		wantOk       bool
+	}{
+		"fits without preemptions": {
+			required:     1_000,
+			wantPriority: math.MinInt32,
+			wantOk:       true,
+		},
+		"preempting the lowest priority workloads": {
+			required:     3_000,
+			wantPriority: 2,
+			wantOk:       true,
+		},
+		"preempting the workloads up to the middle priority": {
+			required:     5_000,
+			wantPriority: 6,
+			wantOk:       true,
+		},
+		"preempting all the workloads": {
+			required:     6_000,
+			wantPriority: 11,
+			wantOk:       true,
+		},
+		"exceeding the quota": {
+			required: 7_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			usageBefore := maps.Clone(cqSnapshot.ResourceNode.Usage)
+			gotPriority, gotOk := cqSnapshot.MinPriorityToPreempt(resources.FlavorResourceQuantities{cpu: tc.required})
+			if gotPriority != tc.wantPriority || gotOk != tc.wantOk {
+				t.Errorf("Unexpected result, want=(%d, %t), got=(%d, %t)", tc.wantPriority, tc.wantOk, gotPriority, gotOk)
+			}
+			if diff := cmp.Diff(usageBefore, cqSnapshot.ResourceNode.Usage); diff != "" {
+				t.Errorf("Unexpected change of the usage in the snapshot (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGuaranteedShortfall(t *testing.T) {
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	makeWorkload := func(name, cq, quantity string) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, quantity).
+			ReserveQuota(utiltesting.MakeAdmission(cq).Assignment(corev1.ResourceCPU, "default", quantity).Obj()).
+			Obj()
+	}
+
+	cases := map[string]struct {
+		workloads []*kueue.Workload
+		want      resources.FlavorResourceQuantities
+	}{
+		"no usage": {
+			want: resources.FlavorResourceQuantities{},
+		},
+		"peer using its own quota": {
+			workloads: []*kueue.Workload{makeWorkload("peer", "peer", "2")},
+			want:      resources.FlavorResourceQuantities{},
+		},
+		"peer borrowing part of the lendable quota": {
+			workloads: []*kueue.Workload{makeWorkload("peer", "peer", "3")},
+			want:      resources.FlavorResourceQuantities{cpu: 1_000},
+		},
+		"peer borrowing all the lendable quota": {
+			workloads: []*kueue.Workload{makeWorkload("peer", "peer", "4")},
+			want:      resources.FlavorResourceQuantities{cpu: 2_000},
+		},
+		"own usage doesn't count as a shortfall": {
+			workloads: []*kueue.Workload{
+				makeWorkload("own", "cq", "1"),
+				makeWorkload("peer", "peer", "4"),
+			},
+			want: resources.FlavorResourceQuantities{cpu: 2_000},
+		},
+		"nominal quota used": {
+			workloads: []*kueue.Workload{
+				makeWorkload("own", "cq", "4"),
+			},
+			want: resources.FlavorResourceQuantities{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4", "", "2").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("peer").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for _, wl := range tc.workloads {
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %s", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot.ClusterQueue("cq").GuaranteedShortfall()); diff != "" {
+				t.Errorf("Unexpected guaranteed shortfall (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFitsWithinGuaranteed(t *testing.T) {
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	memory := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceMemory}
+	cases := map[string]struct {
+		cq   kueue.ClusterQueueReference
+		req  resources.FlavorResourceQuantities
+		want bool
+	}{
+		"below guaranteed quota": {
+			cq:   "lend-a",
+			req:  resources.FlavorResourceQuantities{cpu: 3_000},
+			want: true,
+		},
+		"at guaranteed quota": {
+			cq:   "lend-a",
+			req:  resources.FlavorResourceQuantities{cpu: 4_000},
+			want: true,
+		},
+		"above guaranteed quota": {
+			cq:  "lend-a",
+			req: resources.FlavorResourceQuantities{cpu: 5_000},
+		},
+		"resource without guaranteed quota": {
+			cq:  "lend-a",
+			req: resources.FlavorResourceQuantities{cpu: 1_000, memory: utiltesting.Gi},
+		},
+		"no lending limit": {
+			cq:  "lend-b",
+			req: resources.FlavorResourceQuantities{cpu: 1_000},
+		},
+		"no Cohort": {
+			cq:   "standalone",
+			req:  resources.FlavorResourceQuantities{cpu: 10_000},
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("lend-a").
+					Cohort("lend").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "10", "", "4").
+						Resource(corev1.ResourceMemory, "10Gi").
+						Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("lend-b").
+					Cohort("lend").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "10").
+						Resource(corev1.ResourceMemory, "10Gi").
+						Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("standalone").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "10").
+						Resource(corev1.ResourceMemory, "10Gi").
+						Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			wl := utiltesting.MakeWorkload("lend-a-1", "ns").
+				Request(corev1.ResourceCPU, "2").
+				ReserveQuota(utiltesting.MakeAdmission("lend-a").Assignment(corev1.ResourceCPU, "default", "2").Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatal("Failed adding workload")
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.ClusterQueue(tc.cq).FitsWithinGuaranteed(tc.req); got != tc.want {
+				t.Errorf("Unexpected FitsWithinGuaranteed, want=%v, got=%v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConsolidationCandidates(t *testing.T) {
+	makeWorkload := func(name, flavor, quantity string, admitted bool) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, quantity).
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, kueue.ResourceFlavorReference(flavor), quantity).Obj()).
+			Admitted(admitted).
+			Obj()
+	}
+
+	cases := map[string]struct {
+		workloads []*kueue.Workload
+		resource  corev1.ResourceName
+		want      []string
+	}{
+		"no usage": {
+			resource: corev1.ResourceCPU,
+		},
+		"single flavor in use": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "alpha", "2", true),
+			},
+			resource: corev1.ResourceCPU,
+		},
+		"two half-full flavors": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "alpha", "1", true),
+				makeWorkload("b", "alpha", "1", true),
+				makeWorkload("c", "beta", "2", true),
+			},
+			resource: corev1.ResourceCPU,
+			want:     []string{"ns/a", "ns/b"},
+		},
+		"least used flavor is chosen": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "alpha", "2", true),
+				makeWorkload("b", "beta", "1", true),
+			},
+			resource: corev1.ResourceCPU,
+			want:     []string{"ns/b"},
+		},
+		"flavors too full to consolidate": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "alpha", "3", true),
+				makeWorkload("b", "beta", "3", true),
+			},
+			resource: corev1.ResourceCPU,
+		},
+		"flavor with a workload not admitted yet": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "alpha", "1", true),
+				makeWorkload("b", "alpha", "1", false),
+				makeWorkload("c", "beta", "2", true),
+			},
+			resource: corev1.ResourceCPU,
+			want:     []string{"ns/c"},
+		},
+		"resource not covered": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "alpha", "1", true),
+				makeWorkload("b", "beta", "1", true),
+			},
+			resource: corev1.ResourceMemory,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("alpha").Obj())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("beta").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("alpha").Resource(corev1.ResourceCPU, "4").Obj(),
+					*utiltesting.MakeFlavorQuotas("beta").Resource(corev1.ResourceCPU, "4").Obj(),
+				).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			for _, wl := range tc.workloads {
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %s", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot.ClusterQueue("cq").ConsolidationCandidates(tc.resource)); diff != "" {
+				t.Errorf("Unexpected consolidation candidates (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsPermanentlyUnschedulable(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("small").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("large").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		Cohort("cohort").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("small").Resource(corev1.ResourceCPU, "4").Obj(),
+			*utiltesting.MakeFlavorQuotas("large").Resource(corev1.ResourceCPU, "8").Obj(),
+		).
+		Obj()
+	lender := utiltesting.MakeClusterQueue("lender").
+		Cohort("cohort").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("large").Resource(corev1.ResourceCPU, "2").Obj()).
+		Obj()
+	twoFlavors := utiltesting.MakeClusterQueue("two-flavors").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("small").Resource(corev1.ResourceCPU, "8").Obj(),
+			*utiltesting.MakeFlavorQuotas("large").Resource(corev1.ResourceCPU, "8").Obj(),
+		).
+		Obj()
+	for _, cq := range []*kueue.ClusterQueue{cq, lender, twoFlavors} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	admitted := utiltesting.MakeWorkload("admitted", "ns").
+		Request(corev1.ResourceCPU, "8").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "large", "8").Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, admitted) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[string]struct {
+		cq   kueue.ClusterQueueReference
+		wl   *kueue.Workload
+		want bool
+	}{
+		"fits in the free quota": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "2").Obj(),
+		},
+		"fits only after preempting": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "8").Obj(),
+		},
+		"fits only borrowing from the cohort": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "10").Obj(),
+		},
+		"exceeds the capacity of every flavor": {
+			wl:   utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "11").Obj(),
+			want: true,
+		},
+		"exceeds the capacity of every flavor across pods": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				PodSets(*utiltesting.MakePodSet("main", 3).Request(corev1.ResourceCPU, "4").Obj()).
+				Obj(),
+			want: true,
+		},
+		"uncovered resource": {
+			wl:   utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceMemory, "1Gi").Obj(),
+			want: true,
+		},
+		"podsets fit in different flavors": {
+			cq: "two-flavors",
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				PodSets(
+					*utiltesting.MakePodSet("first", 1).Request(corev1.ResourceCPU, "6").Obj(),
+					*utiltesting.MakePodSet("second", 1).Request(corev1.ResourceCPU, "6").Obj(),
+				).
+				Obj(),
+		},
+		"podset exceeds the capacity of every flavor": {
+			cq: "two-flavors",
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				PodSets(
+					*utiltesting.MakePodSet("first", 1).Request(corev1.ResourceCPU, "6").Obj(),
+					*utiltesting.MakePodSet("second", 1).Request(corev1.ResourceCPU, "9").Obj(),
+				).
+				Obj(),
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cqName := tc.cq
+			if cqName == "" {
+				cqName = "cq"
+			}
+			got := snapshot.ClusterQueue(cqName).IsPermanentlyUnschedulable(workload.NewInfo(tc.wl))
+			if got != tc.want {
+				t.Errorf("Unexpected IsPermanentlyUnschedulable, want=%v, got=%v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMinQuotaIncreaseToFit(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("a100").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("h100").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "8").Obj()).
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("a100").Resource("example.com/gpu", "2").Obj(),
+			*utiltesting.MakeFlavorQuotas("h100").Resource("example.com/gpu", "3").Obj(),
+		).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admitted := utiltesting.MakeWorkload("admitted", "ns").
+		Request(corev1.ResourceCPU, "2").
+		Request("example.com/gpu", "2").
+		ReserveQuota(utiltesting.MakeAdmission("cq").
+			Assignment(corev1.ResourceCPU, "default", "2").
+			Assignment("example.com/gpu", "h100", "2").
+			Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, admitted) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[string]struct {
+		wl           *kueue.Workload
+		wantIncrease resources.FlavorResourceQuantities
+	}{
+		"already fits": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "4").
+				Request("example.com/gpu", "1").
+				Obj(),
+			wantIncrease: resources.FlavorResourceQuantities{},
+		},
+		"small gpu quota bump": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "4").
+				Request("example.com/gpu", "3").
+				Obj(),
+			wantIncrease: resources.FlavorResourceQuantities{
+				{Flavor: "a100", Resource: "example.com/gpu"}: 1,
+			},
+		},
+		"gpu and cpu bumps": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "7").
+				Request("example.com/gpu", "4").
+				Obj(),
+			wantIncrease: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: corev1.ResourceCPU}: 1_000,
+				{Flavor: "a100", Resource: "example.com/gpu"}:     2,
+			},
+		},
+		"resource not covered": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceMemory, "1Gi").
+				Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := snapshot.ClusterQueue("cq").MinQuotaIncreaseToFit(workload.NewInfo(tc.wl))
+			if diff := cmp.Diff(tc.wantIncrease, got); diff != "" {
+				t.Errorf("Unexpected quota increase (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	testingmetrics "sigs.k8s.io/kueue/pkg/util/testing/metrics"
 )
 
 func TestClusterQueueUpdateWithFlavors(t *testing.T) {
@@ -784,3 +785,100 @@ func TestClusterQueueReadinessWithTAS(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatedResourceGroupsWithDuplicateFlavors(t *testing.T) {
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(
+			*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "5").Obj(),
+			*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "5").Obj(),
+			*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "3").Obj(),
+		).
+		Obj()
+
+	gotFlavors := createdResourceGroups(cq.Spec.ResourceGroups)[0].Flavors
+	if diff := cmp.Diff([]kueue.ResourceFlavorReference{"on-demand", "spot"}, gotFlavors); diff != "" {
+		t.Errorf("Unexpected flavors (-want,+got):\n%s", diff)
+	}
+	gotDuplicates := findDuplicateFlavors(cq.Spec.ResourceGroups)
+	if diff := cmp.Diff([]kueue.ResourceFlavorReference{"on-demand"}, gotDuplicates); diff != "" {
+		t.Errorf("Unexpected duplicate flavors (-want,+got):\n%s", diff)
+	}
+}
+
+func TestResourceOvercommitMetrics(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient(), WithReportResourceMetrics(true))
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("overcommit-cq").
+			Cohort("overcommit-cohort").
+			ResourceGroup(
+				*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj(),
+				*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4").Obj(),
+			).
+			Obj(),
+		utiltesting.MakeClusterQueue("overcommit-lender").
+			Cohort("overcommit-cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("borrowing", "ns").
+			Request(corev1.ResourceCPU, "6").
+			ReserveQuota(utiltesting.MakeAdmission("overcommit-cq").Assignment(corev1.ResourceCPU, "default", "6").Obj()).
+			Obj(),
+		utiltesting.MakeWorkload("within-nominal", "ns").
+			Request(corev1.ResourceCPU, "3").
+			ReserveQuota(utiltesting.MakeAdmission("overcommit-cq").Assignment(corev1.ResourceCPU, "spot", "3").Obj()).
+			Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	collectMetrics := func() map[string]float64 {
+		got := make(map[string]float64)
+		for _, cqName := range []string{"overcommit-cq", "overcommit-lender"} {
+			for _, dp := range testingmetrics.CollectFilteredGaugeVec(metrics.ClusterQueueResourceOvercommit, map[string]string{"cluster_queue": cqName}) {
+				got[dp.Labels["cluster_queue"]+"/"+dp.Labels["flavor"]] = dp.Value
+			}
+		}
+		return got
+	}
+	t.Cleanup(func() {
+		metrics.ClearClusterQueueMetrics("overcommit-cq")
+		metrics.ClearClusterQueueMetrics("overcommit-lender")
+	})
+	wantMetrics := map[string]float64{
+		"overcommit-cq/default":     2,
+		"overcommit-cq/spot":        0,
+		"overcommit-lender/default": 0,
+	}
+	if diff := cmp.Diff(wantMetrics, collectMetrics()); diff != "" {
+		t.Errorf("Unexpected overcommit metrics (-want,+got):\n%s", diff)
+	}
+
+	if err := cache.DeleteWorkload(log, utiltesting.MakeWorkload("borrowing", "ns").Obj()); err != nil {
+		t.Fatalf("Failed deleting workload: %v", err)
+	}
+	wantMetrics["overcommit-cq/default"] = 0
+	if diff := cmp.Diff(wantMetrics, collectMetrics()); diff != "" {
+		t.Errorf("Unexpected overcommit metrics after deleting the borrowing workload (-want,+got):\n%s", diff)
+	}
+
+	if err := cache.UpdateClusterQueue(log, utiltesting.MakeClusterQueue("overcommit-cq").
+		Cohort("overcommit-cohort").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()); err != nil {
+		t.Fatalf("Failed updating ClusterQueue: %v", err)
+	}
+	delete(wantMetrics, "overcommit-cq/spot")
+	if diff := cmp.Diff(wantMetrics, collectMetrics()); diff != "" {
+		t.Errorf("Unexpected overcommit metrics after removing the spot quota (-want,+got):\n%s", diff)
+	}
+}
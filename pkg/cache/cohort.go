@@ -32,6 +32,15 @@ type cohort struct {
 	resourceNode ResourceNode
 
 	FairWeight resource.Quantity
+
+	// aggregation computes the SubtreeQuota from the quotas in the
+	// subtree. When nil, the quotas are summed.
+	aggregation CohortAggregation
+
+	// memberGenerations holds the AllocatableResourceGeneration of each
+	// ClusterQueue of the Cohort, as of the last time the Cohort recomputed
+	// their resources.
+	memberGenerations map[kueue.ClusterQueueReference]int64
 }
 
 func newCohort(name kueue.CohortReference) *cohort {
@@ -64,6 +73,22 @@ func (c *cohort) getRootUnsafe() *cohort {
 	return c.Parent().getRootUnsafe()
 }
 
+// hasActiveMember returns whether any ClusterQueue in the subtree of the
+// Cohort is active. It expects that no cycles exist in the Cohort graph.
+func (c *cohort) hasActiveMember() bool {
+	for _, cq := range c.ChildCQs() {
+		if cq.Active() {
+			return true
+		}
+	}
+	for _, child := range c.ChildCohorts() {
+		if child.hasActiveMember() {
+			return true
+		}
+	}
+	return false
+}
+
 // implements hierarchicalResourceNode interface.
 
 func (c *cohort) getResourceNode() ResourceNode {
@@ -0,0 +1,81 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+// CohortAggregation computes the SubtreeQuota of a Cohort, for a flavor
+// and resource, from the sum of its own nominal quota and of the quota
+// lent to it by its children. It is called while holding the cache lock,
+// so it must not call the cache.
+type CohortAggregation interface {
+	SubtreeQuota(cohort kueue.CohortReference, fr resources.FlavorResource, sum int64) int64
+}
+
+// SumCohortAggregation uses the sum of the quotas as the SubtreeQuota of
+// the Cohorts. This is the default.
+type SumCohortAggregation struct{}
+
+func (SumCohortAggregation) SubtreeQuota(_ kueue.CohortReference, _ resources.FlavorResource, sum int64) int64 {
+	return sum
+}
+
+// CappedSumCohortAggregation uses the sum of the quotas as the SubtreeQuota
+// of the Cohorts, capped by the quantities configured for each Cohort, for
+// example to model a shared pool of physical capacity. The flavors and
+// resources without a cap use the sum.
+type CappedSumCohortAggregation map[kueue.CohortReference]resources.FlavorResourceQuantities
+
+func (c CappedSumCohortAggregation) SubtreeQuota(cohort kueue.CohortReference, fr resources.FlavorResource, sum int64) int64 {
+	if limit, found := c[cohort][fr]; found {
+		return min(limit, sum)
+	}
+	return sum
+}
+
+// WithCohortAggregation sets how the SubtreeQuota of the Cohorts is
+// computed. By default, SumCohortAggregation is used.
+func WithCohortAggregation(aggregation CohortAggregation) Option {
+	return func(o *options) {
+		o.cohortAggregation = aggregation
+	}
+}
+
+// newCohortWithAggregation returns a constructor of Cohorts using the
+// aggregation.
+func newCohortWithAggregation(aggregation CohortAggregation) func(kueue.CohortReference) *cohort {
+	return func(name kueue.CohortReference) *cohort {
+		c := newCohort(name)
+		c.aggregation = aggregation
+		return c
+	}
+}
+
+// aggregateSubtreeQuota applies the aggregation of the Cohort to the
+// summed subtreeQuota. The SubtreeQuota is never lowered below the
+// guaranteed quota of the Cohort, which it doesn't lend to its parent.
+func (c *cohort) aggregateSubtreeQuota(subtreeQuota resources.FlavorResourceQuantities) {
+	if c.aggregation == nil {
+		return
+	}
+	for fr, sum := range subtreeQuota {
+		subtreeQuota[fr] = max(c.resourceNode.guaranteedQuota(fr), c.aggregation.SubtreeQuota(c.Name, fr, sum))
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestCohortAggregation(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		opts             []Option
+		wantSubtreeQuota int64
+		wantAvailable    int64
+	}{
+		"default": {
+			wantSubtreeQuota: 12_000,
+			wantAvailable:    7_000,
+		},
+		"sum": {
+			opts:             []Option{WithCohortAggregation(SumCohortAggregation{})},
+			wantSubtreeQuota: 12_000,
+			wantAvailable:    7_000,
+		},
+		"capped sum": {
+			opts: []Option{WithCohortAggregation(CappedSumCohortAggregation{
+				"pool": {fr: 8_000},
+			})},
+			wantSubtreeQuota: 8_000,
+			wantAvailable:    3_000,
+		},
+		"cap above the sum": {
+			opts: []Option{WithCohortAggregation(CappedSumCohortAggregation{
+				"pool": {fr: 20_000},
+			})},
+			wantSubtreeQuota: 12_000,
+			wantAvailable:    7_000,
+		},
+		"cap on another Cohort": {
+			opts: []Option{WithCohortAggregation(CappedSumCohortAggregation{
+				"other": {fr: 8_000},
+			})},
+			wantSubtreeQuota: 12_000,
+			wantAvailable:    7_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), tc.opts...)
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq-a").
+					Cohort("pool").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "6").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("cq-b").
+					Cohort("pool").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "6").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			wl := utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "5").
+				ReserveQuota(utiltesting.MakeAdmission("cq-a").Assignment(corev1.ResourceCPU, "default", "5").Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatal("Failed adding workload")
+			}
+			if err := cache.ValidateCohortTree("pool"); err != nil {
+				t.Errorf("Unexpected inconsistency in the Cohort tree: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.Cohort("pool").ResourceNode.SubtreeQuota[fr]; got != tc.wantSubtreeQuota {
+				t.Errorf("Unexpected SubtreeQuota, want=%d, got=%d", tc.wantSubtreeQuota, got)
+			}
+			if got := snapshot.ClusterQueue("cq-b").Available(fr); got != tc.wantAvailable {
+				t.Errorf("Unexpected available, want=%d, got=%d", tc.wantAvailable, got)
+			}
+		})
+	}
+}
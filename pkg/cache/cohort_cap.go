@@ -0,0 +1,60 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"slices"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/hierarchy"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+// MembersAffectedByCohortCap returns the sorted names of the ClusterQueues,
+// in the Cohort tree rooted at the given Cohort, which could no longer
+// achieve their nominal quota if the quota of the Cohort tree was capped to
+// newCap. For each flavor and resource in newCap, when the combined nominal
+// quota of the ClusterQueues exceeds the cap, the ClusterQueues with a
+// nominal quota for it are affected. It returns nil if the Cohort doesn't
+// exist or has a cycle.
+func (c *Cache) MembersAffectedByCohortCap(cohortName kueue.CohortReference, newCap resources.FlavorResourceQuantities) []kueue.ClusterQueueReference {
+	c.RLock()
+	defer c.RUnlock()
+
+	cohort := c.hm.Cohort(cohortName)
+	if cohort == nil || hierarchy.HasCycle(cohort) {
+		return nil
+	}
+	cqs := cohort.subtreeClusterQueues(nil)
+	var affected []kueue.ClusterQueueReference
+	for fr, limit := range newCap {
+		var nominal int64
+		for _, cq := range cqs {
+			nominal += cq.resourceNode.Quotas[fr].Nominal
+		}
+		if nominal <= limit {
+			continue
+		}
+		for _, cq := range cqs {
+			if cq.resourceNode.Quotas[fr].Nominal > 0 && !slices.Contains(affected, cq.Name) {
+				affected = append(affected, cq.Name)
+			}
+		}
+	}
+	slices.Sort(affected)
+	return affected
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestMembersAffectedByCohortCap(t *testing.T) {
+	defaultCPU := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	defaultGPU := resources.FlavorResource{Flavor: "default", Resource: "example.com/gpu"}
+	clusterQueues := []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq-a").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-b").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "6").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-c").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource("example.com/gpu", "2").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-other").
+			Cohort("other").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+			Obj(),
+	}
+
+	cases := map[string]struct {
+		cohort kueue.CohortReference
+		newCap resources.FlavorResourceQuantities
+		want   []kueue.ClusterQueueReference
+	}{
+		"cap covering the combined nominal quota": {
+			cohort: "cohort",
+			newCap: resources.FlavorResourceQuantities{defaultCPU: 10_000, defaultGPU: 2},
+		},
+		"cap below the combined nominal quota": {
+			cohort: "cohort",
+			newCap: resources.FlavorResourceQuantities{defaultCPU: 8_000},
+			want:   []kueue.ClusterQueueReference{"cq-a", "cq-b"},
+		},
+		"caps below the combined nominal quota of several resources": {
+			cohort: "cohort",
+			newCap: resources.FlavorResourceQuantities{defaultCPU: 9_000, defaultGPU: 1},
+			want:   []kueue.ClusterQueueReference{"cq-a", "cq-b", "cq-c"},
+		},
+		"cap on a resource without nominal quota": {
+			cohort: "cohort",
+			newCap: resources.FlavorResourceQuantities{{Flavor: "spot", Resource: corev1.ResourceCPU}: 0},
+		},
+		"cohort not found": {
+			cohort: "missing",
+			newCap: resources.FlavorResourceQuantities{defaultCPU: 0},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			for _, cq := range clusterQueues {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			got := cache.MembersAffectedByCohortCap(tc.cohort, tc.newCap)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected affected ClusterQueues (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// CohortDeficitPolicy defines whether workloads can be admitted in the
+// ClusterQueues of a Cohort tree whose usage exceeds its capacity, for
+// example after its quota was reduced.
+type CohortDeficitPolicy string
+
+const (
+	// CohortDeficitAllowAdmission keeps admitting the workloads which fit
+	// in the capacity available to their ClusterQueue. This is the
+	// default.
+	CohortDeficitAllowAdmission CohortDeficitPolicy = "AllowAdmission"
+	// CohortDeficitBlockAdmission blocks the admission of new workloads in
+	// the whole Cohort tree until the deficit clears.
+	CohortDeficitBlockAdmission CohortDeficitPolicy = "BlockAdmission"
+)
+
+// WithCohortDeficitPolicy sets whether workloads can be admitted in the
+// Cohort trees in deficit. By default, CohortDeficitAllowAdmission is used.
+func WithCohortDeficitPolicy(policy CohortDeficitPolicy) Option {
+	return func(o *options) {
+		o.cohortDeficitPolicy = policy
+	}
+}
+
+// InDeficit indicates whether the usage of the Cohort exceeds its
+// SubtreeQuota for any flavor and resource.
+func (c *CohortSnapshot) InDeficit() bool {
+	for fr, q := range c.ResourceNode.Usage {
+		if q > c.ResourceNode.SubtreeQuota[fr] {
+			return true
+		}
+	}
+	return false
+}
+
+// AdmissionBlockedByCohortDeficit indicates whether the admission of new
+// workloads in the ClusterQueue is blocked, because the CohortDeficitPolicy
+// is CohortDeficitBlockAdmission and the root of its Cohort tree is in
+// deficit.
+func (s *Snapshot) AdmissionBlockedByCohortDeficit(cq *ClusterQueueSnapshot) bool {
+	if s.CohortDeficitPolicy != CohortDeficitBlockAdmission || !cq.HasParent() {
+		return false
+	}
+	return cq.Parent().Root().InDeficit()
+}
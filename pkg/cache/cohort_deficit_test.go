@@ -0,0 +1,109 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestAdmissionBlockedByCohortDeficit(t *testing.T) {
+	cases := map[string]struct {
+		opts        []Option
+		usage       string
+		wantDeficit bool
+		wantBlocked map[kueue.ClusterQueueReference]bool
+	}{
+		"deficit with the default policy": {
+			usage:       "3",
+			wantDeficit: true,
+			wantBlocked: map[kueue.ClusterQueueReference]bool{
+				"borrower":   false,
+				"guaranteed": false,
+				"standalone": false,
+			},
+		},
+		"deficit blocking admission": {
+			opts:        []Option{WithCohortDeficitPolicy(CohortDeficitBlockAdmission)},
+			usage:       "3",
+			wantDeficit: true,
+			wantBlocked: map[kueue.ClusterQueueReference]bool{
+				"borrower":   true,
+				"guaranteed": true,
+				"standalone": false,
+			},
+		},
+		"no deficit": {
+			opts:  []Option{WithCohortDeficitPolicy(CohortDeficitBlockAdmission)},
+			usage: "2",
+			wantBlocked: map[kueue.ClusterQueueReference]bool{
+				"borrower":   false,
+				"guaranteed": false,
+				"standalone": false,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), tc.opts...)
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("borrower").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("guaranteed").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2", "", "0").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("standalone").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			// The usage can exceed the capacity of the Cohort, for example
+			// when its quota is reduced after the workload was admitted.
+			wl := utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, tc.usage).
+				ReserveQuota(utiltesting.MakeAdmission("borrower").Assignment(corev1.ResourceCPU, "default", tc.usage).Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, wl) {
+				t.Fatal("Failed adding workload")
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.Cohort("cohort").InDeficit(); got != tc.wantDeficit {
+				t.Errorf("Unexpected deficit, want=%v, got=%v", tc.wantDeficit, got)
+			}
+			for cqName, want := range tc.wantBlocked {
+				if got := snapshot.AdmissionBlockedByCohortDeficit(snapshot.ClusterQueue(cqName)); got != want {
+					t.Errorf("Unexpected admission blocked for %q, want=%v, got=%v", cqName, want, got)
+				}
+			}
+		})
+	}
+}
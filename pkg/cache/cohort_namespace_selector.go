@@ -0,0 +1,71 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// SetCohortNamespaceSelector sets the default NamespaceSelector of the
+// Cohort, which narrows the NamespaceSelectors of all the ClusterQueues in
+// its subtree. A nil selector removes the default. The default is kept
+// while the Cohort doesn't exist, so it can be set before the Cohort is
+// created.
+func (c *Cache) SetCohortNamespaceSelector(cohortName kueue.CohortReference, selector *metav1.LabelSelector) error {
+	c.Lock()
+	defer c.Unlock()
+	if selector == nil {
+		delete(c.cohortNamespaceSelectors, cohortName)
+		return nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return err
+	}
+	c.cohortNamespaceSelectors[cohortName] = s
+	return nil
+}
+
+// EffectiveNamespaceSelector returns the NamespaceSelector of the
+// ClusterQueue narrowed by the default NamespaceSelectors of its ancestor
+// Cohorts, so that a namespace is selected only if it is selected by the
+// ClusterQueue and by all the defaults.
+func (c *ClusterQueueSnapshot) EffectiveNamespaceSelector() labels.Selector {
+	selector := c.NamespaceSelector
+	if selector == nil {
+		selector = labels.Nothing()
+	}
+	for cohort := c.Parent(); cohort != nil; cohort = cohort.Parent() {
+		if cohort.NamespaceSelector != nil {
+			selector = intersectSelectors(selector, cohort.NamespaceSelector)
+		}
+	}
+	return selector
+}
+
+// intersectSelectors returns a selector matching the labels matched by
+// both selectors.
+func intersectSelectors(a, b labels.Selector) labels.Selector {
+	requirements, selectable := b.Requirements()
+	if !selectable {
+		return labels.Nothing()
+	}
+	return a.Add(requirements...)
+}
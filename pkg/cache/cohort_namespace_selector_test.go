@@ -0,0 +1,127 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestEffectiveNamespaceSelector(t *testing.T) {
+	namespaces := map[string]labels.Set{
+		"a-prod": {"team": "a", "env": "prod"},
+		"a-dev":  {"team": "a", "env": "dev"},
+		"b-prod": {"team": "b", "env": "prod"},
+	}
+	teamA := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	prod := &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+
+	type cohortDefault struct {
+		cohort   kueue.CohortReference
+		selector *metav1.LabelSelector
+	}
+	cases := map[string]struct {
+		cqSelector *metav1.LabelSelector
+		// defaults are set in order.
+		defaults []cohortDefault
+		want     []string
+	}{
+		"no defaults": {
+			cqSelector: teamA,
+			want:       []string{"a-dev", "a-prod"},
+		},
+		"parent default narrows the selector": {
+			cqSelector: teamA,
+			defaults:   []cohortDefault{{"child", prod}},
+			want:       []string{"a-prod"},
+		},
+		"root default narrows the selector": {
+			cqSelector: &metav1.LabelSelector{},
+			defaults:   []cohortDefault{{"root", prod}},
+			want:       []string{"a-prod", "b-prod"},
+		},
+		"defaults of all the ancestors apply": {
+			cqSelector: &metav1.LabelSelector{},
+			defaults: []cohortDefault{
+				{"child", teamA},
+				{"root", prod},
+			},
+			want: []string{"a-prod"},
+		},
+		"removed default": {
+			cqSelector: teamA,
+			defaults:   []cohortDefault{{"child", prod}, {"child", nil}},
+			want:       []string{"a-dev", "a-prod"},
+		},
+		"ClusterQueue selecting no namespaces": {
+			defaults: []cohortDefault{{"root", prod}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("root").Obj()); err != nil {
+				t.Fatalf("Failed adding Cohort: %v", err)
+			}
+			if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").
+				Cohort("child").
+				NamespaceSelector(tc.cqSelector).
+				Obj()); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			for _, d := range tc.defaults {
+				if err := cache.SetCohortNamespaceSelector(d.cohort, d.selector); err != nil {
+					t.Fatalf("Failed setting the Cohort NamespaceSelector: %v", err)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			selector := snapshot.ClusterQueue("cq").EffectiveNamespaceSelector()
+			var got []string
+			for _, ns := range []string{"a-dev", "a-prod", "b-prod"} {
+				if selector.Matches(namespaces[ns]) {
+					got = append(got, ns)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected selected namespaces (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetCohortNamespaceSelectorInvalid(t *testing.T) {
+	cache := New(utiltesting.NewFakeClient())
+	selector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "env",
+			Operator: "Unknown",
+		}},
+	}
+	if err := cache.SetCohortNamespaceSelector("cohort", selector); err == nil {
+		t.Error("Expected an error for an invalid selector")
+	}
+}
@@ -17,10 +17,19 @@ limitations under the License.
 package cache
 
 import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/hierarchy"
+	"sigs.k8s.io/kueue/pkg/resources"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 type CohortSnapshot struct {
@@ -30,6 +39,17 @@ type CohortSnapshot struct {
 	hierarchy.Cohort[*ClusterQueueSnapshot, *CohortSnapshot]
 
 	FairWeight resource.Quantity
+
+	// StoppedQuota is the quota which the stopped ClusterQueues of the
+	// Cohort contribute to its SubtreeQuota.
+	StoppedQuota resources.FlavorResourceQuantities
+
+	// NamespaceSelector is the default NamespaceSelector narrowing the
+	// ones of the ClusterQueues in the subtree. Nil means no default.
+	NamespaceSelector labels.Selector
+
+	// fairSharingUsageMode is the usage counting toward the share.
+	fairSharingUsageMode FairSharingUsage
 }
 
 func (c *CohortSnapshot) GetName() kueue.CohortReference {
@@ -68,8 +88,292 @@ func (c *CohortSnapshot) subtreeClusterQueueCount() int {
 	return count
 }
 
+// AllWorkloads returns the workloads of all the ClusterQueues in the
+// subtree starting at the Cohort, sorted by key. It expects that no cycles
+// exist in the Cohort graph.
+func (c *CohortSnapshot) AllWorkloads() []*workload.Info {
+	var keys []string
+	byKey := make(map[string]*workload.Info)
+	for _, cq := range c.SubtreeClusterQueues() {
+		keys = slices.AppendSeq(keys, maps.Keys(cq.Workloads))
+		maps.Copy(byKey, cq.Workloads)
+	}
+	slices.Sort(keys)
+	workloads := make([]*workload.Info, len(keys))
+	for i, k := range keys {
+		workloads[i] = byKey[k]
+	}
+	return workloads
+}
+
+// AllAdmissionChecks returns the names of the AdmissionChecks referenced by
+// the ClusterQueues in the subtree starting at the Cohort. It expects that
+// no cycles exist in the Cohort graph.
+func (c *CohortSnapshot) AllAdmissionChecks() sets.Set[string] {
+	checks := sets.New[string]()
+	for _, cq := range c.SubtreeClusterQueues() {
+		checks.Insert(slices.Collect(maps.Keys(cq.AdmissionChecks))...)
+	}
+	return checks
+}
+
+// ReservingNotAdmitted returns the number of workloads, in the
+// ClusterQueues in the subtree starting at the Cohort, which reserve quota
+// but are not admitted yet, as they wait for their admission checks. It
+// expects that no cycles exist in the Cohort graph.
+func (c *CohortSnapshot) ReservingNotAdmitted() int {
+	count := 0
+	for _, cq := range c.SubtreeClusterQueues() {
+		for _, wi := range cq.Workloads {
+			if !workload.IsAdmitted(wi.Obj) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// EffectiveRequestableResources returns the SubtreeQuota of the Cohort,
+// excluding the quota contributed by stopped ClusterQueues in its subtree.
+// Stopped ClusterQueues keep holding their quota, so it can't be borrowed
+// by their peers.
+func (c *CohortSnapshot) EffectiveRequestableResources() resources.FlavorResourceQuantities {
+	effective := maps.Clone(c.ResourceNode.SubtreeQuota)
+	if effective == nil {
+		effective = make(resources.FlavorResourceQuantities)
+	}
+	c.subtractStoppedQuota(effective)
+	return effective
+}
+
+func (c *CohortSnapshot) subtractStoppedQuota(frq resources.FlavorResourceQuantities) {
+	for fr, q := range c.StoppedQuota {
+		frq[fr] = max(0, frq[fr]-q)
+	}
+	for _, cohort := range c.ChildCohorts() {
+		cohort.subtractStoppedQuota(frq)
+	}
+}
+
+// CurrentlyLendable returns, for each flavor and resource, the capacity
+// lent to the Cohort by its members which is not borrowed yet. This is
+// the capacity a new borrower could take immediately, before applying
+// its BorrowingLimits.
+func (c *CohortSnapshot) CurrentlyLendable() resources.FlavorResourceQuantities {
+	lendable := make(resources.FlavorResourceQuantities, len(c.ResourceNode.SubtreeQuota))
+	for fr, q := range c.ResourceNode.SubtreeQuota {
+		lendable[fr] = max(0, q-c.ResourceNode.Usage[fr])
+	}
+	return lendable
+}
+
+// IncrementalUsage returns the usage of the Cohort after adding the
+// usage of the assignment, without modifying the snapshot. The assignment
+// is the usage which reaches the Cohort, which is the whole usage of a
+// workload admitted in a member ClusterQueue with no guaranteed quota.
+func (c *CohortSnapshot) IncrementalUsage(assignment resources.FlavorResourceQuantities) resources.FlavorResourceQuantities {
+	usage := maps.Clone(c.ResourceNode.Usage)
+	if usage == nil {
+		usage = make(resources.FlavorResourceQuantities, len(assignment))
+	}
+	for fr, q := range assignment {
+		usage[fr] += q
+	}
+	return usage
+}
+
+// ProjectedFree returns, for each flavor and resource, the capacity of
+// the Cohort which would remain free after admitting the pending
+// assignments: the CurrentlyLendable capacity minus the sum of the
+// assignments, clamped at zero. A zero quantity shows that the Cohort
+// would be exhausted for the flavor and resource.
+func (c *CohortSnapshot) ProjectedFree(pending []resources.FlavorResourceQuantities) resources.FlavorResourceQuantities {
+	free := c.CurrentlyLendable()
+	for _, assignment := range pending {
+		for fr, q := range assignment {
+			if _, found := free[fr]; found {
+				free[fr] = max(0, free[fr]-q)
+			}
+		}
+	}
+	return free
+}
+
+// MinPeersToReclaim returns the fewest ClusterQueues of the subtree of the
+// Cohort, other than forCQ, whose borrowing workloads must be reclaimed
+// for the required quota to be available to forCQ. A peer releases, for
+// each flavor and resource, the quota it borrows past its nominal quota.
+// Peers are picked greedily, by the share of the missing quota they
+// release, which is optimal for a single flavor and resource. It returns
+// 0 if the required quota is already available, and -1 if forCQ is not
+// in the subtree or reclaiming from all the peers is not enough.
+func (c *CohortSnapshot) MinPeersToReclaim(forCQ kueue.ClusterQueueReference, required resources.FlavorResourceQuantities) int {
+	cqs := c.SubtreeClusterQueues()
+	idx := slices.IndexFunc(cqs, func(cq *ClusterQueueSnapshot) bool {
+		return cq.Name == forCQ
+	})
+	if idx < 0 {
+		return -1
+	}
+	missing := make(resources.FlavorResourceQuantities)
+	for fr, q := range required {
+		if m := q - cqs[idx].Available(fr); m > 0 {
+			missing[fr] = m
+		}
+	}
+	if len(missing) == 0 {
+		return 0
+	}
+	peers := slices.Delete(cqs, idx, idx+1)
+	slices.SortFunc(peers, func(a, b *ClusterQueueSnapshot) int {
+		return strings.Compare(string(a.Name), string(b.Name))
+	})
+	remaining := maps.Clone(missing)
+	var count int
+	for len(remaining) > 0 {
+		best, bestCoverage := -1, 0.0
+		for i, peer := range peers {
+			var coverage float64
+			for fr, m := range remaining {
+				borrowed := max(0, peer.ResourceNode.Usage[fr]-peer.QuotaFor(fr).Nominal)
+				coverage += float64(min(borrowed, m)) / float64(missing[fr])
+			}
+			if coverage > bestCoverage {
+				best, bestCoverage = i, coverage
+			}
+		}
+		if best < 0 {
+			return -1
+		}
+		peer := peers[best]
+		for fr, m := range remaining {
+			m -= max(0, peer.ResourceNode.Usage[fr]-peer.QuotaFor(fr).Nominal)
+			if m > 0 {
+				remaining[fr] = m
+			} else {
+				delete(remaining, fr)
+			}
+		}
+		peers = slices.Delete(peers, best, best+1)
+		count++
+	}
+	return count
+}
+
+// BorrowingByMember returns, for each ClusterQueue of the subtree of the
+// Cohort which borrows, the usage it has beyond its nominal quota, by
+// flavor and resource. The quantities add up to Borrowed.
+func (c *CohortSnapshot) BorrowingByMember() map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities {
+	borrowing := make(map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities)
+	for _, cq := range c.SubtreeClusterQueues() {
+		for fr, q := range cq.ResourceNode.Usage {
+			if b := q - cq.QuotaFor(fr).Nominal; b > 0 {
+				if borrowing[cq.Name] == nil {
+					borrowing[cq.Name] = make(resources.FlavorResourceQuantities)
+				}
+				borrowing[cq.Name][fr] = b
+			}
+		}
+	}
+	return borrowing
+}
+
+// Borrowed returns, by flavor and resource, the usage of the ClusterQueues
+// of the subtree of the Cohort beyond their nominal quota.
+func (c *CohortSnapshot) Borrowed() resources.FlavorResourceQuantities {
+	borrowed := make(resources.FlavorResourceQuantities)
+	for _, frq := range c.BorrowingByMember() {
+		for fr, q := range frq {
+			borrowed[fr] += q
+		}
+	}
+	return borrowed
+}
+
+// UsageExcluding returns the usage of the Cohort, minus the contribution
+// of the ClusterQueue, as if it left the Cohort tree. The contribution
+// of a ClusterQueue is its usage past its guaranteed quota, reduced, for
+// each intermediate Cohort, by the guaranteed quota of that Cohort. It
+// returns the usage of the Cohort if the ClusterQueue is not in its
+// subtree.
+func (c *CohortSnapshot) UsageExcluding(cqName kueue.ClusterQueueReference) resources.FlavorResourceQuantities {
+	usage := maps.Clone(c.ResourceNode.Usage)
+	if usage == nil {
+		usage = make(resources.FlavorResourceQuantities)
+	}
+	cqs := c.SubtreeClusterQueues()
+	idx := slices.IndexFunc(cqs, func(cq *ClusterQueueSnapshot) bool {
+		return cq.Name == cqName
+	})
+	if idx < 0 {
+		return usage
+	}
+	cq := cqs[idx]
+	contribution := make(resources.FlavorResourceQuantities, len(cq.ResourceNode.Usage))
+	for fr, q := range cq.ResourceNode.Usage {
+		contribution[fr] = max(0, q-cq.ResourceNode.guaranteedQuota(fr))
+	}
+	for node := cq.Parent(); node != c; node = node.Parent() {
+		for fr, q := range contribution {
+			u, g := node.ResourceNode.Usage[fr], node.ResourceNode.guaranteedQuota(fr)
+			contribution[fr] = max(0, u-g) - max(0, u-q-g)
+		}
+	}
+	for fr, q := range contribution {
+		usage[fr] -= q
+	}
+	return usage
+}
+
+// UsageImbalance returns the coefficient of variation of the weighted
+// usage shares of the members of the Cohort, its ClusterQueues and child
+// Cohorts. The share of a member is the highest ratio, among the flavors
+// and resources, of its usage to the SubtreeQuota of the Cohort, divided
+// by its FairSharing weight. It is 0 when the usage is perfectly
+// balanced, and grows as the usage concentrates on fewer members. Members
+// with a zero weight are ignored, and it returns 0 when there are fewer
+// than two members or no usage.
+func (c *CohortSnapshot) UsageImbalance() float64 {
+	var shares []float64
+	addShare := func(weight *resource.Quantity, usage resources.FlavorResourceQuantities) {
+		if weight.IsZero() {
+			return
+		}
+		var share float64
+		for fr, q := range c.ResourceNode.SubtreeQuota {
+			if q > 0 {
+				share = max(share, float64(usage[fr])/float64(q))
+			}
+		}
+		shares = append(shares, share*1000/float64(weight.MilliValue()))
+	}
+	for _, cq := range c.ChildCQs() {
+		addShare(&cq.FairWeight, cq.ResourceNode.Usage)
+	}
+	for _, cohort := range c.ChildCohorts() {
+		addShare(&cohort.FairWeight, cohort.ResourceNode.Usage)
+	}
+	if len(shares) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, s := range shares {
+		mean += s
+	}
+	mean /= float64(len(shares))
+	if mean == 0 {
+		return 0
+	}
+	var variance float64
+	for _, s := range shares {
+		variance += (s - mean) * (s - mean)
+	}
+	variance /= float64(len(shares))
+	return math.Sqrt(variance) / mean
+}
+
 func (c *CohortSnapshot) DominantResourceShare() int {
-	share, _ := dominantResourceShare(c, nil)
+	share, _ := dominantResourceShare(c, nil, c.fairSharingUsageMode)
 	return share
 }
 
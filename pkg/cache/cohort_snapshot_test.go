@@ -0,0 +1,896 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueuealpha "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestEffectiveRequestableResources(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	makeCQ := func(name string, cohort kueue.CohortReference, nominal string) *utiltesting.ClusterQueueWrapper {
+		return utiltesting.MakeClusterQueue(name).
+			Cohort(cohort).
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, nominal).Obj())
+	}
+
+	cases := map[string]struct {
+		cohorts       []*kueuealpha.Cohort
+		clusterQueues []*kueue.ClusterQueue
+		cohort        kueue.CohortReference
+		want          resources.FlavorResourceQuantities
+	}{
+		"no stopped members": {
+			clusterQueues: []*kueue.ClusterQueue{
+				makeCQ("a", "cohort", "10").Obj(),
+				makeCQ("b", "cohort", "6").Obj(),
+			},
+			cohort: "cohort",
+			want:   resources.FlavorResourceQuantities{fr: 16_000},
+		},
+		"stopped member": {
+			clusterQueues: []*kueue.ClusterQueue{
+				makeCQ("a", "cohort", "10").Obj(),
+				makeCQ("b", "cohort", "6").Obj(),
+				makeCQ("c", "cohort", "4").StopPolicy(kueue.Hold).Obj(),
+			},
+			cohort: "cohort",
+			want:   resources.FlavorResourceQuantities{fr: 16_000},
+		},
+		"stopped member with lending limit": {
+			clusterQueues: []*kueue.ClusterQueue{
+				makeCQ("a", "cohort", "10").Obj(),
+				utiltesting.MakeClusterQueue("c").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4", "", "1").Obj()).
+					StopPolicy(kueue.HoldAndDrain).
+					Obj(),
+			},
+			cohort: "cohort",
+			want:   resources.FlavorResourceQuantities{fr: 10_000},
+		},
+		"stopped member in child cohort": {
+			cohorts: []*kueuealpha.Cohort{
+				utiltesting.MakeCohort("child").Parent("root").Obj(),
+			},
+			clusterQueues: []*kueue.ClusterQueue{
+				makeCQ("a", "root", "10").Obj(),
+				makeCQ("b", "child", "6").Obj(),
+				makeCQ("c", "child", "4").StopPolicy(kueue.Hold).Obj(),
+			},
+			cohort: "root",
+			want:   resources.FlavorResourceQuantities{fr: 16_000},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cohort := range tc.cohorts {
+				if err := cache.AddOrUpdateCohort(cohort); err != nil {
+					t.Fatalf("Failed adding Cohort: %v", err)
+				}
+			}
+			for _, cq := range tc.clusterQueues {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			got := snapshot.Cohort(tc.cohort).EffectiveRequestableResources()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected effective requestable resources (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCurrentlyLendable(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		usage map[kueue.ClusterQueueReference]string
+		want  resources.FlavorResourceQuantities
+	}{
+		"nothing borrowed": {
+			want: resources.FlavorResourceQuantities{fr: 8_000},
+		},
+		"part of lendable borrowed": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+			},
+			want: resources.FlavorResourceQuantities{fr: 3_000},
+		},
+		"lender using part of its lendable quota": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+				"lender":   "5",
+			},
+			want: resources.FlavorResourceQuantities{fr: 2_000},
+		},
+		"all lendable borrowed": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "8",
+			},
+			want: resources.FlavorResourceQuantities{fr: 0},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10", "", "6").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("borrower").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for cqName, cpu := range tc.usage {
+				wl := utiltesting.MakeWorkload(string(cqName), "ns").
+					Request(corev1.ResourceCPU, cpu).
+					ReserveQuota(utiltesting.MakeAdmission(string(cqName)).Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot.Cohort("cohort").CurrentlyLendable()); diff != "" {
+				t.Errorf("Unexpected currently lendable resources (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAllWorkloads(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("cohort").Obj()); err != nil {
+		t.Fatalf("Failed adding Cohort: %v", err)
+	}
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10", "", "6").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("borrower").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("nested").
+			Cohort("child").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("standalone").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for name, cqName := range map[string]string{
+		"lender-a":   "lender",
+		"lender-b":   "lender",
+		"borrower-a": "borrower",
+		"nested-a":   "nested",
+		"standalone": "standalone",
+	} {
+		wl := utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, "1").
+			ReserveQuota(utiltesting.MakeAdmission(cqName).Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+			Obj()
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[kueue.CohortReference][]string{
+		"cohort": {"ns/borrower-a", "ns/lender-a", "ns/lender-b", "ns/nested-a"},
+		"child":  {"ns/nested-a"},
+	}
+	for cohort, want := range cases {
+		var got []string
+		for _, wi := range snapshot.Cohort(cohort).AllWorkloads() {
+			got = append(got, workload.Key(wi.Obj))
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Unexpected workloads in Cohort %q (-want,+got):\n%s", cohort, diff)
+		}
+	}
+}
+
+func TestAllAdmissionChecks(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, ac := range []string{"check-a", "check-b", "check-c", "check-d"} {
+		cache.AddOrUpdateAdmissionCheck(log, utiltesting.MakeAdmissionCheck(ac).ControllerName("controller").Active(metav1.ConditionTrue).Obj())
+	}
+	if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("cohort").Obj()); err != nil {
+		t.Fatalf("Failed adding Cohort: %v", err)
+	}
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq-a").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			AdmissionChecks("check-a", "check-b").
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-b").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			AdmissionChecks("check-b").
+			Obj(),
+		utiltesting.MakeClusterQueue("nested").
+			Cohort("child").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			AdmissionChecks("check-c").
+			Obj(),
+		utiltesting.MakeClusterQueue("standalone").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			AdmissionChecks("check-d").
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[kueue.CohortReference]sets.Set[string]{
+		"cohort": sets.New("check-a", "check-b", "check-c"),
+		"child":  sets.New("check-c"),
+	}
+	for cohort, want := range cases {
+		if diff := cmp.Diff(want, snapshot.Cohort(cohort).AllAdmissionChecks()); diff != "" {
+			t.Errorf("Unexpected admission checks in Cohort %q (-want,+got):\n%s", cohort, diff)
+		}
+	}
+}
+
+func TestReservingNotAdmitted(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("cohort").Obj()); err != nil {
+		t.Fatalf("Failed adding Cohort: %v", err)
+	}
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq-a").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-b").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("nested").
+			Cohort("child").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("standalone").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for _, w := range []struct {
+		name     string
+		cq       string
+		admitted bool
+	}{
+		{name: "a-admitted", cq: "cq-a", admitted: true},
+		{name: "a-reserving", cq: "cq-a"},
+		{name: "b-admitted", cq: "cq-b", admitted: true},
+		{name: "nested-reserving-1", cq: "nested"},
+		{name: "nested-reserving-2", cq: "nested"},
+		{name: "standalone-reserving", cq: "standalone"},
+	} {
+		wl := utiltesting.MakeWorkload(w.name, "ns").
+			Request(corev1.ResourceCPU, "1").
+			ReserveQuota(utiltesting.MakeAdmission(w.cq).Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+			Admitted(w.admitted).
+			Obj()
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cases := map[kueue.CohortReference]int{
+		"cohort": 3,
+		"child":  2,
+	}
+	for cohort, want := range cases {
+		if got := snapshot.Cohort(cohort).ReservingNotAdmitted(); got != want {
+			t.Errorf("Unexpected reserving not admitted workloads in Cohort %q, want=%d, got=%d", cohort, want, got)
+		}
+	}
+}
+
+func TestIncrementalUsage(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "10").
+				Resource(corev1.ResourceMemory, "10Gi").
+				Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("borrower").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "2").
+				Resource(corev1.ResourceMemory, "2Gi").
+				Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	running := utiltesting.MakeWorkload("running", "ns").
+		Request(corev1.ResourceCPU, "3").
+		ReserveQuota(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "default", "3").Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, running) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	wl := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").
+		Request(corev1.ResourceCPU, "4").
+		Request(corev1.ResourceMemory, "1Gi").
+		ReserveQuota(utiltesting.MakeAdmission("borrower").
+			Assignment(corev1.ResourceCPU, "default", "4").
+			Assignment(corev1.ResourceMemory, "default", "1Gi").
+			Obj()).
+		Obj())
+	cohort := snapshot.Cohort("cohort")
+	before := maps.Clone(cohort.ResourceNode.Usage)
+	got := cohort.IncrementalUsage(wl.FlavorResourceUsage())
+	if diff := cmp.Diff(before, cohort.ResourceNode.Usage); diff != "" {
+		t.Errorf("Unexpected change of the Cohort usage (-want,+got):\n%s", diff)
+	}
+
+	snapshot.AddWorkload(wl)
+	if diff := cmp.Diff(cohort.ResourceNode.Usage, got); diff != "" {
+		t.Errorf("Unexpected incremental usage compared to adding the workload (-want,+got):\n%s", diff)
+	}
+	want := resources.FlavorResourceQuantities{
+		{Flavor: "default", Resource: corev1.ResourceCPU}:    7_000,
+		{Flavor: "default", Resource: corev1.ResourceMemory}: utiltesting.Gi,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected incremental usage (-want,+got):\n%s", diff)
+	}
+}
+
+func TestProjectedFree(t *testing.T) {
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	memory := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceMemory}
+	cases := map[string]struct {
+		pending []resources.FlavorResourceQuantities
+		want    resources.FlavorResourceQuantities
+	}{
+		"no pending assignments": {
+			want: resources.FlavorResourceQuantities{cpu: 9_000, memory: 12 * utiltesting.Gi},
+		},
+		"several pending assignments": {
+			pending: []resources.FlavorResourceQuantities{
+				{cpu: 2_000, memory: utiltesting.Gi},
+				{cpu: 3_000},
+				{memory: 4 * utiltesting.Gi},
+			},
+			want: resources.FlavorResourceQuantities{cpu: 4_000, memory: 7 * utiltesting.Gi},
+		},
+		"pending assignments exhausting the Cohort": {
+			pending: []resources.FlavorResourceQuantities{
+				{cpu: 5_000},
+				{cpu: 6_000, memory: utiltesting.Gi},
+			},
+			want: resources.FlavorResourceQuantities{cpu: 0, memory: 11 * utiltesting.Gi},
+		},
+		"pending assignment for a flavor not in the Cohort": {
+			pending: []resources.FlavorResourceQuantities{
+				{{Flavor: "other", Resource: corev1.ResourceCPU}: 1_000},
+			},
+			want: resources.FlavorResourceQuantities{cpu: 9_000, memory: 12 * utiltesting.Gi},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq-a").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "10").
+						Resource(corev1.ResourceMemory, "10Gi").
+						Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("cq-b").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "2").
+						Resource(corev1.ResourceMemory, "2Gi").
+						Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			running := utiltesting.MakeWorkload("running", "ns").
+				Request(corev1.ResourceCPU, "3").
+				ReserveQuota(utiltesting.MakeAdmission("cq-a").Assignment(corev1.ResourceCPU, "default", "3").Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, running) {
+				t.Fatal("Failed adding workload")
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot.Cohort("cohort").ProjectedFree(tc.pending)); diff != "" {
+				t.Errorf("Unexpected projected free capacity (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinPeersToReclaim(t *testing.T) {
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	memory := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceMemory}
+	cases := map[string]struct {
+		forCQ    kueue.ClusterQueueReference
+		required resources.FlavorResourceQuantities
+		want     int
+	}{
+		"quota available": {
+			forCQ:    "lend-a",
+			required: resources.FlavorResourceQuantities{cpu: 4_000},
+			want:     0,
+		},
+		"single peer": {
+			forCQ:    "lend-a",
+			required: resources.FlavorResourceQuantities{cpu: 8_000},
+			want:     1,
+		},
+		"two peers": {
+			forCQ:    "lend-a",
+			required: resources.FlavorResourceQuantities{cpu: 11_000},
+			want:     2,
+		},
+		"all the peers": {
+			forCQ:    "lend-a",
+			required: resources.FlavorResourceQuantities{cpu: 14_000},
+			want:     3,
+		},
+		"reclaiming from all the peers is not enough": {
+			forCQ:    "lend-a",
+			required: resources.FlavorResourceQuantities{cpu: 15_000},
+			want:     -1,
+		},
+		"resource not borrowed by any peer": {
+			forCQ:    "lend-a",
+			required: resources.FlavorResourceQuantities{memory: utiltesting.Gi},
+			want:     -1,
+		},
+		"clusterQueue not in the Cohort": {
+			forCQ:    "other",
+			required: resources.FlavorResourceQuantities{cpu: 1_000},
+			want:     -1,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("lend-a").
+					Cohort("lend").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "14", "", "14").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("lend-b").
+					Cohort("lend").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "0").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("lend-c").
+					Cohort("lend").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "0").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("lend-d").
+					Cohort("lend").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2", "", "2").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for _, wl := range []*kueue.Workload{
+				utiltesting.MakeWorkload("lend-b-1", "ns").
+					Request(corev1.ResourceCPU, "3").
+					ReserveQuota(utiltesting.MakeAdmission("lend-b").Assignment(corev1.ResourceCPU, "default", "3").Obj()).
+					Obj(),
+				utiltesting.MakeWorkload("lend-c-1", "ns").
+					Request(corev1.ResourceCPU, "5").
+					ReserveQuota(utiltesting.MakeAdmission("lend-c").Assignment(corev1.ResourceCPU, "default", "5").Obj()).
+					Obj(),
+				utiltesting.MakeWorkload("lend-d-1", "ns").
+					Request(corev1.ResourceCPU, "4").
+					ReserveQuota(utiltesting.MakeAdmission("lend-d").Assignment(corev1.ResourceCPU, "default", "4").Obj()).
+					Obj(),
+			} {
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %s", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.Cohort("lend").MinPeersToReclaim(tc.forCQ, tc.required); got != tc.want {
+				t.Errorf("Unexpected number of peers, want=%d, got=%d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBorrowingByMember(t *testing.T) {
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	gpu := resources.FlavorResource{Flavor: "default", Resource: "example.com/gpu"}
+	makeWorkload := func(name, cq, cpuQuantity, gpuQuantity string) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, cpuQuantity).
+			Request("example.com/gpu", gpuQuantity).
+			ReserveQuota(utiltesting.MakeAdmission(cq).
+				Assignment(corev1.ResourceCPU, "default", cpuQuantity).
+				Assignment("example.com/gpu", "default", gpuQuantity).
+				Obj()).
+			Obj()
+	}
+
+	cases := map[string]struct {
+		workloads []*kueue.Workload
+		want      map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities
+	}{
+		"no borrowing": {
+			workloads: []*kueue.Workload{
+				makeWorkload("lender", "lender", "6", "2"),
+				makeWorkload("borrower", "borrower", "2", "0"),
+			},
+			want: map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities{},
+		},
+		"single borrower": {
+			workloads: []*kueue.Workload{
+				makeWorkload("borrower", "borrower", "5", "1"),
+			},
+			want: map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities{
+				"borrower": {cpu: 3_000, gpu: 1},
+			},
+		},
+		"borrowers in nested Cohorts": {
+			workloads: []*kueue.Workload{
+				makeWorkload("lender", "lender", "4", "0"),
+				makeWorkload("borrower", "borrower", "3", "0"),
+				makeWorkload("nested", "nested", "1", "2"),
+			},
+			want: map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities{
+				"borrower": {cpu: 1_000},
+				"nested":   {cpu: 1_000, gpu: 2},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("borrowing").Obj()); err != nil {
+				t.Fatalf("Failed adding Cohort: %v", err)
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("borrowing").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "10").
+						Resource("example.com/gpu", "4").
+						Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("borrower").
+					Cohort("borrowing").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "2").
+						Resource("example.com/gpu", "0").
+						Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("nested").
+					Cohort("child").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+						Resource(corev1.ResourceCPU, "0").
+						Resource("example.com/gpu", "0").
+						Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for _, wl := range tc.workloads {
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %s", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cohort := snapshot.Cohort("borrowing")
+			got := cohort.BorrowingByMember()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected borrowing by member (-want,+got):\n%s", diff)
+			}
+			total := make(resources.FlavorResourceQuantities)
+			for _, frq := range got {
+				for fr, q := range frq {
+					total[fr] += q
+				}
+			}
+			if diff := cmp.Diff(cohort.Borrowed(), total); diff != "" {
+				t.Errorf("Borrowing by member doesn't add up to the borrowed quota (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUsageImbalance(t *testing.T) {
+	cases := map[string]struct {
+		weights map[kueue.ClusterQueueReference]string
+		usage   map[kueue.ClusterQueueReference]string
+		want    float64
+	}{
+		"no usage": {},
+		"perfectly balanced": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"a": "2",
+				"b": "2",
+				"c": "2",
+			},
+		},
+		"balanced by weight": {
+			weights: map[kueue.ClusterQueueReference]string{
+				"a": "2",
+			},
+			usage: map[kueue.ClusterQueueReference]string{
+				"a": "4",
+				"b": "2",
+				"c": "2",
+			},
+		},
+		"highly skewed": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"a": "8",
+			},
+			want: math.Sqrt2,
+		},
+		"members with zero weight are ignored": {
+			weights: map[kueue.ClusterQueueReference]string{
+				"a": "0",
+			},
+			usage: map[kueue.ClusterQueueReference]string{
+				"a": "8",
+				"b": "2",
+				"c": "2",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cqName := range []kueue.ClusterQueueReference{"a", "b", "c"} {
+				cq := utiltesting.MakeClusterQueue(string(cqName)).
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj())
+				if weight, found := tc.weights[cqName]; found {
+					cq = cq.FairWeight(resource.MustParse(weight))
+				}
+				if err := cache.AddClusterQueue(ctx, cq.Obj()); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for cqName, cpu := range tc.usage {
+				wl := utiltesting.MakeWorkload(string(cqName), "ns").
+					Request(corev1.ResourceCPU, cpu).
+					ReserveQuota(utiltesting.MakeAdmission(string(cqName)).Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			got := snapshot.Cohort("cohort").UsageImbalance()
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("Unexpected usage imbalance (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUsageExcluding(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		usage     map[kueue.ClusterQueueReference]string
+		cohort    kueue.CohortReference
+		excluding kueue.ClusterQueueReference
+		want      resources.FlavorResourceQuantities
+	}{
+		"no usage": {
+			cohort:    "cohort",
+			excluding: "borrower",
+			want:      resources.FlavorResourceQuantities{},
+		},
+		"excluding the borrower": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+				"lender":   "5",
+			},
+			cohort:    "cohort",
+			excluding: "borrower",
+			want:      resources.FlavorResourceQuantities{fr: 1_000},
+		},
+		"excluding the lender": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+				"lender":   "5",
+			},
+			cohort:    "cohort",
+			excluding: "lender",
+			want:      resources.FlavorResourceQuantities{fr: 5_000},
+		},
+		"excluding a ClusterQueue within its guaranteed quota": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+				"lender":   "3",
+			},
+			cohort:    "cohort",
+			excluding: "lender",
+			want:      resources.FlavorResourceQuantities{fr: 5_000},
+		},
+		"excluding a ClusterQueue of a child Cohort": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+				"lender":   "5",
+				"nested":   "4",
+			},
+			cohort:    "cohort",
+			excluding: "nested",
+			want:      resources.FlavorResourceQuantities{fr: 6_000},
+		},
+		"excluding a ClusterQueue outside of the Cohort": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"borrower": "5",
+				"nested":   "4",
+			},
+			cohort:    "child",
+			excluding: "borrower",
+			want:      resources.FlavorResourceQuantities{fr: 4_000},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("cohort").Obj()); err != nil {
+				t.Fatalf("Failed adding Cohort: %v", err)
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10", "", "6").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("borrower").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("nested").
+					Cohort("child").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "1").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for cqName, cpu := range tc.usage {
+				wl := utiltesting.MakeWorkload(string(cqName), "ns").
+					Request(corev1.ResourceCPU, cpu).
+					ReserveQuota(utiltesting.MakeAdmission(string(cqName)).Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot.Cohort(tc.cohort).UsageExcluding(tc.excluding)); diff != "" {
+				t.Errorf("Unexpected usage (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/hierarchy"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+var errCohortTreeInconsistent = errors.New("cohort tree is inconsistent")
+
+// ValidateCohortTree checks, for debugging, that the SubtreeQuota and
+// Usage of every Cohort in the tree rooted at the given Cohort match the
+// nominal quota of the Cohort plus the contributions of its children, as
+// accumulated by updateCohortTreeResources and adjusted by the
+// CohortAggregation, and that the SubtreeQuota of
+// every ClusterQueue matches its nominal quota. It returns an error
+// describing the first inconsistency found.
+func (c *Cache) ValidateCohortTree(root kueue.CohortReference) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	cohort := c.hm.Cohort(root)
+	if cohort == nil {
+		return ErrCohortNotFound
+	}
+	if hierarchy.HasCycle(cohort) {
+		return ErrCohortHasCycle
+	}
+	return validateCohortResourceNode(cohort)
+}
+
+func validateCohortResourceNode(cohort *cohort) error {
+	subtreeQuota := make(resources.FlavorResourceQuantities, len(cohort.resourceNode.SubtreeQuota))
+	usage := make(resources.FlavorResourceQuantities, len(cohort.resourceNode.Usage))
+	for fr, quota := range cohort.resourceNode.Quotas {
+		subtreeQuota[fr] = quota.Nominal
+	}
+	accumulate := func(child hierarchicalResourceNode) {
+		node := child.getResourceNode()
+		for fr, q := range node.SubtreeQuota {
+			subtreeQuota[fr] += q - node.guaranteedQuota(fr)
+		}
+		for fr, q := range node.Usage {
+			usage[fr] += max(0, q-node.guaranteedQuota(fr))
+		}
+	}
+	for _, child := range cohort.ChildCohorts() {
+		if err := validateCohortResourceNode(child); err != nil {
+			return err
+		}
+		accumulate(child)
+	}
+	for _, child := range cohort.ChildCQs() {
+		nominal := make(resources.FlavorResourceQuantities, len(child.resourceNode.Quotas))
+		for fr, quota := range child.resourceNode.Quotas {
+			nominal[fr] = quota.Nominal
+		}
+		if err := compareQuantities("ClusterQueue", string(child.Name), "SubtreeQuota", child.resourceNode.SubtreeQuota, nominal); err != nil {
+			return err
+		}
+		accumulate(child)
+	}
+	cohort.aggregateSubtreeQuota(subtreeQuota)
+	if err := compareQuantities("Cohort", string(cohort.Name), "SubtreeQuota", cohort.resourceNode.SubtreeQuota, subtreeQuota); err != nil {
+		return err
+	}
+	return compareQuantities("Cohort", string(cohort.Name), "Usage", cohort.resourceNode.Usage, usage)
+}
+
+// compareQuantities returns an error for the first flavor and resource,
+// in order, whose quantity doesn't match the expected one. Missing
+// entries count as zero.
+func compareQuantities(kind, name, field string, got, want resources.FlavorResourceQuantities) error {
+	keys := slices.Collect(maps.Keys(got))
+	for fr := range want {
+		if _, found := got[fr]; !found {
+			keys = append(keys, fr)
+		}
+	}
+	slices.SortFunc(keys, func(a, b resources.FlavorResource) int {
+		return cmp.Or(cmp.Compare(a.Flavor, b.Flavor), cmp.Compare(a.Resource, b.Resource))
+	})
+	for _, fr := range keys {
+		if got[fr] != want[fr] {
+			return fmt.Errorf("%w: %s %q has a %s of %d for %v, expected %d", errCohortTreeInconsistent, kind, name, field, got[fr], fr, want[fr])
+		}
+	}
+	return nil
+}
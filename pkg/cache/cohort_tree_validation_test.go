@@ -0,0 +1,116 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestValidateCohortTree(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		root    kueue.CohortReference
+		corrupt func(*Cache)
+		wantErr error
+	}{
+		"consistent tree": {
+			root: "root",
+		},
+		"consistent subtree": {
+			root: "child",
+		},
+		"missing cohort": {
+			root:    "missing",
+			wantErr: ErrCohortNotFound,
+		},
+		"inconsistent SubtreeQuota of a child cohort": {
+			root: "root",
+			corrupt: func(c *Cache) {
+				c.hm.Cohort("child").resourceNode.SubtreeQuota[fr] += 1_000
+			},
+			wantErr: errCohortTreeInconsistent,
+		},
+		"inconsistent Usage of the root": {
+			root: "root",
+			corrupt: func(c *Cache) {
+				c.hm.Cohort("root").resourceNode.Usage[fr] = 0
+			},
+			wantErr: errCohortTreeInconsistent,
+		},
+		"inconsistent SubtreeQuota of a ClusterQueue": {
+			root: "root",
+			corrupt: func(c *Cache) {
+				c.hm.ClusterQueue("nested").resourceNode.SubtreeQuota[fr] = 0
+			},
+			wantErr: errCohortTreeInconsistent,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cohort := range []*utiltesting.CohortWrapper{
+				utiltesting.MakeCohort("root").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()),
+				utiltesting.MakeCohort("child").
+					Parent("root").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4", "", "3").Obj()),
+			} {
+				if err := cache.AddOrUpdateCohort(cohort.Obj()); err != nil {
+					t.Fatalf("Failed adding Cohort: %v", err)
+				}
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("direct").
+					Cohort("root").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "6").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("nested").
+					Cohort("child").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10", "", "5").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for cqName, cpu := range map[string]string{"direct": "8", "nested": "12"} {
+				wl := utiltesting.MakeWorkload(cqName, "ns").
+					Request(corev1.ResourceCPU, cpu).
+					ReserveQuota(utiltesting.MakeAdmission(cqName).Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			if tc.corrupt != nil {
+				tc.corrupt(cache)
+			}
+			if err := cache.ValidateCohortTree(tc.root); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Unexpected error, want=%v, got=%v", tc.wantErr, err)
+			}
+		})
+	}
+}
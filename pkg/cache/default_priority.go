@@ -0,0 +1,59 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/util/priority"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// SetDefaultPriority sets the priority used by the scheduler and the
+// preemption, when ranking the workloads of the ClusterQueue, for the
+// workloads without a priority class. A nil priority resets the ClusterQueue to the priority of the
+// workloads, which is the global default for the workloads without a
+// priority class.
+func (c *Cache) SetDefaultPriority(cqName kueue.ClusterQueueReference, p *int32) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.defaultPriority = p
+	return nil
+}
+
+// WorkloadPriority returns the priority of the workload, using the
+// DefaultPriority of its ClusterQueue if the workload doesn't have a
+// priority class.
+func (s *Snapshot) WorkloadPriority(wi *workload.Info) int32 {
+	if cq := s.ClusterQueue(wi.ClusterQueue); cq != nil {
+		return cq.WorkloadPriority(wi)
+	}
+	return priority.Priority(wi.Obj)
+}
+
+// WorkloadPriority returns the priority of the workload of the
+// ClusterQueue, using its DefaultPriority if the workload doesn't have a
+// priority class.
+func (c *ClusterQueueSnapshot) WorkloadPriority(wi *workload.Info) int32 {
+	if wi.Obj.Spec.PriorityClassName == "" && c.DefaultPriority != nil {
+		return *c.DefaultPriority
+	}
+	return priority.Priority(wi.Obj)
+}
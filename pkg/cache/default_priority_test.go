@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestWorkloadPriority(t *testing.T) {
+	cases := map[string]struct {
+		defaultPriority *int32
+		wl              *kueue.Workload
+		want            int32
+	}{
+		"unprioritized without default": {
+			wl:   utiltesting.MakeWorkload("wl", "ns").ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).Obj(),
+			want: 0,
+		},
+		"unprioritized with default": {
+			defaultPriority: ptr.To[int32](100),
+			wl:              utiltesting.MakeWorkload("wl", "ns").ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).Obj(),
+			want:            100,
+		},
+		"priority class takes precedence over the default": {
+			defaultPriority: ptr.To[int32](100),
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				PriorityClass("low").
+				Priority(-10).
+				ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).
+				Obj(),
+			want: -10,
+		},
+		"workload of an unknown ClusterQueue": {
+			defaultPriority: ptr.To[int32](100),
+			wl:              utiltesting.MakeWorkload("wl", "ns").ReserveQuota(utiltesting.MakeAdmission("other").Obj()).Obj(),
+			want:            0,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if err := cache.SetDefaultPriority("cq", tc.defaultPriority); err != nil {
+				t.Fatalf("Failed setting the default priority: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.WorkloadPriority(workload.NewInfo(tc.wl)); got != tc.want {
+				t.Errorf("Unexpected priority, want=%d, got=%d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSetDefaultPriorityMissingClusterQueue(t *testing.T) {
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.SetDefaultPriority("missing", ptr.To[int32](1)); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// SetDefaultTolerations validates the default tolerations configured for
+// the ClusterQueue. If any toleration is invalid, the ClusterQueue becomes
+// pending until valid tolerations are set.
+func (c *Cache) SetDefaultTolerations(log logr.Logger, cqName kueue.ClusterQueueReference, tolerations []corev1.Toleration) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.invalidDefaultTolerations = nil
+	for i := range tolerations {
+		if errs := validateToleration(&tolerations[i]); len(errs) > 0 {
+			cq.invalidDefaultTolerations = append(cq.invalidDefaultTolerations, fmt.Sprintf("toleration %d: %s", i, strings.Join(errs, ", ")))
+		}
+	}
+	cq.updateQueueStatus(log)
+	return nil
+}
+
+// validateToleration returns the errors found in the syntax of the
+// toleration.
+func validateToleration(t *corev1.Toleration) []string {
+	var errs []string
+	if len(t.Key) > 0 {
+		errs = append(errs, validation.IsQualifiedName(t.Key)...)
+	}
+	switch t.Operator {
+	case corev1.TolerationOpEqual, "":
+		if len(t.Key) == 0 {
+			errs = append(errs, "operator must be Exists when the key is empty")
+		}
+		errs = append(errs, validation.IsValidLabelValue(t.Value)...)
+	case corev1.TolerationOpExists:
+		if len(t.Value) > 0 {
+			errs = append(errs, "value must be empty when the operator is Exists")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unsupported operator %q", t.Operator))
+	}
+	switch t.Effect {
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute, "":
+	default:
+		errs = append(errs, fmt.Sprintf("unsupported effect %q", t.Effect))
+	}
+	if t.TolerationSeconds != nil && t.Effect != corev1.TaintEffectNoExecute {
+		errs = append(errs, "tolerationSeconds requires the NoExecute effect")
+	}
+	return errs
+}
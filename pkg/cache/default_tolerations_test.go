@@ -0,0 +1,118 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestSetDefaultTolerations(t *testing.T) {
+	spotToleration := corev1.Toleration{
+		Key:      "instance-type",
+		Operator: corev1.TolerationOpEqual,
+		Value:    "spot",
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	gpuToleration := corev1.Toleration{
+		Key:      "nvidia.com/gpu",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+
+	cases := map[string]struct {
+		tolerations []corev1.Toleration
+		wantActive  bool
+		wantReason  string
+	}{
+		"valid tolerations": {
+			tolerations: []corev1.Toleration{spotToleration, gpuToleration},
+			wantActive:  true,
+			wantReason:  kueue.ClusterQueueActiveReasonReady,
+		},
+		"invalid key": {
+			tolerations: []corev1.Toleration{{Key: "invalid key", Operator: corev1.TolerationOpExists}},
+			wantReason:  kueue.ClusterQueueActiveReasonInvalidDefaultTolerations,
+		},
+		"value with the Exists operator": {
+			tolerations: []corev1.Toleration{{Key: "instance-type", Operator: corev1.TolerationOpExists, Value: "spot"}},
+			wantReason:  kueue.ClusterQueueActiveReasonInvalidDefaultTolerations,
+		},
+		"empty key with the Equal operator": {
+			tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpEqual, Value: "spot"}},
+			wantReason:  kueue.ClusterQueueActiveReasonInvalidDefaultTolerations,
+		},
+		"unsupported effect": {
+			tolerations: []corev1.Toleration{{Key: "instance-type", Operator: corev1.TolerationOpExists, Effect: "Evict"}},
+			wantReason:  kueue.ClusterQueueActiveReasonInvalidDefaultTolerations,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if err := cache.SetDefaultTolerations(log, "cq", tc.tolerations); err != nil {
+				t.Fatalf("Failed setting the default tolerations: %v", err)
+			}
+			if got := cache.ClusterQueueActive("cq"); got != tc.wantActive {
+				t.Errorf("Unexpected active status, want=%v, got=%v", tc.wantActive, got)
+			}
+			if _, reason, _ := cache.ClusterQueueReadiness("cq"); reason != tc.wantReason {
+				t.Errorf("Unexpected readiness reason, want=%s, got=%s", tc.wantReason, reason)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.ClusterQueue("cq") != nil; got != tc.wantActive {
+				t.Errorf("Unexpected ClusterQueue in the snapshot, want=%v, got=%v", tc.wantActive, got)
+			}
+		})
+	}
+}
+
+func TestSetDefaultTolerationsRecovers(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	if err := cache.SetDefaultTolerations(log, "missing", nil); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+	if err := cache.SetDefaultTolerations(log, "cq", []corev1.Toleration{{Key: "invalid key"}}); err != nil {
+		t.Fatalf("Failed setting the default tolerations: %v", err)
+	}
+	if status, _, _ := cache.ClusterQueueReadiness("cq"); status != metav1.ConditionFalse {
+		t.Errorf("Unexpected readiness status with invalid tolerations: %s", status)
+	}
+	if err := cache.SetDefaultTolerations(log, "cq", nil); err != nil {
+		t.Fatalf("Failed clearing the default tolerations: %v", err)
+	}
+	if status, _, _ := cache.ClusterQueueReadiness("cq"); status != metav1.ConditionTrue {
+		t.Errorf("Unexpected readiness status after clearing the tolerations: %s", status)
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package cache
 
 import (
+	"cmp"
+	"maps"
 	"math"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -35,6 +38,7 @@ var (
 type dominantResourceShareNode interface {
 	// see FairSharing.Weight in the API.
 	fairWeight() *resource.Quantity
+	fairSharingUsage(FairSharingUsage) resources.FlavorResourceQuantities
 	hierarchicalResourceNode
 }
 
@@ -45,15 +49,17 @@ type dominantResourceShareNode interface {
 // it means that the usage of the ClusterQueue is below the nominal
 // quota.  The function also returns the resource name that yielded
 // this value.  When the FairSharing weight is 0, and the ClusterQueue
-// or Cohort is borrowing, we return math.MaxInt.
-func dominantResourceShare(node dominantResourceShareNode, wlReq resources.FlavorResourceQuantities) (int, corev1.ResourceName) {
+// or Cohort is borrowing, we return math.MaxInt. The usage counting
+// toward the share is selected by u.
+func dominantResourceShare(node dominantResourceShareNode, wlReq resources.FlavorResourceQuantities, u FairSharingUsage) (int, corev1.ResourceName) {
 	if !node.HasParent() {
 		return 0, ""
 	}
 
+	usage := node.fairSharingUsage(u)
 	borrowing := make(map[corev1.ResourceName]int64, len(node.getResourceNode().SubtreeQuota))
 	for fr, quota := range node.getResourceNode().SubtreeQuota {
-		amountBorrowed := wlReq[fr] + node.getResourceNode().Usage[fr] - quota
+		amountBorrowed := wlReq[fr] + usage[fr] - quota
 		if amountBorrowed > 0 {
 			borrowing[fr.Resource] += amountBorrowed
 		}
@@ -85,6 +91,132 @@ func dominantResourceShare(node dominantResourceShareNode, wlReq resources.Flavo
 	return int(dws), dRes
 }
 
+// FairShareDeficit returns how far the usage of the ClusterQueue is below
+// its weighted fair share, as a fraction of that fair share. The fair
+// share of a resource is the SubtreeQuota of the parent Cohort, split
+// among the Cohort's members proportionally to their FairSharing weight.
+// The dominant resource, with the highest ratio of usage to fair share,
+// determines the result: a positive value means that the ClusterQueue is
+// starved, while a negative value means that it uses more than its fair
+// share. It returns 0 when the ClusterQueue doesn't belong to a Cohort or
+// its weight is zero.
+func (c *ClusterQueueSnapshot) FairShareDeficit() float64 {
+	if !c.HasParent() || c.FairWeight.IsZero() {
+		return 0
+	}
+	parent := c.Parent()
+	var totalWeight int64
+	for _, cq := range parent.ChildCQs() {
+		totalWeight += cq.FairWeight.MilliValue()
+	}
+	for _, cohort := range parent.ChildCohorts() {
+		totalWeight += cohort.FairWeight.MilliValue()
+	}
+	capacity := make(map[corev1.ResourceName]int64)
+	for fr, q := range parent.ResourceNode.SubtreeQuota {
+		capacity[fr.Resource] += q
+	}
+	usage := make(map[corev1.ResourceName]int64)
+	for fr, q := range c.ResourceNode.Usage {
+		usage[fr.Resource] += q
+	}
+
+	weight := float64(c.FairWeight.MilliValue()) / float64(totalWeight)
+	dominantRatio := math.Inf(-1)
+	for rName, total := range capacity {
+		if total <= 0 {
+			continue
+		}
+		ratio := float64(usage[rName]) / (float64(total) * weight)
+		dominantRatio = max(dominantRatio, ratio)
+	}
+	if math.IsInf(dominantRatio, -1) {
+		return 0
+	}
+	return 1 - dominantRatio
+}
+
+// FairShareTargets returns, for each ClusterQueue in the Cohort, its
+// weighted share of the capacity which the Cohort can lend to its
+// members, that is its SubtreeQuota. This is the usage which fair sharing
+// drives the ClusterQueues toward. The capacity is split proportionally
+// to the FairSharing weights of the Cohort's ClusterQueues; the child
+// Cohorts get no target, so they don't count toward the weights. The
+// shares are rounded down, and the remainder is given one unit at a time
+// to the ClusterQueues with the largest fractional shares, ties broken by
+// name, so that the targets add up to the capacity. All the targets are
+// zero if no ClusterQueue has a positive weight.
+func (c *CohortSnapshot) FairShareTargets() map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities {
+	cqs := slices.SortedFunc(slices.Values(c.ChildCQs()), func(a, b *ClusterQueueSnapshot) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	var totalWeight int64
+	for _, cq := range cqs {
+		totalWeight += cq.FairWeight.MilliValue()
+	}
+	targets := make(map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities, len(cqs))
+	for _, cq := range cqs {
+		targets[cq.Name] = make(resources.FlavorResourceQuantities, len(c.ResourceNode.SubtreeQuota))
+	}
+	remainders := make([]int64, len(cqs))
+	order := make([]int, len(cqs))
+	for fr, q := range c.ResourceNode.SubtreeQuota {
+		remaining := q
+		for i, cq := range cqs {
+			var share int64
+			if totalWeight > 0 {
+				weighted := q * cq.FairWeight.MilliValue()
+				share, remainders[i] = weighted/totalWeight, weighted%totalWeight
+			}
+			targets[cq.Name][fr] = share
+			remaining -= share
+			order[i] = i
+		}
+		if totalWeight == 0 {
+			continue
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(remainders[b], remainders[a])
+		})
+		for _, i := range order[:remaining] {
+			targets[cqs[i].Name][fr]++
+		}
+	}
+	return targets
+}
+
+// PreviewWeightChange returns the ClusterQueues of the Cohort ordered by
+// their dominant resource share, as if the FairSharing weight of the
+// ClusterQueue was newWeight, without modifying the snapshot. The
+// ClusterQueues with the lowest share, which are the next to get
+// resources under fair sharing, come first, and ties are broken by name.
+func (c *CohortSnapshot) PreviewWeightChange(cqName kueue.ClusterQueueReference, newWeight resource.Quantity) []kueue.ClusterQueueReference {
+	shares := make(map[kueue.ClusterQueueReference]int, len(c.ChildCQs()))
+	for _, cq := range c.ChildCQs() {
+		var node dominantResourceShareNode = cq
+		if cq.Name == cqName {
+			node = &reweightedClusterQueue{ClusterQueueSnapshot: cq, weight: newWeight}
+		}
+		shares[cq.Name], _ = dominantResourceShare(node, nil, cq.fairSharingUsageMode)
+	}
+	order := slices.Collect(maps.Keys(shares))
+	slices.SortFunc(order, func(a, b kueue.ClusterQueueReference) int {
+		return cmp.Or(cmp.Compare(shares[a], shares[b]), cmp.Compare(a, b))
+	})
+	return order
+}
+
+// reweightedClusterQueue overrides the FairSharing weight of the
+// ClusterQueue for the dominant resource share computation.
+type reweightedClusterQueue struct {
+	*ClusterQueueSnapshot
+	weight resource.Quantity
+}
+
+func (r *reweightedClusterQueue) fairWeight() *resource.Quantity {
+	return &r.weight
+}
+
 // calculateLendable aggregates capacity for resources across all
 // FlavorResources.
 func calculateLendable(node hierarchicalResourceNode) map[corev1.ResourceName]int64 {
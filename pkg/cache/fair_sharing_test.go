@@ -645,7 +645,7 @@ func TestDominantResourceShare(t *testing.T) {
 			cacheCohortsMap := cache.hm.Cohorts()
 			gotCache := make([]fairSharingResult, 0, len(cacheClusterQueuesMap)+len(cacheCohortsMap))
 			for _, cq := range cacheClusterQueuesMap {
-				drVal, drName := dominantResourceShare(cq, tc.flvResQ)
+				drVal, drName := dominantResourceShare(cq, tc.flvResQ, FairSharingReservedUsage)
 				gotCache = append(gotCache, fairSharingResult{
 					Name:     string(cq.Name),
 					NodeType: nodeTypeCq,
@@ -654,7 +654,7 @@ func TestDominantResourceShare(t *testing.T) {
 				})
 			}
 			for _, cohort := range cacheCohortsMap {
-				drVal, drName := dominantResourceShare(cohort, tc.flvResQ)
+				drVal, drName := dominantResourceShare(cohort, tc.flvResQ, FairSharingReservedUsage)
 				gotCache = append(gotCache, fairSharingResult{
 					Name:     string(cohort.Name),
 					NodeType: nodeTypeCohort,
@@ -670,7 +670,7 @@ func TestDominantResourceShare(t *testing.T) {
 			snapshotCohortsMap := snapshot.Cohorts()
 			gotSnapshot := make([]fairSharingResult, 0, len(snapshotClusterQueuesMap)+len(snapshotCohortsMap))
 			for _, cq := range snapshotClusterQueuesMap {
-				drVal, drName := dominantResourceShare(cq, tc.flvResQ)
+				drVal, drName := dominantResourceShare(cq, tc.flvResQ, FairSharingReservedUsage)
 				gotSnapshot = append(gotSnapshot, fairSharingResult{
 					Name:     string(cq.Name),
 					NodeType: nodeTypeCq,
@@ -679,7 +679,7 @@ func TestDominantResourceShare(t *testing.T) {
 				})
 			}
 			for _, cohort := range snapshotCohortsMap {
-				drVal, drName := dominantResourceShare(cohort, tc.flvResQ)
+				drVal, drName := dominantResourceShare(cohort, tc.flvResQ, FairSharingReservedUsage)
 				gotSnapshot = append(gotSnapshot, fairSharingResult{
 					Name:     string(cohort.Name),
 					NodeType: nodeTypeCohort,
@@ -693,3 +693,330 @@ func TestDominantResourceShare(t *testing.T) {
 		})
 	}
 }
+
+func TestFairShareDeficit(t *testing.T) {
+	cases := map[string]struct {
+		usage map[kueue.ClusterQueueReference]string
+		want  map[kueue.ClusterQueueReference]float64
+	}{
+		"no usage": {
+			want: map[kueue.ClusterQueueReference]float64{
+				"cq-a":     1,
+				"cq-b":     1,
+				"no-share": 0,
+			},
+		},
+		"one above and one below the fair share": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"cq-a": "4",
+				"cq-b": "3",
+			},
+			want: map[kueue.ClusterQueueReference]float64{
+				"cq-a":     -1,
+				"cq-b":     0.5,
+				"no-share": 0,
+			},
+		},
+		"exactly at the fair share": {
+			usage: map[kueue.ClusterQueueReference]string{
+				"cq-a": "2",
+				"cq-b": "6",
+			},
+			want: map[kueue.ClusterQueueReference]float64{
+				"cq-a":     0,
+				"cq-b":     0,
+				"no-share": 0,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq-a").
+					Cohort("cohort").
+					FairWeight(resource.MustParse("1")).
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("cq-b").
+					Cohort("cohort").
+					FairWeight(resource.MustParse("3")).
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("no-share").
+					Cohort("cohort").
+					FairWeight(resource.MustParse("0")).
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "0").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for cqName, cpu := range tc.usage {
+				wl := utiltesting.MakeWorkload(fmt.Sprintf("wl-%s", cqName), "ns").
+					Request(corev1.ResourceCPU, cpu).
+					ReserveQuota(utiltesting.MakeAdmission(string(cqName)).Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			got := make(map[kueue.ClusterQueueReference]float64, len(tc.want))
+			for cqName := range tc.want {
+				got[cqName] = snapshot.ClusterQueue(cqName).FairShareDeficit()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected fair share deficit (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFairShareTargets(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq-a").
+			Cohort("cohort").
+			FairWeight(resource.MustParse("1")).
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "4").
+				Resource(corev1.ResourceMemory, "4Gi").
+				Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-b").
+			Cohort("cohort").
+			FairWeight(resource.MustParse("3")).
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "8", "", "4").
+				Resource(corev1.ResourceMemory, "4Gi").
+				Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("no-share").
+			Cohort("cohort").
+			FairWeight(resource.MustParse("0")).
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+				Resource(corev1.ResourceCPU, "0").
+				Resource(corev1.ResourceMemory, "0").
+				Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	memory := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceMemory}
+	cohort := snapshot.ClusterQueue("cq-a").Parent()
+	got := cohort.FairShareTargets()
+	want := map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities{
+		"cq-a":     {cpu: 2_000, memory: 2 * 1024 * 1024 * 1024},
+		"cq-b":     {cpu: 6_000, memory: 6 * 1024 * 1024 * 1024},
+		"no-share": {cpu: 0, memory: 0},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected fair share targets (-want,+got):\n%s", diff)
+	}
+	total := make(resources.FlavorResourceQuantities)
+	for _, target := range got {
+		for fr, q := range target {
+			total[fr] += q
+		}
+	}
+	if diff := cmp.Diff(cohort.ResourceNode.SubtreeQuota, total); diff != "" {
+		t.Errorf("Fair share targets don't add up to the Cohort's lendable capacity (-want,+got):\n%s", diff)
+	}
+}
+
+func TestFairShareTargetsRounding(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").
+		Parent("cohort").
+		FairWeight(resource.MustParse("5")).
+		Obj()); err != nil {
+		t.Fatalf("Failed adding Cohort: %v", err)
+	}
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq-c").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "0").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-b").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "3").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-a").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "5").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("cq-child").
+			Cohort("child").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	// The 10 CPUs of the Cohort, including the ones of the child Cohort,
+	// are split among the 3 ClusterQueues with the same weight, and the
+	// remaining unit goes to the first one by name.
+	cpu := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	want := map[kueue.ClusterQueueReference]resources.FlavorResourceQuantities{
+		"cq-a": {cpu: 3_334},
+		"cq-b": {cpu: 3_333},
+		"cq-c": {cpu: 3_333},
+	}
+	for range 3 {
+		if diff := cmp.Diff(want, snapshot.Cohort("cohort").FairShareTargets()); diff != "" {
+			t.Errorf("Unexpected fair share targets (-want,+got):\n%s", diff)
+		}
+	}
+}
+
+func TestPreviewWeightChange(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient(), WithFairSharing(true))
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cqName := range []string{"a", "b", "c"} {
+		cq := utiltesting.MakeClusterQueue(cqName).
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj()
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for cqName, cpu := range map[string]string{"a": "6", "b": "5"} {
+		wl := utiltesting.MakeWorkload(cqName, "ns").
+			Request(corev1.ResourceCPU, cpu).
+			ReserveQuota(utiltesting.MakeAdmission(cqName).Assignment(corev1.ResourceCPU, "default", cpu).Obj()).
+			Obj()
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %q", wl.Name)
+		}
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	cohort := snapshot.Cohort("cohort")
+
+	cases := map[string]struct {
+		cqName    kueue.ClusterQueueReference
+		newWeight string
+		want      []kueue.ClusterQueueReference
+	}{
+		"unchanged weight": {
+			cqName:    "a",
+			newWeight: "1",
+			want:      []kueue.ClusterQueueReference{"c", "b", "a"},
+		},
+		"higher weight moves the ClusterQueue ahead": {
+			cqName:    "a",
+			newWeight: "4",
+			want:      []kueue.ClusterQueueReference{"c", "a", "b"},
+		},
+		"zero weight moves the borrowing ClusterQueue last": {
+			cqName:    "b",
+			newWeight: "0",
+			want:      []kueue.ClusterQueueReference{"c", "a", "b"},
+		},
+		"weight of a ClusterQueue which doesn't borrow": {
+			cqName:    "c",
+			newWeight: "0",
+			want:      []kueue.ClusterQueueReference{"c", "b", "a"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			shareBefore := snapshot.ClusterQueue(tc.cqName).DominantResourceShare()
+			got := cohort.PreviewWeightChange(tc.cqName, resource.MustParse(tc.newWeight))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected order (-want,+got):\n%s", diff)
+			}
+			if w := snapshot.ClusterQueue(tc.cqName).FairWeight; !w.Equal(resource.MustParse("1")) {
+				t.Errorf("Unexpected change of the weight in the snapshot: %s", w.String())
+			}
+			if got := snapshot.ClusterQueue(tc.cqName).DominantResourceShare(); got != shareBefore {
+				t.Errorf("Unexpected change of the share in the snapshot, want=%d, got=%d", shareBefore, got)
+			}
+		})
+	}
+}
+
+func TestFractionalFairWeightOrdering(t *testing.T) {
+	cases := map[string]struct {
+		weights map[string]string
+		want    []kueue.ClusterQueueReference
+	}{
+		"fractional and whole weights": {
+			weights: map[string]string{"a": "1500m", "b": "1", "c": "2"},
+			want:    []kueue.ClusterQueueReference{"c", "a", "b"},
+		},
+		"near-equal weights": {
+			weights: map[string]string{"a": "1001m", "b": "1", "c": "999m"},
+			want:    []kueue.ClusterQueueReference{"a", "b", "c"},
+		},
+		"equal weights written differently": {
+			weights: map[string]string{"a": "1000m", "b": "1", "c": "1.0"},
+			want:    []kueue.ClusterQueueReference{"a", "b", "c"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), WithFairSharing(true))
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for cqName, weight := range tc.weights {
+				cq := utiltesting.MakeClusterQueue(cqName).
+					Cohort("cohort").
+					FairWeight(resource.MustParse(weight)).
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj()
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+				wl := utiltesting.MakeWorkload(cqName, "ns").
+					Request(corev1.ResourceCPU, "5").
+					ReserveQuota(utiltesting.MakeAdmission(cqName).Assignment(corev1.ResourceCPU, "default", "5").Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			// The ordering is deterministic across snapshots, whose
+			// ClusterQueues are iterated in random order.
+			for range 3 {
+				snapshot, err := cache.Snapshot(ctx)
+				if err != nil {
+					t.Fatalf("Failed taking snapshot: %v", err)
+				}
+				got := snapshot.Cohort("cohort").PreviewWeightChange("a", resource.MustParse(tc.weights["a"]))
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("Unexpected order (-want,+got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sigs.k8s.io/kueue/pkg/resources"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// FairSharingUsage defines which usage counts toward the share of the
+// ClusterQueues and Cohorts in the fair sharing computations.
+type FairSharingUsage string
+
+const (
+	// FairSharingReservedUsage counts the usage of all the workloads
+	// reserving quota, whether they are admitted or still waiting for
+	// their admission checks. This is the default.
+	FairSharingReservedUsage FairSharingUsage = "Reserved"
+	// FairSharingAdmittedUsage only counts the usage of the admitted
+	// workloads.
+	FairSharingAdmittedUsage FairSharingUsage = "Admitted"
+)
+
+// WithFairSharingUsage sets which usage counts toward the fair sharing
+// shares. By default, FairSharingReservedUsage is used.
+func WithFairSharingUsage(u FairSharingUsage) Option {
+	return func(o *options) {
+		o.fairSharingUsage = u
+	}
+}
+
+// FairSharingUsage returns which usage counts toward the fair sharing
+// shares.
+func (c *Cache) FairSharingUsage() FairSharingUsage {
+	if c.fairSharingUsage == "" {
+		return FairSharingReservedUsage
+	}
+	return c.fairSharingUsage
+}
+
+// fairSharingUsage returns the usage of the ClusterQueue which counts
+// toward its share.
+func (c *clusterQueue) fairSharingUsage(u FairSharingUsage) resources.FlavorResourceQuantities {
+	if u == FairSharingAdmittedUsage {
+		return c.AdmittedUsage
+	}
+	return c.resourceNode.Usage
+}
+
+// fairSharingUsage returns the usage of the Cohort which counts toward
+// its share. With FairSharingAdmittedUsage, it is the admitted usage of
+// its members past their guaranteed quota.
+func (c *cohort) fairSharingUsage(u FairSharingUsage) resources.FlavorResourceQuantities {
+	if u != FairSharingAdmittedUsage {
+		return c.resourceNode.Usage
+	}
+	usage := make(resources.FlavorResourceQuantities, len(c.resourceNode.Usage))
+	for _, child := range c.ChildCohorts() {
+		accumulateFairSharingUsage(usage, child, child.fairSharingUsage(u))
+	}
+	for _, child := range c.ChildCQs() {
+		accumulateFairSharingUsage(usage, child, child.fairSharingUsage(u))
+	}
+	return usage
+}
+
+// fairSharingUsage returns the usage of the ClusterQueue which counts
+// toward its share.
+func (c *ClusterQueueSnapshot) fairSharingUsage(u FairSharingUsage) resources.FlavorResourceQuantities {
+	if u != FairSharingAdmittedUsage || len(c.unadmittedUsage) == 0 {
+		return c.ResourceNode.Usage
+	}
+	usage := make(resources.FlavorResourceQuantities, len(c.ResourceNode.Usage))
+	for fr, q := range c.ResourceNode.Usage {
+		usage[fr] = max(0, q-c.unadmittedUsage[fr])
+	}
+	return usage
+}
+
+// fairSharingUsage returns the usage of the Cohort which counts toward
+// its share. With FairSharingAdmittedUsage, it is the admitted usage of
+// its members past their guaranteed quota.
+func (c *CohortSnapshot) fairSharingUsage(u FairSharingUsage) resources.FlavorResourceQuantities {
+	if u != FairSharingAdmittedUsage {
+		return c.ResourceNode.Usage
+	}
+	usage := make(resources.FlavorResourceQuantities, len(c.ResourceNode.Usage))
+	for _, child := range c.ChildCohorts() {
+		accumulateFairSharingUsage(usage, child, child.fairSharingUsage(u))
+	}
+	for _, child := range c.ChildCQs() {
+		accumulateFairSharingUsage(usage, child, child.fairSharingUsage(u))
+	}
+	return usage
+}
+
+// accumulateFairSharingUsage adds the usage of the child past its
+// guaranteed quota, which is the part stored in its parent.
+func accumulateFairSharingUsage(usage resources.FlavorResourceQuantities, child hierarchicalResourceNode, childUsage resources.FlavorResourceQuantities) {
+	for fr, q := range childUsage {
+		usage[fr] += max(0, q-child.getResourceNode().guaranteedQuota(fr))
+	}
+}
+
+// updateUnadmittedUsage tracks the usage of the workload if it is
+// reserving quota without being admitted yet.
+func (c *ClusterQueueSnapshot) updateUnadmittedUsage(wi *workload.Info, m int64) {
+	if c.fairSharingUsageMode != FairSharingAdmittedUsage || workload.IsAdmitted(wi.Obj) {
+		return
+	}
+	if c.unadmittedUsage == nil {
+		c.unadmittedUsage = make(resources.FlavorResourceQuantities)
+	}
+	updateFlavorUsage(wi.FlavorResourceUsage(), c.unadmittedUsage, m)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestFairSharingUsage(t *testing.T) {
+	cases := map[string]struct {
+		opts []Option
+		// wantMode is the mode reported by the cache.
+		wantMode FairSharingUsage
+		// wantReservingFirst indicates whether the ClusterQueue holding
+		// many reservations has a larger share than the one with an
+		// admitted workload.
+		wantReservingFirst bool
+		wantReservingShare bool
+	}{
+		"reserved usage by default": {
+			wantMode:           FairSharingReservedUsage,
+			wantReservingFirst: true,
+			wantReservingShare: true,
+		},
+		"admitted usage only": {
+			opts:     []Option{WithFairSharingUsage(FairSharingAdmittedUsage)},
+			wantMode: FairSharingAdmittedUsage,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), append([]Option{WithFairSharing(true)}, tc.opts...)...)
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("root").Obj()); err != nil {
+				t.Fatalf("Failed adding Cohort: %v", err)
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("reserving").
+					Cohort("child").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("admitted").
+					Cohort("root").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("root").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "8").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for i := range 4 {
+				wl := utiltesting.MakeWorkload(fmt.Sprintf("reserving-%d", i), "ns").
+					Request(corev1.ResourceCPU, "2").
+					ReserveQuota(utiltesting.MakeAdmission("reserving").Assignment(corev1.ResourceCPU, "default", "2").Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", wl.Name)
+				}
+			}
+			admitted := utiltesting.MakeWorkload("admitted", "ns").
+				Request(corev1.ResourceCPU, "6").
+				ReserveQuota(utiltesting.MakeAdmission("admitted").Assignment(corev1.ResourceCPU, "default", "6").Obj()).
+				Admitted(true).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, admitted) {
+				t.Fatal("Failed adding the admitted workload")
+			}
+
+			if got := cache.FairSharingUsage(); got != tc.wantMode {
+				t.Errorf("Unexpected fair sharing usage, want=%q, got=%q", tc.wantMode, got)
+			}
+
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			reservingShare := snapshot.ClusterQueue("reserving").DominantResourceShare()
+			admittedShare := snapshot.ClusterQueue("admitted").DominantResourceShare()
+			if got := reservingShare > admittedShare; got != tc.wantReservingFirst {
+				t.Errorf("Unexpected share ordering, reserving=%d, admitted=%d", reservingShare, admittedShare)
+			}
+			if got := reservingShare > 0; got != tc.wantReservingShare {
+				t.Errorf("Unexpected share of the reserving ClusterQueue: %d", reservingShare)
+			}
+			if got := snapshot.Cohort("child").DominantResourceShare() > 0; got != tc.wantReservingShare {
+				t.Errorf("Unexpected share of the Cohort with reservations: %d", snapshot.Cohort("child").DominantResourceShare())
+			}
+
+			stats, err := cache.Usage(utiltesting.MakeClusterQueue("reserving").Obj())
+			if err != nil {
+				t.Fatalf("Failed getting usage: %v", err)
+			}
+			if stats.WeightedShare != int64(reservingShare) {
+				t.Errorf("Unexpected weighted share in the cache, want=%d, got=%d", reservingShare, stats.WeightedShare)
+			}
+			cohortStats, err := cache.CohortStats(utiltesting.MakeCohort("child").Obj())
+			if err != nil {
+				t.Fatalf("Failed getting cohort stats: %v", err)
+			}
+			if cohortStats.WeightedShare != int64(snapshot.Cohort("child").DominantResourceShare()) {
+				t.Errorf("Unexpected weighted share of the Cohort in the cache, want=%d, got=%d", snapshot.Cohort("child").DominantResourceShare(), cohortStats.WeightedShare)
+			}
+
+			restore := snapshot.ClusterQueue("reserving").SimulateWorkloadRemoval([]*workload.Info{snapshot.ClusterQueue("reserving").Workloads["ns/reserving-0"]})
+			restore()
+			if got := snapshot.ClusterQueue("reserving").DominantResourceShare(); got != reservingShare {
+				t.Errorf("Unexpected share after restoring the simulation, want=%d, got=%d", reservingShare, got)
+			}
+		})
+	}
+}
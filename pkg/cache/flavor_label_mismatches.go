@@ -0,0 +1,47 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// RecordNodeLabelMismatches records that a workload couldn't be assigned
+// the flavors because their node labels don't match the node affinity of
+// the workload. A flavor is counted once per occurrence in the list.
+func (c *Cache) RecordNodeLabelMismatches(flavors []kueue.ResourceFlavorReference) {
+	if len(flavors) == 0 {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	for _, f := range flavors {
+		c.nodeLabelMismatches[f]++
+	}
+}
+
+// NodeLabelMismatches returns, by flavor, the number of times a workload
+// couldn't be assigned the flavor because of a node label mismatch. A flavor
+// which is frequently mismatched is likely misconfigured. The count of a
+// flavor is cleared when the flavor is deleted.
+func (c *Cache) NodeLabelMismatches() map[kueue.ResourceFlavorReference]int {
+	c.RLock()
+	defer c.RUnlock()
+	return maps.Clone(c.nodeLabelMismatches)
+}
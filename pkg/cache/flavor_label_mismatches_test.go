@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestNodeLabelMismatches(t *testing.T) {
+	cases := map[string]struct {
+		mismatches    [][]kueue.ResourceFlavorReference
+		deleteFlavors []string
+		want          map[kueue.ResourceFlavorReference]int
+	}{
+		"no mismatches": {
+			want: map[kueue.ResourceFlavorReference]int{},
+		},
+		"mismatches accumulate": {
+			mismatches: [][]kueue.ResourceFlavorReference{
+				{"on-demand", "spot"},
+				{"spot"},
+				nil,
+				{"spot", "spot"},
+			},
+			want: map[kueue.ResourceFlavorReference]int{
+				"on-demand": 1,
+				"spot":      4,
+			},
+		},
+		"deleted flavor is cleared": {
+			mismatches: [][]kueue.ResourceFlavorReference{
+				{"on-demand", "spot"},
+				{"spot"},
+			},
+			deleteFlavors: []string{"spot"},
+			want: map[kueue.ResourceFlavorReference]int{
+				"on-demand": 1,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			for _, flavors := range tc.mismatches {
+				cache.RecordNodeLabelMismatches(flavors)
+			}
+			for _, f := range tc.deleteFlavors {
+				cache.DeleteResourceFlavor(log, utiltesting.MakeResourceFlavor(f).Obj())
+			}
+			if diff := cmp.Diff(tc.want, cache.NodeLabelMismatches()); diff != "" {
+				t.Errorf("Unexpected node label mismatches (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// LabelKeysObserver is notified when the label keys of the ResourceGroups
+// of a ClusterQueue change, after the node labels of its flavors were
+// updated. The notifications are sent while holding the cache lock, so
+// observers must not call the cache.
+type LabelKeysObserver interface {
+	NotifyLabelKeysChanged(cqName kueue.ClusterQueueReference)
+}
+
+// WithLabelKeysObservers sets the observers notified when the label keys
+// of a ClusterQueue change.
+func WithLabelKeysObservers(observers ...LabelKeysObserver) Option {
+	return func(o *options) {
+		o.labelKeysObservers = append(o.labelKeysObservers, observers...)
+	}
+}
+
+func (c *Cache) notifyLabelKeysChanged(cqName kueue.ClusterQueueReference) {
+	for _, o := range c.labelKeysObservers {
+		o.NotifyLabelKeysChanged(cqName)
+	}
+}
+
+// labelKeys returns the label keys of each ResourceGroup. The sets are
+// not copied, as updateLabelKeys replaces them instead of mutating them.
+func (c *clusterQueue) labelKeys() []sets.Set[string] {
+	keys := make([]sets.Set[string], len(c.ResourceGroups))
+	for i := range c.ResourceGroups {
+		keys[i] = c.ResourceGroups[i].LabelKeys
+	}
+	return keys
+}
+
+// labelKeysEqual indicates whether the label keys of the ResourceGroups
+// are the same as the previous ones.
+func (c *clusterQueue) labelKeysEqual(prev []sets.Set[string]) bool {
+	if len(prev) != len(c.ResourceGroups) {
+		return false
+	}
+	for i := range c.ResourceGroups {
+		if !c.ResourceGroups[i].LabelKeys.Equal(prev[i]) {
+			return false
+		}
+	}
+	return true
+}
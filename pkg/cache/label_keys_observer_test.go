@@ -0,0 +1,107 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+type recordingLabelKeysObserver struct {
+	changed []kueue.ClusterQueueReference
+}
+
+func (o *recordingLabelKeysObserver) NotifyLabelKeysChanged(cqName kueue.ClusterQueueReference) {
+	o.changed = append(o.changed, cqName)
+}
+
+func TestLabelKeysObserver(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	observer := &recordingLabelKeysObserver{}
+	cache := New(utiltesting.NewFakeClient(), WithLabelKeysObservers(observer))
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("other").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "10").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+
+	steps := []struct {
+		name string
+		do   func()
+		want []kueue.ClusterQueueReference
+	}{
+		{
+			name: "label added",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").NodeLabel("instance-type", "spot").Obj())
+			},
+			want: []kueue.ClusterQueueReference{"cq"},
+		},
+		{
+			name: "label value changed",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").NodeLabel("instance-type", "preemptible").Obj())
+			},
+			want: []kueue.ClusterQueueReference{"cq"},
+		},
+		{
+			name: "label of a flavor not used by the ClusterQueue added",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("other").NodeLabel("zone", "a").Obj())
+			},
+			want: []kueue.ClusterQueueReference{"cq"},
+		},
+		{
+			name: "second label added",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").
+					NodeLabel("instance-type", "preemptible").
+					NodeLabel("zone", "a").
+					Obj())
+			},
+			want: []kueue.ClusterQueueReference{"cq", "cq"},
+		},
+		{
+			name: "labels removed",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+			},
+			want: []kueue.ClusterQueueReference{"cq", "cq", "cq"},
+		},
+		{
+			name: "flavor updated without labels",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+			},
+			want: []kueue.ClusterQueueReference{"cq", "cq", "cq"},
+		},
+	}
+	for _, step := range steps {
+		step.do()
+		if diff := cmp.Diff(step.want, observer.changed); diff != "" {
+			t.Errorf("After step %q, unexpected notified ClusterQueues (-want,+got):\n%s", step.name, diff)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+// LenderBorrowingLimits holds, by flavor and resource, the maximum
+// capacity which a ClusterQueue can borrow from each of the ClusterQueues
+// of its Cohort.
+type LenderBorrowingLimits map[resources.FlavorResource]map[kueue.ClusterQueueReference]int64
+
+// SetLenderBorrowingLimit limits how much of the flavor and resource the
+// ClusterQueue can borrow from the lender, another ClusterQueue of its
+// Cohort. A nil limit removes the limit. The limits are applied on top
+// of the BorrowingLimit of the ClusterQueue.
+func (c *Cache) SetLenderBorrowingLimit(cqName kueue.ClusterQueueReference, fr resources.FlavorResource, lender kueue.ClusterQueueReference, limit *int64) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	// The limits are shared with the snapshots, so they are replaced
+	// rather than modified.
+	limits := maps.Clone(cq.lenderBorrowingLimits)
+	if limits == nil {
+		limits = make(LenderBorrowingLimits, 1)
+	}
+	byLender := maps.Clone(limits[fr])
+	if limit == nil {
+		delete(byLender, lender)
+	} else {
+		if byLender == nil {
+			byLender = make(map[kueue.ClusterQueueReference]int64, 1)
+		}
+		byLender[lender] = *limit
+	}
+	if len(byLender) == 0 {
+		delete(limits, fr)
+	} else {
+		limits[fr] = byLender
+	}
+	if len(limits) == 0 {
+		limits = nil
+	}
+	cq.lenderBorrowingLimits = limits
+	return nil
+}
+
+// lenderLimitedNode is implemented by the nodes which have borrowing
+// limits by lender.
+type lenderLimitedNode interface {
+	// lenderLimitedExcess returns the capacity of the parent which can't
+	// be borrowed by the node because of the limits by lender. When
+	// potential is true, it assumes no usage.
+	lenderLimitedExcess(fr resources.FlavorResource, potential bool) int64
+}
+
+var _ lenderLimitedNode = (*ClusterQueueSnapshot)(nil)
+
+// lenderLimitedExcess returns how much the capacity which the limited
+// lenders can lend to the Cohort exceeds the limits. The capacity lent by
+// a ClusterQueue is its quota beyond its LendingLimit guarantee, minus
+// the part which it is using itself. Only lenders which are direct
+// members of the Cohort are considered.
+func (c *ClusterQueueSnapshot) lenderLimitedExcess(fr resources.FlavorResource, potential bool) int64 {
+	limits := c.LenderBorrowingLimits[fr]
+	if len(limits) == 0 || !c.HasParent() {
+		return 0
+	}
+	var excess int64
+	for _, lender := range c.Parent().ChildCQs() {
+		limit, found := limits[lender.Name]
+		if !found || lender == c {
+			continue
+		}
+		r := lender.ResourceNode
+		lent := r.SubtreeQuota[fr] - r.guaranteedQuota(fr)
+		if !potential {
+			lent -= max(0, r.Usage[fr]-r.guaranteedQuota(fr))
+		}
+		excess += max(0, lent-limit)
+	}
+	return excess
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestLenderBorrowingLimits(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		limits map[kueue.ClusterQueueReference]int64
+		// removedLimits are set and then removed.
+		removedLimits          []kueue.ClusterQueueReference
+		peerAUsage             string
+		wantAvailable          int64
+		wantPotentialAvailable int64
+	}{
+		"no limits": {
+			wantAvailable:          8_000,
+			wantPotentialAvailable: 8_000,
+		},
+		"borrowing from peer A is limited": {
+			limits:                 map[kueue.ClusterQueueReference]int64{"peer-a": 1_000},
+			wantAvailable:          5_000,
+			wantPotentialAvailable: 5_000,
+		},
+		"borrowing from peer A is limited and peer A uses part of its quota": {
+			limits:                 map[kueue.ClusterQueueReference]int64{"peer-a": 1_000},
+			peerAUsage:             "2",
+			wantAvailable:          5_000,
+			wantPotentialAvailable: 5_000,
+		},
+		"limit above what peer A lends": {
+			limits:                 map[kueue.ClusterQueueReference]int64{"peer-a": 1_000},
+			peerAUsage:             "3",
+			wantAvailable:          5_000,
+			wantPotentialAvailable: 5_000,
+		},
+		"borrowing from both peers is limited": {
+			limits: map[kueue.ClusterQueueReference]int64{
+				"peer-a": 1_000,
+				"peer-b": 0,
+			},
+			wantAvailable:          1_000,
+			wantPotentialAvailable: 1_000,
+		},
+		"limit removed": {
+			limits:                 map[kueue.ClusterQueueReference]int64{"peer-a": 1_000},
+			removedLimits:          []kueue.ClusterQueueReference{"peer-b"},
+			wantAvailable:          5_000,
+			wantPotentialAvailable: 5_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "0").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("peer-a").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("peer-b").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			if tc.peerAUsage != "" {
+				wl := utiltesting.MakeWorkload("wl", "ns").
+					Request(corev1.ResourceCPU, tc.peerAUsage).
+					ReserveQuota(utiltesting.MakeAdmission("peer-a").Assignment(corev1.ResourceCPU, "default", tc.peerAUsage).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatal("Failed adding workload")
+				}
+			}
+			for lender, limit := range tc.limits {
+				if err := cache.SetLenderBorrowingLimit("cq", fr, lender, ptr.To(limit)); err != nil {
+					t.Fatalf("Failed setting the limit: %v", err)
+				}
+			}
+			for _, lender := range tc.removedLimits {
+				if err := cache.SetLenderBorrowingLimit("cq", fr, lender, ptr.To[int64](0)); err != nil {
+					t.Fatalf("Failed setting the limit: %v", err)
+				}
+				if err := cache.SetLenderBorrowingLimit("cq", fr, lender, nil); err != nil {
+					t.Fatalf("Failed removing the limit: %v", err)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cq := snapshot.ClusterQueue("cq")
+			if got := cq.Available(fr); got != tc.wantAvailable {
+				t.Errorf("Unexpected available, want=%d, got=%d", tc.wantAvailable, got)
+			}
+			if got := cq.PotentialAvailable(fr); got != tc.wantPotentialAvailable {
+				t.Errorf("Unexpected potential available, want=%d, got=%d", tc.wantPotentialAvailable, got)
+			}
+		})
+	}
+}
+
+func TestSetLenderBorrowingLimitUnknownClusterQueue(t *testing.T) {
+	cache := New(utiltesting.NewFakeClient())
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	if err := cache.SetLenderBorrowingLimit("cq", fr, "peer-a", ptr.To[int64](1)); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
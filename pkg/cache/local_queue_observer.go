@@ -0,0 +1,70 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// LocalQueueObserver is notified when a LocalQueue is registered in, or
+// removed from, a ClusterQueue in the cache. The notifications are sent
+// while holding the cache lock, so observers must not call the cache.
+type LocalQueueObserver interface {
+	NotifyLocalQueueAdded(cqName kueue.ClusterQueueReference, lqKey string)
+	NotifyLocalQueueDeleted(cqName kueue.ClusterQueueReference, lqKey string)
+}
+
+// WithLocalQueueObservers sets the observers notified when LocalQueues
+// are added to or removed from the cache.
+func WithLocalQueueObservers(observers ...LocalQueueObserver) Option {
+	return func(o *options) {
+		o.localQueueObservers = append(o.localQueueObservers, observers...)
+	}
+}
+
+func (c *Cache) notifyLocalQueueAdded(cqName kueue.ClusterQueueReference, lqKey string) {
+	for _, o := range c.localQueueObservers {
+		o.NotifyLocalQueueAdded(cqName, lqKey)
+	}
+}
+
+func (c *Cache) notifyLocalQueueDeleted(cqName kueue.ClusterQueueReference, lqKey string) {
+	for _, o := range c.localQueueObservers {
+		o.NotifyLocalQueueDeleted(cqName, lqKey)
+	}
+}
+
+// addLocalQueue adds the LocalQueue to the ClusterQueue and notifies the
+// observers.
+func (c *Cache) addLocalQueue(cq *clusterQueue, q *kueue.LocalQueue) error {
+	if err := cq.addLocalQueue(q); err != nil {
+		return err
+	}
+	c.notifyLocalQueueAdded(cq.Name, queueKey(q))
+	return nil
+}
+
+// deleteLocalQueue removes the LocalQueue from the ClusterQueue and
+// notifies the observers, if the LocalQueue was in the ClusterQueue.
+func (c *Cache) deleteLocalQueue(cq *clusterQueue, q *kueue.LocalQueue) {
+	qKey := queueKey(q)
+	if _, found := cq.localQueues[qKey]; !found {
+		return
+	}
+	cq.deleteLocalQueue(q)
+	c.notifyLocalQueueDeleted(cq.Name, qKey)
+}
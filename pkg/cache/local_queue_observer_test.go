@@ -0,0 +1,150 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+type localQueueEvent struct {
+	Added bool
+	CQ    kueue.ClusterQueueReference
+	LQ    string
+}
+
+type recordingLocalQueueObserver struct {
+	events []localQueueEvent
+}
+
+func (o *recordingLocalQueueObserver) NotifyLocalQueueAdded(cqName kueue.ClusterQueueReference, lqKey string) {
+	o.events = append(o.events, localQueueEvent{Added: true, CQ: cqName, LQ: lqKey})
+}
+
+func (o *recordingLocalQueueObserver) NotifyLocalQueueDeleted(cqName kueue.ClusterQueueReference, lqKey string) {
+	o.events = append(o.events, localQueueEvent{CQ: cqName, LQ: lqKey})
+}
+
+func TestLocalQueueObservers(t *testing.T) {
+	lq := utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("cq").Obj()
+	cases := map[string]struct {
+		operation  func(t *testing.T, cache *Cache)
+		wantEvents []localQueueEvent
+	}{
+		"add": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+			},
+			wantEvents: []localQueueEvent{{Added: true, CQ: "cq", LQ: "ns/lq"}},
+		},
+		"add twice": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+				if err := cache.AddLocalQueue(lq); err == nil {
+					t.Error("Expected an error adding the LocalQueue twice")
+				}
+			},
+			wantEvents: []localQueueEvent{{Added: true, CQ: "cq", LQ: "ns/lq"}},
+		},
+		"add to unknown ClusterQueue": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("other").Obj()); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+			},
+		},
+		"delete": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+				cache.DeleteLocalQueue(lq)
+			},
+			wantEvents: []localQueueEvent{
+				{Added: true, CQ: "cq", LQ: "ns/lq"},
+				{CQ: "cq", LQ: "ns/lq"},
+			},
+		},
+		"delete unknown LocalQueue": {
+			operation: func(_ *testing.T, cache *Cache) {
+				cache.DeleteLocalQueue(lq)
+			},
+		},
+		"update without changing the ClusterQueue": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+				if err := cache.UpdateLocalQueue(lq, lq.DeepCopy()); err != nil {
+					t.Fatalf("Failed updating LocalQueue: %v", err)
+				}
+			},
+			wantEvents: []localQueueEvent{{Added: true, CQ: "cq", LQ: "ns/lq"}},
+		},
+		"move to another ClusterQueue": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+				if err := cache.UpdateLocalQueue(lq, utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("cq2").Obj()); err != nil {
+					t.Fatalf("Failed updating LocalQueue: %v", err)
+				}
+			},
+			wantEvents: []localQueueEvent{
+				{Added: true, CQ: "cq", LQ: "ns/lq"},
+				{CQ: "cq", LQ: "ns/lq"},
+				{Added: true, CQ: "cq2", LQ: "ns/lq"},
+			},
+		},
+		"delete ClusterQueue": {
+			operation: func(t *testing.T, cache *Cache) {
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+				cache.DeleteClusterQueue(utiltesting.MakeClusterQueue("cq").Obj())
+			},
+			wantEvents: []localQueueEvent{
+				{Added: true, CQ: "cq", LQ: "ns/lq"},
+				{CQ: "cq", LQ: "ns/lq"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			observer := &recordingLocalQueueObserver{}
+			cache := New(utiltesting.NewFakeClient(), WithLocalQueueObservers(observer))
+			for _, name := range []string{"cq", "cq2"} {
+				if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue(name).Obj()); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			tc.operation(t, cache)
+			if diff := cmp.Diff(tc.wantEvents, observer.events); diff != "" {
+				t.Errorf("Unexpected notifications (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
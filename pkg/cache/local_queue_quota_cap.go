@@ -0,0 +1,76 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// SetLocalQueueQuotaCap caps, by flavor and resource, the quota of its
+// ClusterQueue which the workloads of the LocalQueue can reserve. The
+// flavors and resources which are not in the cap are not limited. A nil
+// cap removes it. The cap is dropped when the LocalQueue is removed from
+// the cache.
+func (c *Cache) SetLocalQueueQuotaCap(q *kueue.LocalQueue, quotaCap resources.FlavorResourceQuantities) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(q.Spec.ClusterQueue)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	qImpl, found := cq.localQueues[queueKey(q)]
+	if !found {
+		return errLocalQueueNotFound
+	}
+	qImpl.quotaCap = maps.Clone(quotaCap)
+	return nil
+}
+
+// FitsLocalQueueQuotaCap indicates whether the workload can reserve the
+// usage without exceeding the quota cap of its LocalQueue, given the quota
+// already reserved by the workloads of the LocalQueue. The quota reserved
+// by the preempted workloads of the same LocalQueue is freed before the
+// cap is checked. It returns true if the LocalQueue has no cap.
+func (c *Cache) FitsLocalQueueQuotaCap(wl *workload.Info, usage resources.FlavorResourceQuantities, preempted []*workload.Info) bool {
+	c.RLock()
+	defer c.RUnlock()
+	cq := c.hm.ClusterQueue(wl.ClusterQueue)
+	if cq == nil {
+		return true
+	}
+	qKey := workload.QueueKey(wl.Obj)
+	q, found := cq.localQueues[qKey]
+	if !found || len(q.quotaCap) == 0 {
+		return true
+	}
+	freed := make(resources.FlavorResourceQuantities)
+	for _, p := range preempted {
+		if p.ClusterQueue == wl.ClusterQueue && workload.QueueKey(p.Obj) == qKey {
+			updateFlavorUsage(p.FlavorResourceUsage(), freed, 1)
+		}
+	}
+	for fr, limit := range q.quotaCap {
+		if q.totalReserved[fr]-freed[fr]+usage[fr] > limit {
+			return false
+		}
+	}
+	return true
+}
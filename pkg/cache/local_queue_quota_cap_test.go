@@ -0,0 +1,183 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestLocalQueueQuotaCap(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		quotaCap     resources.FlavorResourceQuantities
+		wantAdmitted map[string]int
+	}{
+		"no cap": {
+			wantAdmitted: map[string]int{
+				"capped":   5,
+				"uncapped": 5,
+			},
+		},
+		"capped below the ClusterQueue quota": {
+			quotaCap: resources.FlavorResourceQuantities{fr: 3_000},
+			wantAdmitted: map[string]int{
+				"capped":   3,
+				"uncapped": 5,
+			},
+		},
+		"cap on another resource": {
+			quotaCap: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: corev1.ResourceMemory}: 1,
+			},
+			wantAdmitted: map[string]int{
+				"capped":   5,
+				"uncapped": 5,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			for _, lqName := range []string{"capped", "uncapped"} {
+				if err := cache.AddLocalQueue(utiltesting.MakeLocalQueue(lqName, "ns").ClusterQueue("cq").Obj()); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+			}
+			if err := cache.SetLocalQueueQuotaCap(utiltesting.MakeLocalQueue("capped", "ns").ClusterQueue("cq").Obj(), tc.quotaCap); err != nil {
+				t.Fatalf("Failed setting the quota cap: %v", err)
+			}
+
+			// Admit the workloads one by one, while they fit in the cap.
+			gotAdmitted := make(map[string]int)
+			for _, lqName := range []string{"capped", "uncapped"} {
+				for i := range 5 {
+					wl := utiltesting.MakeWorkload(fmt.Sprintf("%s-%d", lqName, i), "ns").
+						Queue(lqName).
+						Request(corev1.ResourceCPU, "1").
+						Obj()
+					wi := workload.NewInfo(wl)
+					wi.ClusterQueue = "cq"
+					if !cache.FitsLocalQueueQuotaCap(wi, resources.FlavorResourceQuantities{fr: 1_000}, nil) {
+						continue
+					}
+					admitted := wl.DeepCopy()
+					workload.SetQuotaReservation(admitted, utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj(), nil)
+					if err := cache.AssumeWorkload(log, admitted); err != nil {
+						t.Fatalf("Failed assuming workload: %v", err)
+					}
+					gotAdmitted[lqName]++
+				}
+			}
+			for lqName, want := range tc.wantAdmitted {
+				if got := gotAdmitted[lqName]; got != want {
+					t.Errorf("Unexpected admitted workloads in LocalQueue %q, want=%d, got=%d", lqName, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalQueueQuotaCapWithPreemptions(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	admitted := func(name, lqName string) *workload.Info {
+		return workload.NewInfo(utiltesting.MakeWorkload(name, "ns").
+			Queue(lqName).
+			Request(corev1.ResourceCPU, "1").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+			Obj())
+	}
+	capped := []*workload.Info{admitted("capped-0", "capped"), admitted("capped-1", "capped"), admitted("capped-2", "capped")}
+	uncapped := admitted("uncapped-0", "uncapped")
+	cases := map[string]struct {
+		preempted []*workload.Info
+		want      bool
+	}{
+		"no preemptions": {},
+		"preempting a workload of the same LocalQueue": {
+			preempted: []*workload.Info{capped[0]},
+			want:      true,
+		},
+		"preempting a workload of another LocalQueue": {
+			preempted: []*workload.Info{uncapped},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			for _, lqName := range []string{"capped", "uncapped"} {
+				if err := cache.AddLocalQueue(utiltesting.MakeLocalQueue(lqName, "ns").ClusterQueue("cq").Obj()); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+			}
+			if err := cache.SetLocalQueueQuotaCap(utiltesting.MakeLocalQueue("capped", "ns").ClusterQueue("cq").Obj(), resources.FlavorResourceQuantities{fr: 3_000}); err != nil {
+				t.Fatalf("Failed setting the quota cap: %v", err)
+			}
+			for _, wi := range append(capped, uncapped) {
+				if !cache.AddOrUpdateWorkload(log, wi.Obj) {
+					t.Fatalf("Failed adding workload %q", wi.Obj.Name)
+				}
+			}
+
+			wi := workload.NewInfo(utiltesting.MakeWorkload("incoming", "ns").
+				Queue("capped").
+				Request(corev1.ResourceCPU, "1").
+				Obj())
+			wi.ClusterQueue = "cq"
+			if got := cache.FitsLocalQueueQuotaCap(wi, resources.FlavorResourceQuantities{fr: 1_000}, tc.preempted); got != tc.want {
+				t.Errorf("Unexpected fit in the quota cap, want=%v, got=%v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSetLocalQueueQuotaCapErrors(t *testing.T) {
+	ctx, _ := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	if err := cache.SetLocalQueueQuotaCap(utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("missing").Obj(), nil); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+	if err := cache.SetLocalQueueQuotaCap(utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("cq").Obj(), nil); !errors.Is(err, errLocalQueueNotFound) {
+		t.Errorf("Unexpected error for a missing LocalQueue: %v", err)
+	}
+}
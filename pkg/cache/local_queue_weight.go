@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"maps"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+var (
+	errLocalQueueNotFound      = errors.New("local queue not found")
+	errInvalidLocalQueueWeight = errors.New("local queue weight must be positive")
+)
+
+// SetLocalQueueWeight sets the admission weight of the LocalQueue within
+// its ClusterQueue. LocalQueues have a weight of 1 by default. The weight
+// is dropped when the LocalQueue is removed from the cache.
+func (c *Cache) SetLocalQueueWeight(q *kueue.LocalQueue, weight int64) error {
+	if weight <= 0 {
+		return errInvalidLocalQueueWeight
+	}
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(q.Spec.ClusterQueue)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	qImpl, found := cq.localQueues[queueKey(q)]
+	if !found {
+		return errLocalQueueNotFound
+	}
+	qImpl.weight = weight
+	return nil
+}
+
+// NextLocalQueueForAdmission returns the key of the LocalQueue of the
+// ClusterQueue which should get the next admission, following a smooth
+// weighted round-robin over the LocalQueues, so that in the long run the
+// number of selections of each LocalQueue is proportional to its weight.
+// Only the LocalQueues with pending workloads, as given by the keys in
+// pending, or with workloads reserving quota take part in the round; the
+// others keep their running weight. It returns an empty string if the
+// ClusterQueue doesn't exist or has no such LocalQueues.
+func (c *Cache) NextLocalQueueForAdmission(cqName kueue.ClusterQueueReference, pending sets.Set[string]) string {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ""
+	}
+	var (
+		selected    *queue
+		totalWeight int64
+	)
+	// Iterate in a stable order so that ties are broken by key.
+	for _, k := range slices.Sorted(maps.Keys(cq.localQueues)) {
+		q := cq.localQueues[k]
+		if q.reservingWorkloads == 0 && !pending.Has(k) {
+			continue
+		}
+		w := q.admissionWeight()
+		q.currentWeight += w
+		totalWeight += w
+		if selected == nil || q.currentWeight > selected.currentWeight {
+			selected = q
+		}
+	}
+	if selected == nil {
+		return ""
+	}
+	selected.currentWeight -= totalWeight
+	return selected.key
+}
+
+func (q *queue) admissionWeight() int64 {
+	if q.weight == 0 {
+		return 1
+	}
+	return q.weight
+}
@@ -0,0 +1,162 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestNextLocalQueueForAdmission(t *testing.T) {
+	cases := map[string]struct {
+		weights map[string]int64
+		// idle are the LocalQueues without pending workloads.
+		idle      []string
+		reserving []string
+		rounds    int
+		want      map[string]int
+	}{
+		"default weights": {
+			rounds: 30,
+			want: map[string]int{
+				"ns/a": 10,
+				"ns/b": 10,
+				"ns/c": 10,
+			},
+		},
+		"weighted": {
+			weights: map[string]int64{
+				"a": 1,
+				"b": 2,
+				"c": 3,
+			},
+			rounds: 600,
+			want: map[string]int{
+				"ns/a": 100,
+				"ns/b": 200,
+				"ns/c": 300,
+			},
+		},
+		"partially weighted": {
+			weights: map[string]int64{
+				"c": 8,
+			},
+			rounds: 100,
+			want: map[string]int{
+				"ns/a": 10,
+				"ns/b": 10,
+				"ns/c": 80,
+			},
+		},
+		"idle queue skipped": {
+			weights: map[string]int64{
+				"b": 8,
+			},
+			idle:   []string{"b"},
+			rounds: 20,
+			want: map[string]int{
+				"ns/a": 10,
+				"ns/c": 10,
+			},
+		},
+		"idle queue with reserving workloads": {
+			weights: map[string]int64{
+				"b": 8,
+			},
+			idle:      []string{"a", "b"},
+			reserving: []string{"b"},
+			rounds:    90,
+			want: map[string]int{
+				"ns/b": 80,
+				"ns/c": 10,
+			},
+		},
+		"all queues idle": {
+			idle:   []string{"a", "b", "c"},
+			rounds: 3,
+			want: map[string]int{
+				"": 3,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			pending := sets.New("ns/a", "ns/b", "ns/c")
+			for _, name := range tc.idle {
+				pending.Delete("ns/" + name)
+			}
+			for _, name := range []string{"a", "b", "c"} {
+				lq := utiltesting.MakeLocalQueue(name, "ns").ClusterQueue("cq").Obj()
+				if err := cache.AddLocalQueue(lq); err != nil {
+					t.Fatalf("Failed adding LocalQueue: %v", err)
+				}
+				if w, found := tc.weights[name]; found {
+					if err := cache.SetLocalQueueWeight(lq, w); err != nil {
+						t.Fatalf("Failed setting LocalQueue weight: %v", err)
+					}
+				}
+			}
+			for _, name := range tc.reserving {
+				cache.AddOrUpdateWorkload(log, utiltesting.MakeWorkload("wl-"+name, "ns").
+					Queue(name).
+					ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).
+					Obj())
+			}
+			got := make(map[string]int)
+			for range tc.rounds {
+				got[cache.NextLocalQueueForAdmission("cq", pending)]++
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected distribution of admissions (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetLocalQueueWeight(t *testing.T) {
+	ctx, _ := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	lq := utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("cq").Obj()
+	if err := cache.SetLocalQueueWeight(lq, 1); !errors.Is(err, errLocalQueueNotFound) {
+		t.Errorf("Unexpected error for a missing LocalQueue: %v", err)
+	}
+	if err := cache.AddLocalQueue(lq); err != nil {
+		t.Fatalf("Failed adding LocalQueue: %v", err)
+	}
+	if err := cache.SetLocalQueueWeight(lq, 0); !errors.Is(err, errInvalidLocalQueueWeight) {
+		t.Errorf("Unexpected error for a zero weight: %v", err)
+	}
+	if err := cache.SetLocalQueueWeight(utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("other").Obj(), 1); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+	if got := cache.NextLocalQueueForAdmission("other", nil); got != "" {
+		t.Errorf("Unexpected LocalQueue for a missing ClusterQueue: %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+var errInvalidMaxFlavorsToTry = errors.New("max flavors to try must be positive")
+
+// SetMaxFlavorsToTry limits how many flavors of a ResourceGroup are tried
+// for a resource of a PodSet in a single flavor assignment of the
+// workloads of the ClusterQueue. With FlavorFungibility, the next
+// assignment resumes from the flavor after the last one tried. A nil
+// value removes the limit.
+func (c *Cache) SetMaxFlavorsToTry(cqName kueue.ClusterQueueReference, maxFlavors *int32) error {
+	if maxFlavors != nil && *maxFlavors < 1 {
+		return errInvalidMaxFlavorsToTry
+	}
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.maxFlavorsToTry = maxFlavors
+	return nil
+}
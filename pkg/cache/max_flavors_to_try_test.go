@@ -0,0 +1,51 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestSetMaxFlavorsToTry(t *testing.T) {
+	ctx, _ := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	if err := cache.SetMaxFlavorsToTry("missing", ptr.To[int32](2)); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+	if err := cache.SetMaxFlavorsToTry("cq", ptr.To[int32](0)); !errors.Is(err, errInvalidMaxFlavorsToTry) {
+		t.Errorf("Unexpected error for an invalid limit: %v", err)
+	}
+	if err := cache.SetMaxFlavorsToTry("cq", ptr.To[int32](2)); err != nil {
+		t.Fatalf("Failed setting the max flavors to try: %v", err)
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	if diff := cmp.Diff(ptr.To[int32](2), snapshot.ClusterQueue("cq").MaxFlavorsToTry); diff != "" {
+		t.Errorf("Unexpected MaxFlavorsToTry in the snapshot (-want,+got):\n%s", diff)
+	}
+}
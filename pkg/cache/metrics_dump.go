@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utilresource "sigs.k8s.io/kueue/pkg/util/resource"
+)
+
+// DumpMetrics returns the key gauges of the cache, for all the
+// ClusterQueues, in the Prometheus text exposition format. The series use
+// the names of the metrics exported by Kueue, but they are computed from
+// the current state of the cache, on a private registry, rather than read
+// from the global registry. This allows to snapshot the state at a point
+// in time, for debugging.
+func (c *Cache) DumpMetrics() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	reserving := newDumpGaugeVec("reserving_active_workloads", "The number of Workloads that are reserving quota, per 'cluster_queue'", "cluster_queue")
+	admitted := newDumpGaugeVec("admitted_active_workloads", "The number of admitted Workloads that are active (unsuspended and not finished), per 'cluster_queue'", "cluster_queue")
+	reservation := newDumpGaugeVec("cluster_queue_resource_reservation", "Reports the cluster_queue's total resource reservation within all the flavors", "cohort", "cluster_queue", "flavor", "resource")
+	usage := newDumpGaugeVec("cluster_queue_resource_usage", "Reports the cluster_queue's total resource usage within all the flavors", "cohort", "cluster_queue", "flavor", "resource")
+	nominal := newDumpGaugeVec("cluster_queue_nominal_quota", "Reports the cluster_queue's resource nominal quota within all the flavors", "cohort", "cluster_queue", "flavor", "resource")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(reserving, admitted, reservation, usage, nominal)
+
+	for name, cq := range c.hm.ClusterQueues() {
+		var cohortName kueue.CohortReference
+		if cq.HasParent() {
+			cohortName = cq.Parent().Name
+		}
+		reserving.WithLabelValues(string(name)).Set(float64(len(cq.Workloads)))
+		admitted.WithLabelValues(string(name)).Set(float64(cq.admittedWorkloadsCount))
+		for fr, quota := range cq.resourceNode.Quotas {
+			labels := []string{string(cohortName), string(name), string(fr.Flavor), string(fr.Resource)}
+			nominal.WithLabelValues(labels...).Set(resourceValueToFloat(fr, quota.Nominal))
+			reservation.WithLabelValues(labels...).Set(resourceValueToFloat(fr, cq.resourceNode.Usage[fr]))
+			usage.WithLabelValues(labels...).Set(resourceValueToFloat(fr, cq.AdmittedUsage[fr]))
+		}
+	}
+
+	// The gauges are consistent by construction and writing to a
+	// strings.Builder doesn't fail, so the errors can be ignored.
+	families, _ := registry.Gather()
+	var sb strings.Builder
+	for _, mf := range families {
+		_, _ = expfmt.MetricFamilyToText(&sb, mf)
+	}
+	return sb.String()
+}
+
+func newDumpGaugeVec(name, help string, labels ...string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: constants.KueueName,
+		Name:      name,
+		Help:      help,
+	}, labels)
+}
+
+func resourceValueToFloat(fr resources.FlavorResource, v int64) float64 {
+	q := resources.ResourceQuantity(fr.Resource, v)
+	return utilresource.QuantityToFloat(&q)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestDumpMetrics(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		Cohort("cohort").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	workloads := []*utiltesting.WorkloadWrapper{
+		utiltesting.MakeWorkload("admitted", "ns").
+			Request(corev1.ResourceCPU, "3").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "3").Obj()).
+			Admitted(true),
+		utiltesting.MakeWorkload("reserving", "ns").
+			Request(corev1.ResourceCPU, "1500m").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1500m").Obj()),
+	}
+	for _, wl := range workloads {
+		if !cache.AddOrUpdateWorkload(log, wl.Obj()) {
+			t.Fatal("Failed adding workload")
+		}
+	}
+
+	got := cache.DumpMetrics()
+	wantSeries := []string{
+		"# TYPE kueue_reserving_active_workloads gauge",
+		`kueue_reserving_active_workloads{cluster_queue="cq"} 2`,
+		"# TYPE kueue_admitted_active_workloads gauge",
+		`kueue_admitted_active_workloads{cluster_queue="cq"} 1`,
+		`kueue_cluster_queue_nominal_quota{cluster_queue="cq",cohort="cohort",flavor="default",resource="cpu"} 10`,
+		`kueue_cluster_queue_resource_reservation{cluster_queue="cq",cohort="cohort",flavor="default",resource="cpu"} 4.5`,
+		`kueue_cluster_queue_resource_usage{cluster_queue="cq",cohort="cohort",flavor="default",resource="cpu"} 3`,
+	}
+	for _, s := range wantSeries {
+		if !strings.Contains(got, s+"\n") {
+			t.Errorf("Missing series %q in the dump:\n%s", s, got)
+		}
+	}
+}
+
+func TestDumpMetricsEmpty(t *testing.T) {
+	cache := New(utiltesting.NewFakeClient())
+	if got := cache.DumpMetrics(); got != "" {
+		t.Errorf("Unexpected dump for an empty cache:\n%s", got)
+	}
+}
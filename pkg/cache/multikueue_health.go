@@ -0,0 +1,64 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// SetMultiKueueClustersHealthy records whether any of the worker clusters
+// of the MultiKueue AdmissionCheck is healthy. It returns the
+// ClusterQueues which became active. The health of AdmissionChecks
+// missing from the cache is ignored.
+func (c *Cache) SetMultiKueueClustersHealthy(log logr.Logger, acName string, healthy bool) sets.Set[kueue.ClusterQueueReference] {
+	c.Lock()
+	defer c.Unlock()
+	ac, found := c.admissionChecks[acName]
+	if !found || ac.NoHealthyClusters == !healthy {
+		return nil
+	}
+	ac.NoHealthyClusters = !healthy
+	c.admissionChecks[acName] = ac
+	return c.updateClusterQueues(log)
+}
+
+// SetStopWithoutHealthyClusters sets whether the ClusterQueue stops
+// admitting workloads while none of the worker clusters of its MultiKueue
+// AdmissionCheck is healthy, rather than reserving quota for workloads
+// which can't be dispatched. The ClusterQueue is then pending with the
+// MultiKueueNoHealthyClusters reason.
+func (c *Cache) SetStopWithoutHealthyClusters(log logr.Logger, cqName kueue.ClusterQueueReference, stop bool) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.stopWithoutHealthyClusters = stop
+	cq.updateQueueStatus(log)
+	return nil
+}
+
+// hasNoHealthyClusters indicates whether the ClusterQueue stops admitting
+// workloads because none of the worker clusters of its MultiKueue
+// AdmissionCheck is healthy.
+func (c *clusterQueue) hasNoHealthyClusters() bool {
+	return c.stopWithoutHealthyClusters && len(c.multiKueueChecksWithoutHealthyClusters) > 0
+}
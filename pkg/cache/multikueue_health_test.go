@@ -0,0 +1,110 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestMultiKueueClustersHealth(t *testing.T) {
+	type step struct {
+		healthy       bool
+		wantActivated sets.Set[kueue.ClusterQueueReference]
+		wantReason    string
+	}
+	cases := map[string]struct {
+		stop  bool
+		steps []step
+	}{
+		"stop without healthy clusters": {
+			stop: true,
+			steps: []step{
+				{healthy: false, wantReason: kueue.ClusterQueueActiveReasonMultiKueueNoHealthyClusters},
+				{
+					healthy:       true,
+					wantActivated: sets.New[kueue.ClusterQueueReference]("cq"),
+					wantReason:    kueue.ClusterQueueActiveReasonReady,
+				},
+				{healthy: false, wantReason: kueue.ClusterQueueActiveReasonMultiKueueNoHealthyClusters},
+			},
+		},
+		"keep admitting without healthy clusters": {
+			steps: []step{
+				{healthy: false, wantReason: kueue.ClusterQueueActiveReasonReady},
+				{healthy: true, wantReason: kueue.ClusterQueueActiveReasonReady},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateAdmissionCheck(log, utiltesting.MakeAdmissionCheck("mk").
+				ControllerName(kueue.MultiKueueControllerName).
+				Active(metav1.ConditionTrue).
+				Obj())
+			if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").AdmissionChecks("mk").Obj()); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if err := cache.SetStopWithoutHealthyClusters(log, "cq", tc.stop); err != nil {
+				t.Fatalf("Failed setting the stop without healthy clusters: %v", err)
+			}
+			for i, s := range tc.steps {
+				activated := cache.SetMultiKueueClustersHealthy(log, "mk", s.healthy)
+				if diff := cmp.Diff(s.wantActivated, activated, cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("Step %d: unexpected activated ClusterQueues (-want,+got):\n%s", i, diff)
+				}
+				if _, reason, _ := cache.ClusterQueueReadiness("cq"); reason != s.wantReason {
+					t.Errorf("Step %d: unexpected readiness reason, want=%s, got=%s", i, s.wantReason, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiKueueClustersHealthKeptOnAdmissionCheckUpdate(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	ac := utiltesting.MakeAdmissionCheck("mk").
+		ControllerName(kueue.MultiKueueControllerName).
+		Active(metav1.ConditionTrue).
+		Obj()
+	cache.AddOrUpdateAdmissionCheck(log, ac)
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").AdmissionChecks("mk").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	if err := cache.SetStopWithoutHealthyClusters(log, "missing", true); !errors.Is(err, ErrCqNotFound) {
+		t.Errorf("Unexpected error for a missing ClusterQueue: %v", err)
+	}
+	if err := cache.SetStopWithoutHealthyClusters(log, "cq", true); err != nil {
+		t.Fatalf("Failed setting the stop without healthy clusters: %v", err)
+	}
+	cache.SetMultiKueueClustersHealthy(log, "mk", false)
+	cache.AddOrUpdateAdmissionCheck(log, ac)
+	if cache.ClusterQueueActive("cq") {
+		t.Error("ClusterQueue became active after updating the AdmissionCheck")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/hierarchy"
+)
+
+// NamespaceSelectorConflict is a pair of ClusterQueues of the same Cohort
+// tree whose NamespaceSelectors overlap.
+type NamespaceSelectorConflict struct {
+	// ClusterQueues holds the names of the two ClusterQueues, sorted.
+	ClusterQueues [2]kueue.ClusterQueueReference
+	// Namespaces holds the sorted names of the sample namespaces selected
+	// by both ClusterQueues. It is empty when both ClusterQueues select
+	// all the namespaces.
+	Namespaces []string
+}
+
+// Warning returns a human readable warning about the conflict.
+func (c NamespaceSelectorConflict) Warning() string {
+	if len(c.Namespaces) == 0 {
+		return fmt.Sprintf("ClusterQueues %q and %q in the same Cohort both select all the namespaces", c.ClusterQueues[0], c.ClusterQueues[1])
+	}
+	return fmt.Sprintf("ClusterQueues %q and %q in the same Cohort both select the namespaces %s", c.ClusterQueues[0], c.ClusterQueues[1], strings.Join(c.Namespaces, ", "))
+}
+
+// NamespaceSelectorConflicts returns the pairs of ClusterQueues, in the
+// Cohort tree rooted at the given Cohort, whose NamespaceSelectors
+// overlap, which makes the routing of the workloads to the ClusterQueues
+// ambiguous. The overlap is evaluated on the sample namespaces. Besides,
+// as a heuristic not depending on the sample, two ClusterQueues selecting
+// all the namespaces always conflict. The conflicts are sorted by the
+// names of the ClusterQueues. It returns nil if the Cohort doesn't exist
+// or has a cycle.
+func (c *Cache) NamespaceSelectorConflicts(cohortName kueue.CohortReference, namespaces []corev1.Namespace) []NamespaceSelectorConflict {
+	c.RLock()
+	defer c.RUnlock()
+
+	cohort := c.hm.Cohort(cohortName)
+	if cohort == nil || hierarchy.HasCycle(cohort) {
+		return nil
+	}
+	cqs := cohort.subtreeClusterQueues(nil)
+	slices.SortFunc(cqs, func(a, b *clusterQueue) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	var conflicts []NamespaceSelectorConflict
+	for i, a := range cqs {
+		for _, b := range cqs[i+1:] {
+			var shared []string
+			for _, ns := range namespaces {
+				nsLabels := labels.Set(ns.Labels)
+				if a.NamespaceSelector.Matches(nsLabels) && b.NamespaceSelector.Matches(nsLabels) {
+					shared = append(shared, ns.Name)
+				}
+			}
+			if len(shared) > 0 || (a.NamespaceSelector.Empty() && b.NamespaceSelector.Empty()) {
+				slices.Sort(shared)
+				conflicts = append(conflicts, NamespaceSelectorConflict{
+					ClusterQueues: [2]kueue.ClusterQueueReference{a.Name, b.Name},
+					Namespaces:    shared,
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// subtreeClusterQueues appends the ClusterQueues in the subtree of the
+// Cohort to cqs. It expects that no cycles exist in the Cohort graph.
+func (c *cohort) subtreeClusterQueues(cqs []*clusterQueue) []*clusterQueue {
+	cqs = append(cqs, c.ChildCQs()...)
+	for _, child := range c.ChildCohorts() {
+		cqs = child.subtreeClusterQueues(cqs)
+	}
+	return cqs
+}
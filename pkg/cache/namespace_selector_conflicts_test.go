@@ -0,0 +1,119 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestNamespaceSelectorConflicts(t *testing.T) {
+	teamSelector := func(team string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{"team": team}}
+	}
+	envSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "env",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"prod"},
+		}},
+	}
+	namespaces := []corev1.Namespace{
+		*utiltesting.MakeNamespaceWrapper("a-prod").Label("team", "a").Label("env", "prod").Obj(),
+		*utiltesting.MakeNamespaceWrapper("a-dev").Label("team", "a").Label("env", "dev").Obj(),
+		*utiltesting.MakeNamespaceWrapper("b-prod").Label("team", "b").Label("env", "prod").Obj(),
+	}
+
+	cases := map[string]struct {
+		clusterQueues []*kueue.ClusterQueue
+		namespaces    []corev1.Namespace
+		want          []NamespaceSelectorConflict
+		wantWarnings  []string
+	}{
+		"disjoint selectors": {
+			clusterQueues: []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("team-a").Cohort("cohort").NamespaceSelector(teamSelector("a")).Obj(),
+				utiltesting.MakeClusterQueue("team-b").Cohort("cohort").NamespaceSelector(teamSelector("b")).Obj(),
+			},
+			namespaces: namespaces,
+		},
+		"overlapping selectors": {
+			clusterQueues: []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("team-a").Cohort("cohort").NamespaceSelector(teamSelector("a")).Obj(),
+				utiltesting.MakeClusterQueue("team-b").Cohort("cohort").NamespaceSelector(teamSelector("b")).Obj(),
+				utiltesting.MakeClusterQueue("prod").Cohort("cohort").NamespaceSelector(envSelector).Obj(),
+			},
+			namespaces: namespaces,
+			want: []NamespaceSelectorConflict{
+				{ClusterQueues: [2]kueue.ClusterQueueReference{"prod", "team-a"}, Namespaces: []string{"a-prod"}},
+				{ClusterQueues: [2]kueue.ClusterQueueReference{"prod", "team-b"}, Namespaces: []string{"b-prod"}},
+			},
+			wantWarnings: []string{
+				`ClusterQueues "prod" and "team-a" in the same Cohort both select the namespaces a-prod`,
+				`ClusterQueues "prod" and "team-b" in the same Cohort both select the namespaces b-prod`,
+			},
+		},
+		"selectors of ClusterQueues in other Cohorts are ignored": {
+			clusterQueues: []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("team-a").Cohort("cohort").NamespaceSelector(teamSelector("a")).Obj(),
+				utiltesting.MakeClusterQueue("prod").Cohort("other").NamespaceSelector(envSelector).Obj(),
+			},
+			namespaces: namespaces,
+		},
+		"selecting all the namespaces without a sample": {
+			clusterQueues: []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("all-1").Cohort("cohort").Obj(),
+				utiltesting.MakeClusterQueue("all-2").Cohort("cohort").Obj(),
+				utiltesting.MakeClusterQueue("team-a").Cohort("cohort").NamespaceSelector(teamSelector("a")).Obj(),
+			},
+			want: []NamespaceSelectorConflict{
+				{ClusterQueues: [2]kueue.ClusterQueueReference{"all-1", "all-2"}},
+			},
+			wantWarnings: []string{
+				`ClusterQueues "all-1" and "all-2" in the same Cohort both select all the namespaces`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			for _, cq := range tc.clusterQueues {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			got := cache.NamespaceSelectorConflicts("cohort", tc.namespaces)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected conflicts (-want,+got):\n%s", diff)
+			}
+			var gotWarnings []string
+			for _, c := range got {
+				gotWarnings = append(gotWarnings, c.Warning())
+			}
+			if diff := cmp.Diff(tc.wantWarnings, gotWarnings); diff != "" {
+				t.Errorf("Unexpected warnings (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+// OrphanedFlavorStatusPolicy defines how the usage of the workloads which
+// were assigned a ResourceFlavor which no longer exists is reported in the
+// status of the ClusterQueues. It doesn't change the quota accounting: the
+// workloads keep being accounted under their assigned flavors.
+type OrphanedFlavorStatusPolicy string
+
+const (
+	// OrphanedFlavorStatusKeep reports the orphaned usage under the reserved
+	// kueue.OrphanedResourceFlavorName, instead of the deleted flavors.
+	OrphanedFlavorStatusKeep OrphanedFlavorStatusPolicy = "Keep"
+	// OrphanedFlavorStatusDrop doesn't report the orphaned usage.
+	OrphanedFlavorStatusDrop OrphanedFlavorStatusPolicy = "Drop"
+)
+
+// WithOrphanedFlavorStatusPolicy sets how the usage of deleted flavors is
+// reported in the status of the ClusterQueues. By default, it is reported
+// under the deleted flavors.
+func WithOrphanedFlavorStatusPolicy(policy OrphanedFlavorStatusPolicy) Option {
+	return func(o *options) {
+		o.orphanedFlavorStatusPolicy = policy
+	}
+}
+
+// orphanedFlavorUsage applies the OrphanedFlavorStatusPolicy to the usage.
+// It returns the usage of the existing flavors and the usage to report
+// under kueue.OrphanedResourceFlavorName, by resource. The workloads keep being
+// accounted under their assigned flavors, so that their usage is removed
+// correctly once they release their quota, or once the flavor is created
+// again.
+func (c *Cache) orphanedFlavorUsage(frq resources.FlavorResourceQuantities) (resources.FlavorResourceQuantities, map[corev1.ResourceName]int64) {
+	if c.orphanedFlavorStatusPolicy == "" {
+		return frq, nil
+	}
+	var orphaned map[corev1.ResourceName]int64
+	usage := make(resources.FlavorResourceQuantities, len(frq))
+	for fr, q := range frq {
+		if _, found := c.resourceFlavors[fr.Flavor]; found {
+			usage[fr] = q
+			continue
+		}
+		if c.orphanedFlavorStatusPolicy == OrphanedFlavorStatusKeep && q != 0 {
+			if orphaned == nil {
+				orphaned = make(map[corev1.ResourceName]int64)
+			}
+			orphaned[fr.Resource] += q
+		}
+	}
+	return usage, orphaned
+}
+
+// getUsageWithOrphaned returns the usage of the ClusterQueue, applying the
+// OrphanedFlavorStatusPolicy.
+func (c *Cache) getUsageWithOrphaned(frq resources.FlavorResourceQuantities, cq *clusterQueue) []kueue.FlavorUsage {
+	usage, orphaned := c.orphanedFlavorUsage(frq)
+	out := getUsage(usage, cq)
+	if len(orphaned) == 0 {
+		return out
+	}
+	orphanedUsage := kueue.FlavorUsage{
+		Name:      kueue.OrphanedResourceFlavorName,
+		Resources: make([]kueue.ResourceUsage, 0, len(orphaned)),
+	}
+	for rName, q := range orphaned {
+		orphanedUsage.Resources = append(orphanedUsage.Resources, kueue.ResourceUsage{
+			Name:  rName,
+			Total: resources.ResourceQuantity(rName, q),
+		})
+	}
+	sort.Slice(orphanedUsage.Resources, func(i, j int) bool {
+		return orphanedUsage.Resources[i].Name < orphanedUsage.Resources[j].Name
+	})
+	return append(out, orphanedUsage)
+}
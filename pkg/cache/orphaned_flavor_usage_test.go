@@ -0,0 +1,117 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestOrphanedFlavorStatusPolicy(t *testing.T) {
+	usage := func(on, spot string) []kueue.FlavorUsage {
+		return []kueue.FlavorUsage{
+			{
+				Name:      "on-demand",
+				Resources: []kueue.ResourceUsage{{Name: corev1.ResourceCPU, Total: resource.MustParse(on)}},
+			},
+			{
+				Name:      "spot",
+				Resources: []kueue.ResourceUsage{{Name: corev1.ResourceCPU, Total: resource.MustParse(spot)}},
+			},
+		}
+	}
+	cases := map[string]struct {
+		opts []Option
+		want []kueue.FlavorUsage
+	}{
+		"default reports the usage under the deleted flavor": {
+			want: usage("1", "3"),
+		},
+		"keep reports the usage under the reserved flavor name": {
+			opts: []Option{WithOrphanedFlavorStatusPolicy(OrphanedFlavorStatusKeep)},
+			want: append(usage("1", "0"), kueue.FlavorUsage{
+				Name:      kueue.OrphanedResourceFlavorName,
+				Resources: []kueue.ResourceUsage{{Name: corev1.ResourceCPU, Total: resource.MustParse("3")}},
+			}),
+		},
+		"drop doesn't report the usage": {
+			opts: []Option{WithOrphanedFlavorStatusPolicy(OrphanedFlavorStatusDrop)},
+			want: usage("1", "0"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), tc.opts...)
+			spot := utiltesting.MakeResourceFlavor("spot").Obj()
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+			cache.AddOrUpdateResourceFlavor(log, spot)
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+					*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4").Obj(),
+				).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			for name, flavor := range map[string]string{"wl-on-demand": "on-demand", "wl-spot": "spot"} {
+				cpu := "1"
+				if flavor == "spot" {
+					cpu = "3"
+				}
+				wl := utiltesting.MakeWorkload(name, "ns").
+					Request(corev1.ResourceCPU, cpu).
+					ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, kueue.ResourceFlavorReference(flavor), cpu).Obj()).
+					Admitted(true).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %q", name)
+				}
+			}
+			cache.DeleteResourceFlavor(log, spot)
+
+			stats, err := cache.Usage(cq)
+			if err != nil {
+				t.Fatalf("Failed getting usage: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, stats.ReservedResources); diff != "" {
+				t.Errorf("Unexpected reserved resources (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, stats.AdmittedResources); diff != "" {
+				t.Errorf("Unexpected admitted resources (-want,+got):\n%s", diff)
+			}
+
+			// Once the flavor is created again, the usage is reported
+			// under it regardless of the policy.
+			cache.AddOrUpdateResourceFlavor(log, spot)
+			stats, err = cache.Usage(cq)
+			if err != nil {
+				t.Fatalf("Failed getting usage: %v", err)
+			}
+			if diff := cmp.Diff(usage("1", "3"), stats.ReservedResources); diff != "" {
+				t.Errorf("Unexpected reserved resources after creating the flavor again (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// RecordPreemptions records that the victims were preempted to admit a
+// workload of the ClusterQueue. The preemptions are attributed to the
+// ClusterQueue once the victims release their quota reservation, and are
+// charged to its preemption budget right away. Victims already recorded,
+// whose preemption is still ongoing, are not charged again.
+func (c *Cache) RecordPreemptions(preemptorCQ kueue.ClusterQueueReference, victims []*workload.Info) {
+	c.Lock()
+	defer c.Unlock()
+	preemptor := c.hm.ClusterQueue(preemptorCQ)
+	for _, victim := range victims {
+		k := c.WorkloadKey(victim.Obj)
+		cq := c.hm.ClusterQueue(victim.ClusterQueue)
+		if cq == nil || cq.Workloads[k] == nil {
+			continue
+		}
+		_, issued := c.issuedPreemptions[k]
+		c.issuedPreemptions[k] = preemptorCQ
+		if !issued && preemptor != nil {
+			preemptor.spendPreemptionBudget(c.clock.Now())
+		}
+	}
+}
+
+// PreemptionsCausedBy returns the number of workloads which released their
+// quota reservation after being preempted to admit workloads of the
+// ClusterQueue.
+func (c *Cache) PreemptionsCausedBy(cqName kueue.ClusterQueueReference) int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.preemptionsCausedBy[cqName]
+}
+
+// attributePreemption is called when the workload releases its quota
+// reservation. If the workload was preempted, the preemption is
+// attributed to the ClusterQueue of the preemptor.
+func (c *Cache) attributePreemption(wlKey string) {
+	preemptorCQ, found := c.issuedPreemptions[wlKey]
+	if !found {
+		return
+	}
+	delete(c.issuedPreemptions, wlKey)
+	if c.hm.ClusterQueue(preemptorCQ) != nil {
+		c.preemptionsCausedBy[preemptorCQ]++
+	}
+}
+
+// forgetPreemptionsCausedBy drops the preemptions attributed, or to be
+// attributed, to the ClusterQueue.
+func (c *Cache) forgetPreemptionsCausedBy(cqName kueue.ClusterQueueReference) {
+	delete(c.preemptionsCausedBy, cqName)
+	for k, preemptorCQ := range c.issuedPreemptions {
+		if preemptorCQ == cqName {
+			delete(c.issuedPreemptions, k)
+		}
+	}
+}
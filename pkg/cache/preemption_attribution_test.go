@@ -0,0 +1,104 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestPreemptionsCausedBy(t *testing.T) {
+	victim := utiltesting.MakeWorkload("victim", "ns").
+		Request(corev1.ResourceCPU, "4").
+		ReserveQuota(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "default", "4").Obj()).
+		Admitted(true).
+		Obj()
+	cases := map[string]struct {
+		// release releases the quota reservation of the victim.
+		release func(t *testing.T, cache *Cache)
+		want    map[kueue.ClusterQueueReference]int
+	}{
+		"victim evicted": {
+			release: func(t *testing.T, cache *Cache) {
+				_, log := utiltesting.ContextWithLog(t)
+				evicted := victim.DeepCopy()
+				workload.UnsetQuotaReservationWithCondition(evicted, "Preempted", "preempted", evicted.CreationTimestamp.Time)
+				if err := cache.UpdateWorkload(log, victim, evicted); err != nil {
+					t.Fatalf("Failed updating the victim: %v", err)
+				}
+			},
+			want: map[kueue.ClusterQueueReference]int{"borrower": 1, "lender": 0, "other": 0},
+		},
+		"victim deleted": {
+			release: func(t *testing.T, cache *Cache) {
+				_, log := utiltesting.ContextWithLog(t)
+				if err := cache.DeleteWorkload(log, victim); err != nil {
+					t.Fatalf("Failed deleting the victim: %v", err)
+				}
+			},
+			want: map[kueue.ClusterQueueReference]int{"borrower": 1, "lender": 0, "other": 0},
+		},
+		"victim still holding its quota": {
+			release: func(*testing.T, *Cache) {},
+			want:    map[kueue.ClusterQueueReference]int{"borrower": 0, "lender": 0, "other": 0},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			for _, name := range []string{"lender", "borrower", "other"} {
+				cq := utiltesting.MakeClusterQueue(name).
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj()
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			if !cache.AddOrUpdateWorkload(log, victim) {
+				t.Fatal("Failed adding the victim")
+			}
+			victimInfo := workload.NewInfo(victim)
+			victimInfo.ClusterQueue = "lender"
+			unknown := workload.NewInfo(utiltesting.MakeWorkload("unknown", "ns").Obj())
+			unknown.ClusterQueue = "lender"
+			cache.RecordPreemptions("borrower", []*workload.Info{victimInfo, unknown})
+
+			tc.release(t, cache)
+			got := make(map[kueue.ClusterQueueReference]int, len(tc.want))
+			for cqName := range tc.want {
+				got[cqName] = cache.PreemptionsCausedBy(cqName)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected preemptions caused (-want,+got):\n%s", diff)
+			}
+
+			cache.DeleteClusterQueue(utiltesting.MakeClusterQueue("borrower").Obj())
+			if got := cache.PreemptionsCausedBy("borrower"); got != 0 {
+				t.Errorf("Unexpected preemptions caused by a deleted ClusterQueue: %d", got)
+			}
+		})
+	}
+}
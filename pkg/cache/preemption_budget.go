@@ -0,0 +1,94 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"time"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+var errInvalidPreemptionBudget = errors.New("preemption budget must have a non-negative number of preemptions and a positive window")
+
+// PreemptionBudget limits how many workloads can be preempted to admit the
+// workloads of a ClusterQueue within a sliding time window.
+type PreemptionBudget struct {
+	// Preemptions is the maximum number of workloads preempted within
+	// the Window.
+	Preemptions int
+	// Window is the period over which the preemptions are counted.
+	Window time.Duration
+}
+
+// SetPreemptionBudget sets the preemption budget of the ClusterQueue. The
+// budget refills as the preemptions get older than its window. A nil
+// budget removes the limit.
+func (c *Cache) SetPreemptionBudget(cqName kueue.ClusterQueueReference, budget *PreemptionBudget) error {
+	if budget != nil && (budget.Preemptions < 0 || budget.Window <= 0) {
+		return errInvalidPreemptionBudget
+	}
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.preemptionBudget = budget
+	if budget == nil {
+		cq.budgetedPreemptions = nil
+	}
+	return nil
+}
+
+// PreemptionBudgetAllows indicates whether the preemption budget of the
+// ClusterQueue allows preempting the given number of workloads to admit
+// one of its workloads. It is always true for a ClusterQueue without a
+// budget.
+func (c *Cache) PreemptionBudgetAllows(cqName kueue.ClusterQueueReference, preemptions int) bool {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil || cq.preemptionBudget == nil {
+		return true
+	}
+	cq.refillPreemptionBudget(c.clock.Now())
+	return len(cq.budgetedPreemptions)+preemptions <= cq.preemptionBudget.Preemptions
+}
+
+// spendPreemptionBudget charges the preemption of a workload to the
+// budget of the ClusterQueue, if it has one.
+func (c *clusterQueue) spendPreemptionBudget(now time.Time) {
+	if c.preemptionBudget == nil {
+		return
+	}
+	c.refillPreemptionBudget(now)
+	c.budgetedPreemptions = append(c.budgetedPreemptions, now)
+}
+
+// refillPreemptionBudget drops the preemptions older than the window of
+// the budget.
+func (c *clusterQueue) refillPreemptionBudget(now time.Time) {
+	expired := 0
+	for _, t := range c.budgetedPreemptions {
+		if now.Sub(t) < c.preemptionBudget.Window {
+			break
+		}
+		expired++
+	}
+	c.budgetedPreemptions = c.budgetedPreemptions[expired:]
+}
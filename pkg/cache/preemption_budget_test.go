@@ -0,0 +1,147 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestPreemptionBudget(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	cache := New(utiltesting.NewFakeClient(), WithClock(t, fakeClock))
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("other").
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	var victims []*workload.Info
+	for _, name := range []string{"a", "b", "c"} {
+		wl := utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, "2").
+			ReserveQuota(utiltesting.MakeAdmission("other").Assignment(corev1.ResourceCPU, "default", "2").Obj()).
+			Obj()
+		if !cache.AddOrUpdateWorkload(log, wl) {
+			t.Fatalf("Failed adding workload %s", name)
+		}
+		victims = append(victims, workload.NewInfo(wl))
+	}
+
+	if !cache.PreemptionBudgetAllows("cq", 3) {
+		t.Error("Preemptions blocked without a budget")
+	}
+	if err := cache.SetPreemptionBudget("cq", &PreemptionBudget{Preemptions: 2, Window: time.Minute}); err != nil {
+		t.Fatalf("Failed setting the preemption budget: %v", err)
+	}
+	if cache.PreemptionBudgetAllows("cq", 3) {
+		t.Error("Preemptions beyond the budget allowed")
+	}
+	if !cache.PreemptionBudgetAllows("cq", 2) {
+		t.Error("Preemptions within the budget blocked")
+	}
+
+	cache.RecordPreemptions("cq", victims[:1])
+	// The preemption of the victim is still ongoing in the next cycle.
+	cache.RecordPreemptions("cq", victims[:1])
+	if !cache.PreemptionBudgetAllows("cq", 1) {
+		t.Error("Ongoing preemption charged twice to the budget")
+	}
+	fakeClock.Step(30 * time.Second)
+	cache.RecordPreemptions("cq", victims[1:2])
+	if cache.PreemptionBudgetAllows("cq", 1) {
+		t.Error("Preemption allowed after the budget was spent")
+	}
+	if !cache.PreemptionBudgetAllows("other", 1) {
+		t.Error("Preemption blocked for a ClusterQueue without a budget")
+	}
+
+	fakeClock.Step(30 * time.Second)
+	if !cache.PreemptionBudgetAllows("cq", 1) {
+		t.Error("Preemption blocked after the oldest preemption left the window")
+	}
+	if cache.PreemptionBudgetAllows("cq", 2) {
+		t.Error("Preemptions allowed beyond the partially refilled budget")
+	}
+
+	fakeClock.Step(30 * time.Second)
+	if !cache.PreemptionBudgetAllows("cq", 2) {
+		t.Error("Preemptions blocked after the budget refilled")
+	}
+
+	cache.RecordPreemptions("cq", victims[2:])
+	if err := cache.SetPreemptionBudget("cq", nil); err != nil {
+		t.Fatalf("Failed removing the preemption budget: %v", err)
+	}
+	if !cache.PreemptionBudgetAllows("cq", 3) {
+		t.Error("Preemptions blocked after removing the budget")
+	}
+}
+
+func TestSetPreemptionBudgetErrors(t *testing.T) {
+	ctx, _ := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	if err := cache.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	cases := map[string]struct {
+		cq      kueue.ClusterQueueReference
+		budget  *PreemptionBudget
+		wantErr error
+	}{
+		"negative preemptions": {
+			cq:      "cq",
+			budget:  &PreemptionBudget{Preemptions: -1, Window: time.Minute},
+			wantErr: errInvalidPreemptionBudget,
+		},
+		"no window": {
+			cq:      "cq",
+			budget:  &PreemptionBudget{Preemptions: 1},
+			wantErr: errInvalidPreemptionBudget,
+		},
+		"ClusterQueue not found": {
+			cq:      "missing",
+			budget:  &PreemptionBudget{Preemptions: 1, Window: time.Minute},
+			wantErr: ErrCqNotFound,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := cache.SetPreemptionBudget(tc.cq, tc.budget); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Unexpected error, want=%v, got=%v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+var (
+	errWorkloadAlreadyPreempting = errors.New("workload is already being preempted by another workload")
+	errWorkloadNotInCache        = errors.New("workload not found in the cache")
+)
+
+// preemptionGrace tracks a workload selected for preemption which keeps
+// holding its quota until its eviction completes.
+type preemptionGrace struct {
+	preemptor    string
+	clusterQueue kueue.ClusterQueueReference
+}
+
+// MarkPreempting records that the victims were selected for preemption
+// by the preemptor. During the grace period, until their eviction
+// completes, the victims keep counting against the quota of their
+// ClusterQueues. Once a victim releases its quota reservation, the quota
+// it frees stays earmarked for the preemptor, so that no other workload
+// can be admitted using it, until the preemptor reserves quota or the
+// preemption is cancelled.
+//
+// It returns an error, without recording anything, if any victim is not
+// in the cache or is already being preempted by a different workload.
+func (c *Cache) MarkPreempting(preemptor *workload.Info, victims []*workload.Info) error {
+	c.Lock()
+	defer c.Unlock()
+
+	preemptorKey := c.WorkloadKey(preemptor.Obj)
+	for _, victim := range victims {
+		k := c.WorkloadKey(victim.Obj)
+		if grace, found := c.preemptingWorkloads[k]; found && grace.preemptor != preemptorKey {
+			return fmt.Errorf("%w: %q is preempted by %q", errWorkloadAlreadyPreempting, k, grace.preemptor)
+		}
+		cq := c.hm.ClusterQueue(victim.ClusterQueue)
+		if cq == nil || cq.Workloads[k] == nil {
+			return fmt.Errorf("%w: %q", errWorkloadNotInCache, k)
+		}
+	}
+	for _, victim := range victims {
+		c.preemptingWorkloads[c.WorkloadKey(victim.Obj)] = preemptionGrace{
+			preemptor:    preemptorKey,
+			clusterQueue: victim.ClusterQueue,
+		}
+	}
+	return nil
+}
+
+// IsPreempting indicates whether the workload was selected for preemption
+// and is still holding its quota during the grace period.
+func (c *Cache) IsPreempting(wlKey string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	_, found := c.preemptingWorkloads[wlKey]
+	return found
+}
+
+// CancelPreemption drops the grace tracking of the victims of the
+// preemptor, and releases the quota earmarked for it.
+func (c *Cache) CancelPreemption(log logr.Logger, preemptorKey string) {
+	c.Lock()
+	defer c.Unlock()
+	c.releaseEarmarks(log, preemptorKey)
+}
+
+// completePreemption is called when the workload releases its quota
+// reservation. If the workload was being preempted, the quota it was
+// using is earmarked for its preemptor.
+func (c *Cache) completePreemption(log logr.Logger, wlKey string, usage resources.FlavorResourceQuantities) {
+	c.attributePreemption(wlKey)
+	grace, found := c.preemptingWorkloads[wlKey]
+	if !found {
+		return
+	}
+	delete(c.preemptingWorkloads, wlKey)
+	cq := c.hm.ClusterQueue(grace.clusterQueue)
+	if cq == nil {
+		return
+	}
+	log.V(3).Info("Earmarking quota released by preempted workload", "workload", wlKey, "preemptor", grace.preemptor, "clusterQueue", cq.Name)
+	cq.addEarmark(grace.preemptor, usage)
+	if c.earmarkedClusterQueues[grace.preemptor] == nil {
+		c.earmarkedClusterQueues[grace.preemptor] = sets.New[kueue.ClusterQueueReference]()
+	}
+	c.earmarkedClusterQueues[grace.preemptor].Insert(cq.Name)
+}
+
+// releaseEarmarks releases the quota earmarked for the preemptor, and
+// stops tracking its victims which are still in the grace period.
+func (c *Cache) releaseEarmarks(log logr.Logger, preemptorKey string) {
+	for k, grace := range c.preemptingWorkloads {
+		if grace.preemptor == preemptorKey {
+			delete(c.preemptingWorkloads, k)
+		}
+	}
+	for cqName := range c.earmarkedClusterQueues[preemptorKey] {
+		if cq := c.hm.ClusterQueue(cqName); cq != nil {
+			log.V(3).Info("Releasing quota earmarked for preemptor", "preemptor", preemptorKey, "clusterQueue", cqName)
+			cq.removeEarmark(preemptorKey)
+		}
+	}
+	delete(c.earmarkedClusterQueues, preemptorKey)
+}
+
+// preemptingIn returns the keys of the workloads in the ClusterQueue
+// which are in their preemption grace period, or nil if there are none.
+func (c *Cache) preemptingIn(cqName kueue.ClusterQueueReference) sets.Set[string] {
+	var preempting sets.Set[string]
+	for k, grace := range c.preemptingWorkloads {
+		if grace.clusterQueue == cqName {
+			if preempting == nil {
+				preempting = sets.New[string]()
+			}
+			preempting.Insert(k)
+		}
+	}
+	return preempting
+}
+
+func (c *clusterQueue) addEarmark(preemptorKey string, usage resources.FlavorResourceQuantities) {
+	if c.earmarks == nil {
+		c.earmarks = make(map[string]resources.FlavorResourceQuantities)
+	}
+	if c.earmarks[preemptorKey] == nil {
+		c.earmarks[preemptorKey] = make(resources.FlavorResourceQuantities, len(usage))
+	}
+	for fr, q := range usage {
+		addUsage(c, fr, q)
+	}
+	updateFlavorUsage(usage, c.earmarks[preemptorKey], 1)
+}
+
+func (c *clusterQueue) removeEarmark(preemptorKey string) {
+	for fr, q := range c.earmarks[preemptorKey] {
+		removeUsage(c, fr, q)
+	}
+	delete(c.earmarks, preemptorKey)
+}
+
+// SimulateEarmarkRelease modifies the snapshot by removing the usage
+// earmarked for the preemptor, so that the preemptor can use the quota
+// freed by its victims. It returns a function which can be used to
+// restore the usage.
+func (s *Snapshot) SimulateEarmarkRelease(preemptorKey string) func() {
+	var earmarked []*ClusterQueueSnapshot
+	for _, cq := range s.ClusterQueues() {
+		if usage, found := cq.Earmarks[preemptorKey]; found {
+			cq.RemoveUsage(workload.Usage{Quota: usage})
+			earmarked = append(earmarked, cq)
+		}
+	}
+	return func() {
+		for _, cq := range earmarked {
+			cq.AddUsage(workload.Usage{Quota: cq.Earmarks[preemptorKey]})
+		}
+	}
+}
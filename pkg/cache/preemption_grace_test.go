@@ -0,0 +1,153 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestPreemptionGrace(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()
+	admission := utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "4").Obj()
+	victim := utiltesting.MakeWorkload("victim", "ns").
+		Request(corev1.ResourceCPU, "4").
+		ReserveQuota(admission).
+		Admitted(true).
+		Obj()
+	preemptor := utiltesting.MakeWorkload("preemptor", "ns").
+		Request(corev1.ResourceCPU, "4").
+		Obj()
+	other := utiltesting.MakeWorkload("other", "ns").
+		Request(corev1.ResourceCPU, "4").
+		Obj()
+	victimKey := workload.Key(victim)
+	preemptorKey := workload.Key(preemptor)
+
+	cases := map[string]struct {
+		// finish is applied once the victim is deleted.
+		finish    func(t *testing.T, cache *Cache)
+		wantUsage int64
+	}{
+		"preemptor reserves quota": {
+			finish: func(t *testing.T, cache *Cache) {
+				_, log := utiltesting.ContextWithLog(t)
+				wl := preemptor.DeepCopy()
+				workload.SetQuotaReservation(wl, admission, nil)
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatal("Failed adding the preemptor")
+				}
+			},
+			wantUsage: 4_000,
+		},
+		"preemptor deleted": {
+			finish: func(t *testing.T, cache *Cache) {
+				_, log := utiltesting.ContextWithLog(t)
+				if err := cache.DeleteWorkload(log, preemptor); !errors.Is(err, ErrCqNotFound) {
+					t.Errorf("Unexpected error deleting the preemptor: %v", err)
+				}
+			},
+			wantUsage: 0,
+		},
+		"preemption cancelled": {
+			finish: func(t *testing.T, cache *Cache) {
+				_, log := utiltesting.ContextWithLog(t)
+				cache.CancelPreemption(log, preemptorKey)
+			},
+			wantUsage: 0,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			if !cache.AddOrUpdateWorkload(log, victim) {
+				t.Fatal("Failed adding the victim")
+			}
+			victimInfo := workload.NewInfo(victim)
+			victimInfo.ClusterQueue = "cq"
+
+			if err := cache.MarkPreempting(workload.NewInfo(preemptor), []*workload.Info{victimInfo}); err != nil {
+				t.Fatalf("Failed marking the victim as preempting: %v", err)
+			}
+			if !cache.IsPreempting(victimKey) {
+				t.Error("Expected the victim to be in its preemption grace period")
+			}
+			if err := cache.MarkPreempting(workload.NewInfo(other), []*workload.Info{victimInfo}); !errors.Is(err, errWorkloadAlreadyPreempting) {
+				t.Errorf("Unexpected error marking the victim for another preemptor: %v", err)
+			}
+
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cqSnapshot := snapshot.ClusterQueue("cq")
+			if diff := cmp.Diff(sets.New(victimKey), cqSnapshot.Preempting); diff != "" {
+				t.Errorf("Unexpected preempting workloads (-want,+got):\n%s", diff)
+			}
+			if got := cqSnapshot.Available(fr); got != 0 {
+				t.Errorf("Unexpected available quota during the grace period: %d", got)
+			}
+
+			if err := cache.DeleteWorkload(log, victim); err != nil {
+				t.Fatalf("Failed deleting the victim: %v", err)
+			}
+			if cache.IsPreempting(victimKey) {
+				t.Error("Expected the victim to complete its preemption")
+			}
+			snapshot, err = cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cqSnapshot = snapshot.ClusterQueue("cq")
+			if got := cqSnapshot.Available(fr); got != 0 {
+				t.Errorf("Unexpected available quota with earmarked usage: %d", got)
+			}
+			restore := snapshot.SimulateEarmarkRelease(preemptorKey)
+			if got := cqSnapshot.Available(fr); got != 4_000 {
+				t.Errorf("Unexpected available quota for the preemptor: %d", got)
+			}
+			restore()
+			if got := cqSnapshot.Available(fr); got != 0 {
+				t.Errorf("Unexpected available quota after restoring the earmarks: %d", got)
+			}
+
+			tc.finish(t, cache)
+			if got := cache.hm.ClusterQueue("cq").resourceNode.Usage[fr]; got != tc.wantUsage {
+				t.Errorf("Unexpected usage, want=%d, got=%d", tc.wantUsage, got)
+			}
+			if got := len(cache.hm.ClusterQueue("cq").earmarks); got != 0 {
+				t.Errorf("Unexpected earmarks left: %d", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+var errUnknownReclaimEvictionOrder = errors.New("unknown reclaim eviction order")
+
+// ReclaimEvictionOrder defines in which order the workloads borrowing
+// from a lending ClusterQueue are evicted when it reclaims its quota.
+type ReclaimEvictionOrder string
+
+const (
+	// ReclaimEvictionOrderPriority evicts the workloads with the lowest
+	// priority first, and the most recently admitted among them. It is
+	// the default.
+	ReclaimEvictionOrderPriority ReclaimEvictionOrder = "Priority"
+	// ReclaimEvictionOrderNewestFirst evicts the most recently admitted
+	// workloads first, regardless of their priority.
+	ReclaimEvictionOrderNewestFirst ReclaimEvictionOrder = "NewestFirst"
+)
+
+// SetReclaimEvictionOrder sets the order in which the workloads of other
+// ClusterQueues in the Cohort are evicted when the ClusterQueue reclaims
+// its quota. An empty order resets the ClusterQueue to the default,
+// ReclaimEvictionOrderPriority.
+func (c *Cache) SetReclaimEvictionOrder(cqName kueue.ClusterQueueReference, order ReclaimEvictionOrder) error {
+	switch order {
+	case "", ReclaimEvictionOrderPriority, ReclaimEvictionOrderNewestFirst:
+	default:
+		return errUnknownReclaimEvictionOrder
+	}
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.reclaimEvictionOrder = order
+	return nil
+}
+
+// ReclaimEvictionOrder returns the order in which the ClusterQueue
+// evicts the workloads of other ClusterQueues when reclaiming its quota.
+func (c *Cache) ReclaimEvictionOrder(cqName kueue.ClusterQueueReference) (ReclaimEvictionOrder, error) {
+	c.RLock()
+	defer c.RUnlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return "", ErrCqNotFound
+	}
+	return cq.evictionOrderForReclaim(), nil
+}
+
+func (c *clusterQueue) evictionOrderForReclaim() ReclaimEvictionOrder {
+	if c.reclaimEvictionOrder == "" {
+		return ReclaimEvictionOrderPriority
+	}
+	return c.reclaimEvictionOrder
+}
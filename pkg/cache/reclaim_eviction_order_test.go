@@ -0,0 +1,92 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestSetReclaimEvictionOrder(t *testing.T) {
+	cases := map[string]struct {
+		cqName       kueue.ClusterQueueReference
+		order        ReclaimEvictionOrder
+		wantErr      error
+		wantOrder    ReclaimEvictionOrder
+		wantSnapshot ReclaimEvictionOrder
+	}{
+		"newest first": {
+			cqName:       "lender",
+			order:        ReclaimEvictionOrderNewestFirst,
+			wantOrder:    ReclaimEvictionOrderNewestFirst,
+			wantSnapshot: ReclaimEvictionOrderNewestFirst,
+		},
+		"reset to default": {
+			cqName:    "lender",
+			wantOrder: ReclaimEvictionOrderPriority,
+		},
+		"unknown order": {
+			cqName:    "lender",
+			order:     "OldestFirst",
+			wantErr:   errUnknownReclaimEvictionOrder,
+			wantOrder: ReclaimEvictionOrderPriority,
+		},
+		"unknown ClusterQueue": {
+			cqName:    "missing",
+			order:     ReclaimEvictionOrderNewestFirst,
+			wantErr:   ErrCqNotFound,
+			wantOrder: ReclaimEvictionOrderPriority,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("lender").
+				Cohort("cohort").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+
+			if err := cache.SetReclaimEvictionOrder(tc.cqName, tc.order); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Unexpected error, want=%v, got=%v", tc.wantErr, err)
+			}
+			got, err := cache.ReclaimEvictionOrder("lender")
+			if err != nil {
+				t.Fatalf("Failed getting the reclaim eviction order: %v", err)
+			}
+			if got != tc.wantOrder {
+				t.Errorf("Unexpected reclaim eviction order, want=%q, got=%q", tc.wantOrder, got)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			if got := snapshot.ClusterQueue("lender").ReclaimEvictionOrder; got != tc.wantSnapshot {
+				t.Errorf("Unexpected reclaim eviction order in the snapshot, want=%q, got=%q", tc.wantSnapshot, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// rejectionTTL is how long the last rejection of a workload is kept.
+const rejectionTTL = 5 * time.Minute
+
+// rejection holds the reason why the scheduler rejected a workload for
+// a ClusterQueue.
+type rejection struct {
+	clusterQueue kueue.ClusterQueueReference
+	reason       string
+	timestamp    time.Time
+}
+
+// RecordRejection records that the scheduler rejected the workload for the
+// ClusterQueue, replacing any previous rejection of the workload. The
+// record expires after rejectionTTL, or when the workload reserves quota.
+func (c *Cache) RecordRejection(wlKey string, cqName kueue.ClusterQueueReference, reason string) {
+	c.Lock()
+	defer c.Unlock()
+	now := c.clock.Now()
+	for k, r := range c.rejections {
+		if now.Sub(r.timestamp) > rejectionTTL {
+			delete(c.rejections, k)
+		}
+	}
+	c.rejections[wlKey] = rejection{
+		clusterQueue: cqName,
+		reason:       reason,
+		timestamp:    now,
+	}
+}
+
+// LastRejection returns the ClusterQueue and the reason of the last
+// rejection of the workload, if it didn't expire yet.
+func (c *Cache) LastRejection(wlKey string) (kueue.ClusterQueueReference, string, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	r, found := c.rejections[wlKey]
+	if !found || c.clock.Since(r.timestamp) > rejectionTTL {
+		return "", "", false
+	}
+	return r.clusterQueue, r.reason, true
+}
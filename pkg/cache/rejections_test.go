@@ -0,0 +1,98 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestLastRejection(t *testing.T) {
+	wl := utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "2").Obj()
+	wlKey := workload.Key(wl)
+
+	cases := map[string]struct {
+		// after is applied once the rejection is recorded.
+		after      func(t *testing.T, cache *Cache, fakeClock *testingclock.FakeClock)
+		wantCQ     kueue.ClusterQueueReference
+		wantReason string
+		wantOk     bool
+	}{
+		"rejection recorded": {
+			wantCQ:     "cq",
+			wantReason: "insufficient quota",
+			wantOk:     true,
+		},
+		"newer rejection": {
+			after: func(_ *testing.T, cache *Cache, _ *testingclock.FakeClock) {
+				cache.RecordRejection(wlKey, "other-cq", "no matching flavor")
+			},
+			wantCQ:     "other-cq",
+			wantReason: "no matching flavor",
+			wantOk:     true,
+		},
+		"rejection expired": {
+			after: func(_ *testing.T, _ *Cache, fakeClock *testingclock.FakeClock) {
+				fakeClock.Step(rejectionTTL + time.Second)
+			},
+		},
+		"workload reserved quota": {
+			after: func(t *testing.T, cache *Cache, _ *testingclock.FakeClock) {
+				_, log := utiltesting.ContextWithLog(t)
+				admitted := wl.DeepCopy()
+				workload.SetQuotaReservation(admitted, utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "2").Obj(), nil)
+				if !cache.AddOrUpdateWorkload(log, admitted) {
+					t.Fatal("Failed adding the workload")
+				}
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			fakeClock := testingclock.NewFakeClock(time.Now())
+			cache := New(utiltesting.NewFakeClient(), WithClock(t, fakeClock))
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+
+			if _, _, ok := cache.LastRejection(wlKey); ok {
+				t.Error("Unexpected rejection before recording it")
+			}
+			cache.RecordRejection(wlKey, "cq", "insufficient quota")
+			if tc.after != nil {
+				tc.after(t, cache, fakeClock)
+			}
+
+			gotCQ, gotReason, gotOk := cache.LastRejection(wlKey)
+			if gotCQ != tc.wantCQ || gotReason != tc.wantReason || gotOk != tc.wantOk {
+				t.Errorf("Unexpected rejection, want=(%q, %q, %t), got=(%q, %q, %t)", tc.wantCQ, tc.wantReason, tc.wantOk, gotCQ, gotReason, gotOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// requeueState tracks the add and remove cycles of a workload.
+type requeueState struct {
+	// count is the number of times the workload was added back after
+	// being removed.
+	count int
+	// removed indicates whether the workload was removed and not added
+	// back yet.
+	removed bool
+}
+
+// RequeueCount returns how many times the workload was added back to the
+// cache after its quota reservation was removed, for example after being
+// evicted and requeued. A high count indicates an unstable workload.
+func (c *Cache) RequeueCount(key string) int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.requeues[key].count
+}
+
+// ForgetRequeueCount drops the requeue count of the workload. It is meant
+// to be called once the workload is deleted, so that the tracked workloads
+// don't grow without bound.
+func (c *Cache) ForgetRequeueCount(key string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.requeues, key)
+}
+
+// recordWorkloadRemoved records that the quota reservation of the workload
+// was removed from the cache.
+func (c *Cache) recordWorkloadRemoved(key string) {
+	state := c.requeues[key]
+	state.removed = true
+	c.requeues[key] = state
+}
+
+// recordWorkloadAdded records that the workload was added to the cache,
+// counting a requeue if it was removed before.
+func (c *Cache) recordWorkloadAdded(key string) {
+	state, found := c.requeues[key]
+	if !found || !state.removed {
+		return
+	}
+	state.count++
+	state.removed = false
+	c.requeues[key] = state
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestRequeueCount(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	reserving := utiltesting.MakeWorkload("wl", "ns").
+		Request(corev1.ResourceCPU, "1").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+		Obj()
+	other := utiltesting.MakeWorkload("other", "ns").
+		Request(corev1.ResourceCPU, "1").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+		Obj()
+
+	if !cache.AddOrUpdateWorkload(log, other) {
+		t.Fatal("Failed adding workload")
+	}
+	if got := cache.RequeueCount("ns/wl"); got != 0 {
+		t.Errorf("Unexpected requeue count for an unknown workload, want=0, got=%d", got)
+	}
+
+	// First admission, through the scheduler.
+	if err := cache.AssumeWorkload(log, reserving); err != nil {
+		t.Fatalf("Failed assuming workload: %v", err)
+	}
+	if !cache.AddOrUpdateWorkload(log, reserving) {
+		t.Fatal("Failed adding workload")
+	}
+	if got := cache.RequeueCount("ns/wl"); got != 0 {
+		t.Errorf("Unexpected requeue count after the first admission, want=0, got=%d", got)
+	}
+
+	// Evicted and admitted again, twice.
+	for i := 1; i <= 2; i++ {
+		if err := cache.DeleteWorkload(log, reserving); err != nil {
+			t.Fatalf("Failed deleting workload: %v", err)
+		}
+		if err := cache.AssumeWorkload(log, reserving); err != nil {
+			t.Fatalf("Failed assuming workload: %v", err)
+		}
+		if !cache.AddOrUpdateWorkload(log, reserving) {
+			t.Fatal("Failed adding workload")
+		}
+		if got := cache.RequeueCount("ns/wl"); got != i {
+			t.Errorf("Unexpected requeue count after cycle %d, want=%d, got=%d", i, i, got)
+		}
+	}
+
+	if got := cache.RequeueCount("ns/other"); got != 0 {
+		t.Errorf("Unexpected requeue count for a stable workload, want=0, got=%d", got)
+	}
+
+	// Deleted workloads are forgotten.
+	for _, wl := range []*kueue.Workload{reserving, other} {
+		if err := cache.DeleteWorkload(log, wl); err != nil {
+			t.Fatalf("Failed deleting workload: %v", err)
+		}
+		cache.ForgetRequeueCount(cache.WorkloadKey(wl))
+	}
+	if got := cache.RequeueCount("ns/wl"); got != 0 {
+		t.Errorf("Unexpected requeue count after the deletion, want=0, got=%d", got)
+	}
+	if len(cache.requeues) != 0 {
+		t.Errorf("Unexpected requeue states kept after the deletions: %v", cache.requeues)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// ReservationOutcomes counts how the quota reservations of the workloads
+// which were observed in the cache waiting for their admission checks
+// concluded.
+type ReservationOutcomes struct {
+	// Admitted is the number of workloads admitted after reserving quota.
+	Admitted int
+	// Dropped is the number of workloads which lost their quota
+	// reservation, or were deleted, before being admitted.
+	Dropped int
+}
+
+// AdmissionRate returns the fraction of the concluded reservations which
+// converted into admissions, and false if no reservation concluded yet.
+func (o ReservationOutcomes) AdmissionRate() (float64, bool) {
+	total := o.Admitted + o.Dropped
+	if total == 0 {
+		return 0, false
+	}
+	return float64(o.Admitted) / float64(total), true
+}
+
+// ReservationOutcomes returns how the quota reservations in the
+// ClusterQueue concluded. Workloads forgotten by the scheduler after
+// failing to be assumed are not counted.
+func (c *Cache) ReservationOutcomes(cqName kueue.ClusterQueueReference) ReservationOutcomes {
+	c.RLock()
+	defer c.RUnlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ReservationOutcomes{}
+	}
+	return cq.reservationOutcomes
+}
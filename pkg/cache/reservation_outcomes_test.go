@@ -0,0 +1,123 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestReservationOutcomes(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+		AdmissionChecks("check").
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admission := utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()
+	reserving := func(name string) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").Request(corev1.ResourceCPU, "1").ReserveQuota(admission).Obj()
+	}
+	admit := func(wl *kueue.Workload) *kueue.Workload {
+		admitted := wl.DeepCopy()
+		apimeta.SetStatusCondition(&admitted.Status.Conditions, metav1.Condition{
+			Type:   kueue.WorkloadAdmitted,
+			Status: metav1.ConditionTrue,
+			Reason: "Admitted",
+		})
+		return admitted
+	}
+
+	if got, found := cache.ReservationOutcomes("cq").AdmissionRate(); found {
+		t.Errorf("Unexpected admission rate before any reservation concluded: %v", got)
+	}
+
+	// Admitted through an update of the workload.
+	a := reserving("a")
+	if !cache.AddOrUpdateWorkload(log, a) {
+		t.Fatal("Failed adding workload")
+	}
+	if err := cache.UpdateWorkload(log, a, admit(a)); err != nil {
+		t.Fatalf("Failed updating workload: %v", err)
+	}
+	// Admitted through a re-add of the workload.
+	b := reserving("b")
+	if !cache.AddOrUpdateWorkload(log, b) {
+		t.Fatal("Failed adding workload")
+	}
+	if !cache.AddOrUpdateWorkload(log, admit(b)) {
+		t.Fatal("Failed updating workload")
+	}
+	// Lost its quota reservation before being admitted.
+	c := reserving("c")
+	if !cache.AddOrUpdateWorkload(log, c) {
+		t.Fatal("Failed adding workload")
+	}
+	evicted := c.DeepCopy()
+	apimeta.SetStatusCondition(&evicted.Status.Conditions, metav1.Condition{
+		Type:   kueue.WorkloadQuotaReserved,
+		Status: metav1.ConditionFalse,
+		Reason: "Pending",
+	})
+	if err := cache.UpdateWorkload(log, c, evicted); err != nil {
+		t.Fatalf("Failed updating workload: %v", err)
+	}
+	// Deleted before being admitted.
+	d := reserving("d")
+	if !cache.AddOrUpdateWorkload(log, d) {
+		t.Fatal("Failed adding workload")
+	}
+	if err := cache.DeleteWorkload(log, d); err != nil {
+		t.Fatalf("Failed deleting workload: %v", err)
+	}
+	// Deleted after being admitted.
+	if err := cache.DeleteWorkload(log, admit(a)); err != nil {
+		t.Fatalf("Failed deleting workload: %v", err)
+	}
+	// Added to the cache after its admission, without being observed
+	// waiting for its admission checks.
+	if !cache.AddOrUpdateWorkload(log, admit(reserving("e"))) {
+		t.Fatal("Failed adding workload")
+	}
+	// Still waiting for its admission checks.
+	if !cache.AddOrUpdateWorkload(log, reserving("f")) {
+		t.Fatal("Failed adding workload")
+	}
+
+	want := ReservationOutcomes{Admitted: 2, Dropped: 2}
+	got := cache.ReservationOutcomes("cq")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected reservation outcomes (-want,+got):\n%s", diff)
+	}
+	if rate, found := got.AdmissionRate(); !found || rate != 0.5 {
+		t.Errorf("Unexpected admission rate, want=0.5, got=%v (found=%v)", rate, found)
+	}
+	if diff := cmp.Diff(ReservationOutcomes{}, cache.ReservationOutcomes("missing")); diff != "" {
+		t.Errorf("Unexpected reservation outcomes for a missing ClusterQueue (-want,+got):\n%s", diff)
+	}
+}
@@ -43,8 +43,29 @@ func (rg *ResourceGroup) Clone() ResourceGroup {
 	}
 }
 
+// UsableFlavorCount returns how many flavors of the ResourceGroup have
+// free capacity, either nominal or borrowable from the Cohort, for any of
+// the covered resources in the ClusterQueue. Zero means the ResourceGroup
+// is saturated.
+func (rg *ResourceGroup) UsableFlavorCount(cq *ClusterQueueSnapshot) int {
+	count := 0
+	for _, fName := range rg.Flavors {
+		for rName := range rg.CoveredResources {
+			if cq.Available(resources.FlavorResource{Flavor: fName, Resource: rName}) > 0 {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
 type ResourceQuota struct {
-	Nominal        int64
+	Nominal int64
+	// BorrowingLimit is the maximum amount which can be borrowed from
+	// the Cohort. A nil value means that borrowing is only limited by
+	// the capacity of the Cohort, while zero means that no borrowing
+	// is allowed.
 	BorrowingLimit *int64
 	LendingLimit   *int64
 }
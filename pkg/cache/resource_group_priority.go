@@ -0,0 +1,68 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+var errUnknownResourceGroup = errors.New("no resource group covers the resource")
+
+// SetResourceGroupPriority sets the priority of the ResourceGroup of the
+// ClusterQueue covering the resource. The ResourceGroups with a higher
+// priority are evaluated first during the flavor assignment, regardless
+// of their position in the spec. A nil priority removes the priority set
+// through this resource.
+func (c *Cache) SetResourceGroupPriority(cqName kueue.ClusterQueueReference, resource corev1.ResourceName, priority *int32) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	if priority == nil {
+		delete(cq.resourceGroupPriorities, resource)
+		return nil
+	}
+	if cq.rgByResource(resource) == nil {
+		return errUnknownResourceGroup
+	}
+	if cq.resourceGroupPriorities == nil {
+		cq.resourceGroupPriorities = make(map[corev1.ResourceName]int32)
+	}
+	cq.resourceGroupPriorities[resource] = *priority
+	return nil
+}
+
+// ResourceGroupPriority returns the priority of the ResourceGroup, which
+// is the highest priority set through any of its covered resources, or 0
+// when none is set.
+func (c *ClusterQueueSnapshot) ResourceGroupPriority(rg *ResourceGroup) int32 {
+	var priority int32
+	found := false
+	for rName := range rg.CoveredResources {
+		if p, ok := c.ResourceGroupPriorities[rName]; ok && (!found || p > priority) {
+			priority = p
+			found = true
+		}
+	}
+	return priority
+}
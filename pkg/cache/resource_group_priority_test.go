@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestSetResourceGroupPriority(t *testing.T) {
+	type setPriority struct {
+		cq       kueue.ClusterQueueReference
+		resource corev1.ResourceName
+		priority *int32
+		wantErr  error
+	}
+	cases := map[string]struct {
+		operations     []setPriority
+		wantPriorities []int32
+	}{
+		"no priorities": {
+			wantPriorities: []int32{0, 0},
+		},
+		"priority of the second group": {
+			operations: []setPriority{
+				{cq: "cq", resource: "example.com/gpu", priority: ptr.To[int32](5)},
+			},
+			wantPriorities: []int32{0, 5},
+		},
+		"highest priority among the covered resources": {
+			operations: []setPriority{
+				{cq: "cq", resource: corev1.ResourceCPU, priority: ptr.To[int32](2)},
+				{cq: "cq", resource: corev1.ResourceMemory, priority: ptr.To[int32](3)},
+			},
+			wantPriorities: []int32{3, 0},
+		},
+		"negative priority": {
+			operations: []setPriority{
+				{cq: "cq", resource: corev1.ResourceCPU, priority: ptr.To[int32](-1)},
+			},
+			wantPriorities: []int32{-1, 0},
+		},
+		"removed priority": {
+			operations: []setPriority{
+				{cq: "cq", resource: corev1.ResourceCPU, priority: ptr.To[int32](2)},
+				{cq: "cq", resource: corev1.ResourceCPU},
+			},
+			wantPriorities: []int32{0, 0},
+		},
+		"resource not covered by any group": {
+			operations: []setPriority{
+				{cq: "cq", resource: "example.com/tpu", priority: ptr.To[int32](2), wantErr: errUnknownResourceGroup},
+			},
+			wantPriorities: []int32{0, 0},
+		},
+		"unknown ClusterQueue": {
+			operations: []setPriority{
+				{cq: "other", resource: corev1.ResourceCPU, priority: ptr.To[int32](2), wantErr: ErrCqNotFound},
+			},
+			wantPriorities: []int32{0, 0},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("gpu").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+					Resource(corev1.ResourceCPU, "4").
+					Resource(corev1.ResourceMemory, "4Gi").
+					Obj()).
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("gpu").
+					Resource("example.com/gpu", "2").
+					Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			for _, op := range tc.operations {
+				if err := cache.SetResourceGroupPriority(op.cq, op.resource, op.priority); !errors.Is(err, op.wantErr) {
+					t.Errorf("Unexpected error setting the priority through %s, want=%v, got=%v", op.resource, op.wantErr, err)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cqSnapshot := snapshot.ClusterQueue("cq")
+			gotPriorities := make([]int32, len(cqSnapshot.ResourceGroups))
+			for i := range cqSnapshot.ResourceGroups {
+				gotPriorities[i] = cqSnapshot.ResourceGroupPriority(&cqSnapshot.ResourceGroups[i])
+			}
+			if diff := cmp.Diff(tc.wantPriorities, gotPriorities); diff != "" {
+				t.Errorf("Unexpected resource group priorities (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sigs.k8s.io/kueue/pkg/resources"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// ResourceGroupIndexes returns the indexes, in the ClusterQueue spec, of
+// the ResourceGroups covering the resources requested by the workload.
+func (c *ClusterQueueSnapshot) ResourceGroupIndexes(wl *workload.Info) []int {
+	var indexes []int
+	for i := range c.ResourceGroups {
+		rg := &c.ResourceGroups[i]
+		for _, ps := range wl.TotalRequests {
+			if requestsAnyOf(ps.Requests, rg) {
+				indexes = append(indexes, i)
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+// NextCandidatePerResourceGroup models an independent FIFO order for each
+// ResourceGroup of the ClusterQueue, so that a workload blocked on the
+// resources of one group, like GPUs, doesn't hold back the workloads only
+// requesting the resources of another group, like CPUs.
+//
+// The pending workloads must be provided in the queueing order of the
+// ClusterQueue. A workload is queued in every group it requests resources
+// from, so it is behind the earlier workloads of all those groups. The
+// result maps the index of each ResourceGroup with pending workloads to
+// the first of them.
+func (c *ClusterQueueSnapshot) NextCandidatePerResourceGroup(pending []*workload.Info) map[int]*workload.Info {
+	candidates := make(map[int]*workload.Info, len(c.ResourceGroups))
+	for _, wl := range pending {
+		for _, i := range c.ResourceGroupIndexes(wl) {
+			if _, found := candidates[i]; !found {
+				candidates[i] = wl
+			}
+		}
+		if len(candidates) == len(c.ResourceGroups) {
+			break
+		}
+	}
+	return candidates
+}
+
+// requestsAnyOf indicates whether any of the requests is for a resource
+// covered by the ResourceGroup.
+func requestsAnyOf(requests resources.Requests, rg *ResourceGroup) bool {
+	for rName := range requests {
+		if rg.CoveredResources.Has(rName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestNextCandidatePerResourceGroup(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("gpu").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+			Resource(corev1.ResourceCPU, "8").
+			Resource(corev1.ResourceMemory, "8Gi").
+			Obj()).
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("gpu").Resource("example.com/gpu", "2").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admitted := utiltesting.MakeWorkload("admitted", "ns").
+		Request("example.com/gpu", "2").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment("example.com/gpu", "gpu", "2").Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(log, admitted) {
+		t.Fatal("Failed adding workload")
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	cqSnapshot := snapshot.ClusterQueue("cq")
+
+	blockedGPU := workload.NewInfo(utiltesting.MakeWorkload("blocked-gpu", "ns").Request("example.com/gpu", "1").Obj())
+	cpu := workload.NewInfo(utiltesting.MakeWorkload("cpu", "ns").Request(corev1.ResourceCPU, "2").Obj())
+	mixed := workload.NewInfo(utiltesting.MakeWorkload("mixed", "ns").
+		Request(corev1.ResourceCPU, "1").
+		Request("example.com/gpu", "1").
+		Obj())
+
+	cases := map[string]struct {
+		pending        []*workload.Info
+		wantCandidates map[int]string
+	}{
+		"no pending workloads": {
+			wantCandidates: map[int]string{},
+		},
+		"blocked gpu workload doesn't hold back the cpu workload": {
+			pending: []*workload.Info{blockedGPU, cpu},
+			wantCandidates: map[int]string{
+				0: "cpu",
+				1: "blocked-gpu",
+			},
+		},
+		"workload requesting both groups is queued in both": {
+			pending: []*workload.Info{mixed, blockedGPU, cpu},
+			wantCandidates: map[int]string{
+				0: "mixed",
+				1: "mixed",
+			},
+		},
+		"cpu workload ahead of the workload requesting both groups": {
+			pending: []*workload.Info{cpu, mixed},
+			wantCandidates: map[int]string{
+				0: "cpu",
+				1: "mixed",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotCandidates := make(map[int]string)
+			for i, wl := range cqSnapshot.NextCandidatePerResourceGroup(tc.pending) {
+				gotCandidates[i] = wl.Obj.Name
+			}
+			if diff := cmp.Diff(tc.wantCandidates, gotCandidates); diff != "" {
+				t.Errorf("Unexpected candidates (-want,+got):\n%s", diff)
+			}
+		})
+	}
+
+	// The candidate of the cpu group can be admitted, even if the head of
+	// the gpu group, which is earlier in the queue, doesn't fit.
+	candidates := cqSnapshot.NextCandidatePerResourceGroup([]*workload.Info{blockedGPU, cpu})
+	if _, fits := cqSnapshot.MinBorrowAssignment(candidates[1]); fits {
+		t.Errorf("Workload %q unexpectedly fits", candidates[1].Obj.Name)
+	}
+	if _, fits := cqSnapshot.MinBorrowAssignment(candidates[0]); !fits {
+		t.Errorf("Workload %q doesn't fit", candidates[0].Obj.Name)
+	}
+}
+
+func TestResourceGroupIndexes(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	cache := New(utiltesting.NewFakeClient())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("gpu").Obj())
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "8").Obj()).
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("gpu").Resource("example.com/gpu", "2").Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	snapshot, err := cache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	cqSnapshot := snapshot.ClusterQueue("cq")
+
+	cases := map[string]struct {
+		wl          *kueue.Workload
+		wantIndexes []int
+	}{
+		"cpu only": {
+			wl:          utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "1").Obj(),
+			wantIndexes: []int{0},
+		},
+		"gpu only": {
+			wl:          utiltesting.MakeWorkload("wl", "ns").Request("example.com/gpu", "1").Obj(),
+			wantIndexes: []int{1},
+		},
+		"both": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "1").
+				Request("example.com/gpu", "1").
+				Obj(),
+			wantIndexes: []int{0, 1},
+		},
+		"uncovered resource": {
+			wl: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceMemory, "1Gi").Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := cqSnapshot.ResourceGroupIndexes(workload.NewInfo(tc.wl))
+			if diff := cmp.Diff(tc.wantIndexes, got); diff != "" {
+				t.Errorf("Unexpected indexes (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package cache
 import (
 	"maps"
 
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/hierarchy"
 	"sigs.k8s.io/kueue/pkg/resources"
 )
@@ -82,6 +83,9 @@ type hierarchicalResourceNode interface {
 // capacity which is stored locally. If the node has a parent, it
 // queries the parent's capacity, limiting this amount by the borrowing
 // limit - and by how much capacity the node is storing/using in its parent.
+// For nodes with borrowing limits by lender, the capacity of the limited
+// lenders beyond those limits is excluded, and for nodes with a borrowing
+// cap, the BorrowingLimit is lowered to the cap.
 //
 // This function may return a negative number in the case of
 // overadmission - e.g. capacity was removed or the node moved to
@@ -93,8 +97,11 @@ func available(node hierarchicalResourceNode, fr resources.FlavorResource) int64
 	}
 	localAvailable := max(0, r.guaranteedQuota(fr)-r.Usage[fr])
 	parentAvailable := available(node.parentHRN(), fr)
+	if l, ok := node.(lenderLimitedNode); ok && parentAvailable > 0 {
+		parentAvailable = max(0, parentAvailable-l.lenderLimitedExcess(fr, false))
+	}
 
-	if borrowingLimit := r.Quotas[fr].BorrowingLimit; borrowingLimit != nil {
+	if borrowingLimit := borrowingLimit(node, fr); borrowingLimit != nil {
 		storedInParent := r.SubtreeQuota[fr] - r.guaranteedQuota(fr)
 		usedInParent := max(0, r.Usage[fr]-r.guaranteedQuota(fr))
 		withMaxFromParent := storedInParent - usedInParent + *borrowingLimit
@@ -110,8 +117,12 @@ func potentialAvailable(node hierarchicalResourceNode, fr resources.FlavorResour
 	if !node.HasParent() {
 		return r.SubtreeQuota[fr]
 	}
-	available := r.guaranteedQuota(fr) + potentialAvailable(node.parentHRN(), fr)
-	if borrowingLimit := r.Quotas[fr].BorrowingLimit; borrowingLimit != nil {
+	parentAvailable := potentialAvailable(node.parentHRN(), fr)
+	if l, ok := node.(lenderLimitedNode); ok {
+		parentAvailable = max(0, parentAvailable-l.lenderLimitedExcess(fr, true))
+	}
+	available := r.guaranteedQuota(fr) + parentAvailable
+	if borrowingLimit := borrowingLimit(node, fr); borrowingLimit != nil {
 		maxWithBorrowing := r.SubtreeQuota[fr] + *borrowingLimit
 		available = min(maxWithBorrowing, available)
 	}
@@ -167,6 +178,7 @@ func updateCohortTreeResources(cohort *cohort) error {
 // updateCohortTree, which starts at the root and includes
 // a cycle check.
 func updateCohortResourceNode(cohort *cohort) {
+	cohort.memberGenerations = make(map[kueue.ClusterQueueReference]int64, len(cohort.ChildCQs()))
 	cohort.resourceNode.SubtreeQuota = make(resources.FlavorResourceQuantities, len(cohort.resourceNode.SubtreeQuota))
 	cohort.resourceNode.Usage = make(resources.FlavorResourceQuantities, len(cohort.resourceNode.Usage))
 
@@ -179,8 +191,10 @@ func updateCohortResourceNode(cohort *cohort) {
 	}
 	for _, child := range cohort.ChildCQs() {
 		updateClusterQueueResourceNode(child)
+		cohort.memberGenerations[child.Name] = child.AllocatableResourceGeneration
 		accumulateFromChild(cohort, child)
 	}
+	cohort.aggregateSubtreeQuota(cohort.resourceNode.SubtreeQuota)
 }
 
 func accumulateFromChild(parent *cohort, child hierarchicalResourceNode) {
@@ -22,8 +22,12 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 func TestCohortLendable(t *testing.T) {
@@ -63,3 +67,88 @@ func TestCohortLendable(t *testing.T) {
 		t.Errorf("Unexpected cohort lendable (-want,+got):\n%s", diff)
 	}
 }
+
+func TestBorrowingLimit(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		borrowingLimit            *string
+		wantAvailable             int64
+		wantPotentialAvailable    int64
+		wantBorrowableCapacity    int64
+		wantFitsWithBorrowing     bool
+		wantFitsAboveBorrowingCap bool
+	}{
+		"nil borrowing limit is limited by the cohort": {
+			wantAvailable:             12_000,
+			wantPotentialAvailable:    14_000,
+			wantBorrowableCapacity:    14_000,
+			wantFitsWithBorrowing:     true,
+			wantFitsAboveBorrowingCap: true,
+		},
+		"zero borrowing limit forbids borrowing": {
+			borrowingLimit:         ptr.To("0"),
+			wantAvailable:          2_000,
+			wantPotentialAvailable: 4_000,
+			wantBorrowableCapacity: 4_000,
+		},
+		"positive borrowing limit": {
+			borrowingLimit:         ptr.To("3"),
+			wantAvailable:          5_000,
+			wantPotentialAvailable: 7_000,
+			wantBorrowableCapacity: 7_000,
+			wantFitsWithBorrowing:  true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			quota := utiltesting.MakeFlavorQuotas("default").ResourceQuotaWrapper(corev1.ResourceCPU).NominalQuota("4")
+			if tc.borrowingLimit != nil {
+				quota = quota.BorrowingLimit(*tc.borrowingLimit)
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq").Cohort("cohort").ResourceGroup(*quota.Append().Obj()).Obj(),
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			admitted := utiltesting.MakeWorkload("admitted", "ns").
+				Request(corev1.ResourceCPU, "2").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "2").Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, admitted) {
+				t.Fatal("Failed adding workload")
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cq := snapshot.ClusterQueue("cq")
+			if got := cq.Available(fr); got != tc.wantAvailable {
+				t.Errorf("Unexpected available, want=%d, got=%d", tc.wantAvailable, got)
+			}
+			if got := cq.PotentialAvailable(fr); got != tc.wantPotentialAvailable {
+				t.Errorf("Unexpected potential available, want=%d, got=%d", tc.wantPotentialAvailable, got)
+			}
+			if got := cq.EffectiveCapacity(true)[fr]; got != tc.wantBorrowableCapacity {
+				t.Errorf("Unexpected capacity including borrowing, want=%d, got=%d", tc.wantBorrowableCapacity, got)
+			}
+			fits := func(q int64) bool {
+				return cq.Fits(workload.Usage{Quota: resources.FlavorResourceQuantities{fr: q}})
+			}
+			if got := fits(4_000); got != tc.wantFitsWithBorrowing {
+				t.Errorf("Unexpected fits when borrowing, want=%v, got=%v", tc.wantFitsWithBorrowing, got)
+			}
+			if got := fits(6_000); got != tc.wantFitsAboveBorrowingCap {
+				t.Errorf("Unexpected fits above the borrowing limit, want=%v, got=%v", tc.wantFitsAboveBorrowingCap, got)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 
 	kueuealpha "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
@@ -452,3 +453,79 @@ func TestAvailable(t *testing.T) {
 		})
 	}
 }
+
+func TestUsableFlavorCount(t *testing.T) {
+	makeWorkload := func(name, cq, flavor, quantity string) *kueue.Workload {
+		return utiltesting.MakeWorkload(name, "ns").
+			Request(corev1.ResourceCPU, quantity).
+			ReserveQuota(utiltesting.MakeAdmission(cq).Assignment(corev1.ResourceCPU, kueue.ResourceFlavorReference(flavor), quantity).Obj()).
+			Obj()
+	}
+
+	cases := map[string]struct {
+		workloads []*kueue.Workload
+		want      int
+	}{
+		"all flavors free": {
+			want: 3,
+		},
+		"one flavor full": {
+			workloads: []*kueue.Workload{makeWorkload("a", "cq", "on-demand", "2")},
+			want:      2,
+		},
+		"flavor with borrowable capacity": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "cq", "on-demand", "2"),
+				makeWorkload("b", "cq", "spot", "2"),
+			},
+			want: 2,
+		},
+		"all flavors full": {
+			workloads: []*kueue.Workload{
+				makeWorkload("a", "cq", "on-demand", "2"),
+				makeWorkload("b", "cq", "spot", "3"),
+				makeWorkload("c", "cq", "reserved", "1"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			for _, rf := range []string{"on-demand", "spot", "reserved"} {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor(rf).Obj())
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq").
+					Cohort("cohort").
+					ResourceGroup(
+						*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "2", "0").Obj(),
+						*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "2").Obj(),
+						*utiltesting.MakeFlavorQuotas("reserved").Resource(corev1.ResourceCPU, "1").Obj(),
+					).
+					Obj(),
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "1").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			for _, wl := range tc.workloads {
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatalf("Failed adding workload %s", wl.Name)
+				}
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cq := snapshot.ClusterQueue("cq")
+			if got := cq.ResourceGroups[0].UsableFlavorCount(cq); got != tc.want {
+				t.Errorf("Unexpected usable flavor count, want=%d, got=%d", tc.want, got)
+			}
+		})
+	}
+}
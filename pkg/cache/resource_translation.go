@@ -0,0 +1,74 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// SetResourceTranslations sets, for the ClusterQueue, the factor by which
+// the requests for a resource are multiplied when computing the usage of
+// its workloads. For example, with time-sliced GPUs, a factor of 0.5 for
+// nvidia.com/gpu makes a workload requesting 2 logical GPUs use 1 GPU of
+// the quota. Fractions of a unit are rounded up.
+//
+// The usage of the workloads already admitted in the ClusterQueue is
+// recomputed with the new translations.
+func (c *Cache) SetResourceTranslations(log logr.Logger, cqName kueue.ClusterQueueReference, translations map[corev1.ResourceName]resource.Quantity) error {
+	c.Lock()
+	defer c.Unlock()
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return ErrCqNotFound
+	}
+	cq.setResourceTranslations(log, translations)
+	return nil
+}
+
+func (c *clusterQueue) setResourceTranslations(log logr.Logger, translations map[corev1.ResourceName]resource.Quantity) {
+	if maps.EqualFunc(c.resourceTranslations, translations, func(a, b resource.Quantity) bool { return a.Cmp(b) == 0 }) {
+		return
+	}
+	for _, wi := range c.Workloads {
+		c.updateWorkloadUsage(log, wi, -1)
+	}
+	c.resourceTranslations = maps.Clone(translations)
+	for k, wi := range c.Workloads {
+		newWi := workload.NewInfo(wi.Obj, append(c.infoOptions(), workload.WithAdmittedGeneration(wi.AdmittedGeneration()))...)
+		c.Workloads[k] = newWi
+		c.updateWorkloadUsage(log, newWi, 1)
+	}
+	c.AllocatableResourceGeneration++
+}
+
+// infoOptions returns a new slice with the options to build the info of
+// the workloads admitted in the ClusterQueue.
+func (c *clusterQueue) infoOptions() []workload.InfoOption {
+	opts := slices.Clone(c.workloadInfoOptions)
+	if len(c.resourceTranslations) > 0 {
+		opts = append(opts, workload.WithResourceTranslations(c.resourceTranslations))
+	}
+	return opts
+}
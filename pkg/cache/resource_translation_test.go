@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestSetResourceTranslations(t *testing.T) {
+	const gpu corev1.ResourceName = "nvidia.com/gpu"
+	gpuFr := resources.FlavorResource{Flavor: "a100", Resource: gpu}
+
+	cases := map[string]struct {
+		translations map[corev1.ResourceName]resource.Quantity
+		wantUsage    resources.FlavorResourceQuantities
+	}{
+		"no translation": {
+			wantUsage: resources.FlavorResourceQuantities{gpuFr: 6},
+		},
+		"logical GPU maps to half a GPU": {
+			translations: map[corev1.ResourceName]resource.Quantity{
+				gpu: resource.MustParse("0.5"),
+			},
+			wantUsage: resources.FlavorResourceQuantities{gpuFr: 3},
+		},
+		"fractions are rounded up": {
+			translations: map[corev1.ResourceName]resource.Quantity{
+				gpu: resource.MustParse("0.25"),
+			},
+			wantUsage: resources.FlavorResourceQuantities{gpuFr: 2},
+		},
+		"translation for other resource": {
+			translations: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU: resource.MustParse("0.5"),
+			},
+			wantUsage: resources.FlavorResourceQuantities{gpuFr: 6},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("a100").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("a100").Resource(gpu, "8").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			// The first workload is admitted before the translation is set,
+			// and the second one after.
+			first := utiltesting.MakeWorkload("first", "ns").
+				Request(gpu, "4").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(gpu, "a100", "4").Obj()).
+				Obj()
+			second := utiltesting.MakeWorkload("second", "ns").
+				Request(gpu, "2").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(gpu, "a100", "2").Obj()).
+				Obj()
+			if !cache.AddOrUpdateWorkload(log, first) {
+				t.Fatal("Failed adding workload")
+			}
+			if err := cache.SetResourceTranslations(log, "cq", tc.translations); err != nil {
+				t.Fatalf("Failed setting resource translations: %v", err)
+			}
+			if !cache.AddOrUpdateWorkload(log, second) {
+				t.Fatal("Failed adding workload")
+			}
+			if diff := cmp.Diff(tc.wantUsage, cache.hm.ClusterQueue("cq").resourceNode.Usage); diff != "" {
+				t.Errorf("Unexpected usage (-want,+got):\n%s", diff)
+			}
+
+			if err := cache.DeleteWorkload(log, first); err != nil {
+				t.Fatalf("Failed deleting workload: %v", err)
+			}
+			if err := cache.DeleteWorkload(log, second); err != nil {
+				t.Fatalf("Failed deleting workload: %v", err)
+			}
+			if diff := cmp.Diff(resources.FlavorResourceQuantities{gpuFr: 0}, cache.hm.ClusterQueue("cq").resourceNode.Usage); diff != "" {
+				t.Errorf("Unexpected usage after deleting the workloads (-want,+got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("missing ClusterQueue", func(t *testing.T) {
+		_, log := utiltesting.ContextWithLog(t)
+		cache := New(utiltesting.NewFakeClient())
+		if err := cache.SetResourceTranslations(log, "cq", nil); !errors.Is(err, ErrCqNotFound) {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
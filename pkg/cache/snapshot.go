@@ -29,6 +29,7 @@ import (
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/hierarchy"
+	"sigs.k8s.io/kueue/pkg/resources"
 	utilmaps "sigs.k8s.io/kueue/pkg/util/maps"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
@@ -37,22 +38,32 @@ type Snapshot struct {
 	hierarchy.Manager[*ClusterQueueSnapshot, *CohortSnapshot]
 	ResourceFlavors          map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor
 	InactiveClusterQueueSets sets.Set[kueue.ClusterQueueReference]
+	// PartialAdmissionGranularity is the multiple to which the pod counts
+	// of partially admitted PodSets are rounded down.
+	PartialAdmissionGranularity int32
+	// CohortDeficitPolicy defines whether workloads can be admitted in
+	// the Cohort trees in deficit.
+	CohortDeficitPolicy CohortDeficitPolicy
+	// workloadKeyFunc computes the keys of the Workloads.
+	workloadKeyFunc WorkloadKeyFunc
 }
 
 // RemoveWorkload removes a workload from its corresponding ClusterQueue and
 // updates resource usage.
 func (s *Snapshot) RemoveWorkload(wl *workload.Info) {
 	cq := s.ClusterQueue(wl.ClusterQueue)
-	delete(cq.Workloads, workload.Key(wl.Obj))
+	delete(cq.Workloads, cq.workloadKey(wl.Obj))
 	cq.RemoveUsage(wl.Usage())
+	cq.updateUnadmittedUsage(wl, -1)
 }
 
 // AddWorkload adds a workload from its corresponding ClusterQueue and
 // updates resource usage.
 func (s *Snapshot) AddWorkload(wl *workload.Info) {
 	cq := s.ClusterQueue(wl.ClusterQueue)
-	cq.Workloads[workload.Key(wl.Obj)] = wl
+	cq.Workloads[cq.workloadKey(wl.Obj)] = wl
 	cq.AddUsage(wl.Usage())
+	cq.updateUnadmittedUsage(wl, 1)
 }
 
 func (s *Snapshot) Log(log logr.Logger) {
@@ -106,9 +117,12 @@ func (c *Cache) Snapshot(ctx context.Context) (*Snapshot, error) {
 	defer c.RUnlock()
 
 	snap := Snapshot{
-		Manager:                  hierarchy.NewManager(newCohortSnapshot),
-		ResourceFlavors:          make(map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor, len(c.resourceFlavors)),
-		InactiveClusterQueueSets: sets.New[kueue.ClusterQueueReference](),
+		Manager:                     hierarchy.NewManager(newCohortSnapshot),
+		ResourceFlavors:             make(map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor, len(c.resourceFlavors)),
+		InactiveClusterQueueSets:    sets.New[kueue.ClusterQueueReference](),
+		PartialAdmissionGranularity: c.partialAdmissionGranularity,
+		CohortDeficitPolicy:         c.cohortDeficitPolicy,
+		workloadKeyFunc:             c.workloadKeyFunc,
 	}
 	for _, cohort := range c.hm.Cohorts() {
 		if hierarchy.HasCycle(cohort) {
@@ -117,6 +131,8 @@ func (c *Cache) Snapshot(ctx context.Context) (*Snapshot, error) {
 		snap.AddCohort(cohort.Name)
 		snap.Cohort(cohort.Name).ResourceNode = cohort.resourceNode.Clone()
 		snap.Cohort(cohort.Name).FairWeight = cohort.FairWeight
+		snap.Cohort(cohort.Name).fairSharingUsageMode = c.fairSharingUsage
+		snap.Cohort(cohort.Name).NamespaceSelector = c.cohortNamespaceSelectors[cohort.Name]
 		if cohort.HasParent() {
 			snap.UpdateCohortEdge(cohort.Name, cohort.Parent().Name)
 		}
@@ -135,9 +151,17 @@ func (c *Cache) Snapshot(ctx context.Context) (*Snapshot, error) {
 	for _, cq := range c.hm.ClusterQueues() {
 		if !cq.Active() || (cq.HasParent() && hierarchy.HasCycle(cq.Parent())) {
 			snap.InactiveClusterQueueSets.Insert(cq.Name)
+			if cq.isStopped && cq.HasParent() && !hierarchy.HasCycle(cq.Parent()) {
+				snap.addStoppedQuota(cq)
+			}
 			continue
 		}
 		cqSnapshot := snapshotClusterQueue(cq)
+		cqSnapshot.Preempting = c.preemptingIn(cq.Name)
+		cqSnapshot.fairSharingUsageMode = c.fairSharingUsage
+		for _, wi := range cqSnapshot.Workloads {
+			cqSnapshot.updateUnadmittedUsage(wi, 1)
+		}
 		snap.AddClusterQueue(cqSnapshot)
 		if cq.HasParent() {
 			snap.UpdateClusterQueueEdge(cq.Name, cq.Parent().Name)
@@ -157,6 +181,18 @@ func (c *Cache) Snapshot(ctx context.Context) (*Snapshot, error) {
 	return &snap, nil
 }
 
+// addStoppedQuota records, in the snapshot of its Cohort, the quota
+// contributed by the stopped ClusterQueue.
+func (s *Snapshot) addStoppedQuota(cq *clusterQueue) {
+	cohort := s.Cohort(cq.Parent().Name)
+	if cohort.StoppedQuota == nil {
+		cohort.StoppedQuota = make(resources.FlavorResourceQuantities)
+	}
+	for fr, q := range cq.resourceNode.SubtreeQuota {
+		cohort.StoppedQuota[fr] += q - cq.resourceNode.guaranteedQuota(fr)
+	}
+}
+
 // snapshotClusterQueue creates a copy of ClusterQueue that includes
 // references to immutable objects and deep copies of changing ones.
 func snapshotClusterQueue(c *clusterQueue) *ClusterQueueSnapshot {
@@ -174,10 +210,24 @@ func snapshotClusterQueue(c *clusterQueue) *ClusterQueueSnapshot {
 		ResourceNode:                  c.resourceNode.Clone(),
 		TASFlavors:                    make(map[kueue.ResourceFlavorReference]*TASFlavorSnapshot),
 		tasOnly:                       c.isTASOnly(),
+		ReclaimEvictionOrder:          c.reclaimEvictionOrder,
+		LenderBorrowingLimits:         c.lenderBorrowingLimits,
+		DefaultPriority:               c.defaultPriority,
+		BorrowingFraction:             c.borrowingFraction,
+		MaxFlavorsToTry:               c.maxFlavorsToTry,
+		ResourceGroupPriorities:       maps.Clone(c.resourceGroupPriorities),
+		UsageAccounting:               workload.NewUsageAccounting(c.infoOptions()...),
+		workloadKeyFunc:               c.workloadKeyFunc,
 	}
 	for i, rg := range c.ResourceGroups {
 		cc.ResourceGroups[i] = rg.Clone()
 	}
+	if len(c.earmarks) > 0 {
+		cc.Earmarks = make(map[string]resources.FlavorResourceQuantities, len(c.earmarks))
+		for preemptor, usage := range c.earmarks {
+			cc.Earmarks[preemptor] = maps.Clone(usage)
+		}
+	}
 	return cc
 }
 
@@ -0,0 +1,49 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"slices"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/hierarchy"
+)
+
+// StaleGenerationClusterQueues returns the sorted names of the
+// ClusterQueues whose AllocatableResourceGeneration is inconsistent with
+// the last time their Cohort recomputed its resources: either the Cohort
+// didn't recompute the resources of the ClusterQueue since it joined, or
+// the generation of the ClusterQueue is lower than the one it had after
+// the recompute. The generations only increase, so a non-empty result
+// signals a bug. The ClusterQueues in Cohorts with cycles are skipped.
+func (c *Cache) StaleGenerationClusterQueues() []kueue.ClusterQueueReference {
+	c.RLock()
+	defer c.RUnlock()
+
+	var stale []kueue.ClusterQueueReference
+	for _, cq := range c.hm.ClusterQueues() {
+		if !cq.HasParent() || hierarchy.HasCycle(cq.Parent()) {
+			continue
+		}
+		generation, recomputed := cq.Parent().memberGenerations[cq.Name]
+		if !recomputed || cq.AllocatableResourceGeneration < generation {
+			stale = append(stale, cq.Name)
+		}
+	}
+	slices.Sort(stale)
+	return stale
+}
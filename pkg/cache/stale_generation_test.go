@@ -0,0 +1,91 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestStaleGenerationClusterQueues(t *testing.T) {
+	cases := map[string]struct {
+		// makeStale corrupts the generations tracked by the cache.
+		makeStale func(cache *Cache)
+		want      []kueue.ClusterQueueReference
+	}{
+		"consistent tree": {},
+		"member with a generation lower than the recomputed one": {
+			makeStale: func(cache *Cache) {
+				cache.hm.ClusterQueue("cq-b").AllocatableResourceGeneration--
+			},
+			want: []kueue.ClusterQueueReference{"cq-b"},
+		},
+		"member not recomputed by its Cohort": {
+			makeStale: func(cache *Cache) {
+				delete(cache.hm.Cohort("root").memberGenerations, "cq-a")
+			},
+			want: []kueue.ClusterQueueReference{"cq-a"},
+		},
+		"member with a generation increased after the recompute": {
+			makeStale: func(cache *Cache) {
+				cache.hm.ClusterQueue("cq-b").AllocatableResourceGeneration++
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("child").Parent("root").Obj()); err != nil {
+				t.Fatalf("Failed adding Cohort: %v", err)
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("cq-a").
+					Cohort("root").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("cq-b").
+					Cohort("child").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("standalone").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			// Recompute the tree once more, as done when a Cohort is updated.
+			if err := cache.AddOrUpdateCohort(utiltesting.MakeCohort("root").Obj()); err != nil {
+				t.Fatalf("Failed updating Cohort: %v", err)
+			}
+			if tc.makeStale != nil {
+				tc.makeStale(cache)
+			}
+			if diff := cmp.Diff(tc.want, cache.StaleGenerationClusterQueues()); diff != "" {
+				t.Errorf("Unexpected stale ClusterQueues (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
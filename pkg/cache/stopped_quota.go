@@ -0,0 +1,71 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+)
+
+// StoppedQuotaPolicy defines what would happen to the quota which a
+// ClusterQueue lends to its Cohort, if the ClusterQueue was stopped. It is
+// only used to plan maintenance through HeadroomFromStopping; it doesn't
+// change how the quota of the stopped ClusterQueues is accounted for when
+// scheduling.
+type StoppedQuotaPolicy string
+
+const (
+	// StoppedQuotaFreeze assumes the quota lent by the stopped ClusterQueue
+	// is withdrawn from its Cohort, so it can't be borrowed by its peers.
+	StoppedQuotaFreeze StoppedQuotaPolicy = "Freeze"
+	// StoppedQuotaRelease assumes the quota lent by the stopped ClusterQueue
+	// stays available to be borrowed by its peers.
+	StoppedQuotaRelease StoppedQuotaPolicy = "Release"
+)
+
+// HeadroomFromStopping returns, for each flavor and resource, the
+// capacity which would become borrowable by the peers of the ClusterQueue
+// in its Cohort, if it was stopped and its workloads drained. The usage
+// the ClusterQueue takes from the Cohort is released. With
+// StoppedQuotaFreeze, the quota the ClusterQueue lends to the Cohort is
+// withdrawn, so the peers only gain the capacity it borrows beyond its own
+// quota. Only the flavors and resources with a positive headroom are
+// included. It returns nil if the ClusterQueue doesn't exist.
+func (c *Cache) HeadroomFromStopping(cqName kueue.ClusterQueueReference, policy StoppedQuotaPolicy) resources.FlavorResourceQuantities {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.hm.ClusterQueue(cqName)
+	if cq == nil {
+		return nil
+	}
+	headroom := make(resources.FlavorResourceQuantities)
+	if !cq.HasParent() {
+		return headroom
+	}
+	node := cq.resourceNode
+	for fr, usage := range node.Usage {
+		released := max(0, usage-node.guaranteedQuota(fr))
+		if policy == StoppedQuotaFreeze {
+			released -= node.SubtreeQuota[fr] - node.guaranteedQuota(fr)
+		}
+		if released > 0 {
+			headroom[fr] = released
+		}
+	}
+	return headroom
+}
@@ -0,0 +1,134 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestHeadroomFromStopping(t *testing.T) {
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	cases := map[string]struct {
+		policy   StoppedQuotaPolicy
+		cqName   kueue.ClusterQueueReference
+		usage    string
+		want     resources.FlavorResourceQuantities
+		wantNil  bool
+		noCohort bool
+	}{
+		"freeze, borrowing beyond its quota": {
+			policy: StoppedQuotaFreeze,
+			cqName: "stopping",
+			usage:  "6",
+			want:   resources.FlavorResourceQuantities{fr: 2_000},
+		},
+		"freeze, within its quota": {
+			policy: StoppedQuotaFreeze,
+			cqName: "stopping",
+			usage:  "3",
+			want:   resources.FlavorResourceQuantities{},
+		},
+		"release, borrowing beyond its quota": {
+			policy: StoppedQuotaRelease,
+			cqName: "stopping",
+			usage:  "6",
+			want:   resources.FlavorResourceQuantities{fr: 6_000},
+		},
+		"release, within its quota": {
+			policy: StoppedQuotaRelease,
+			cqName: "stopping",
+			usage:  "3",
+			want:   resources.FlavorResourceQuantities{fr: 3_000},
+		},
+		"freeze, with lending limit": {
+			policy: StoppedQuotaFreeze,
+			cqName: "lender",
+			usage:  "7",
+			want:   resources.FlavorResourceQuantities{},
+		},
+		"release, with lending limit": {
+			policy: StoppedQuotaRelease,
+			cqName: "lender",
+			usage:  "7",
+			want:   resources.FlavorResourceQuantities{fr: 3_000},
+		},
+		"release, without a cohort": {
+			policy:   StoppedQuotaRelease,
+			cqName:   "stopping",
+			usage:    "3",
+			noCohort: true,
+			want:     resources.FlavorResourceQuantities{},
+		},
+		"missing ClusterQueue": {
+			cqName:  "missing",
+			wantNil: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			stopping := utiltesting.MakeClusterQueue("stopping").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj())
+			if !tc.noCohort {
+				stopping.Cohort("cohort")
+			}
+			for _, cq := range []*kueue.ClusterQueue{
+				stopping.Obj(),
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10", "", "6").Obj()).
+					Obj(),
+				utiltesting.MakeClusterQueue("peer").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+					Obj(),
+			} {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue: %v", err)
+				}
+			}
+			if tc.usage != "" {
+				wl := utiltesting.MakeWorkload("wl", "ns").
+					Request(corev1.ResourceCPU, tc.usage).
+					ReserveQuota(utiltesting.MakeAdmission(string(tc.cqName)).Assignment(corev1.ResourceCPU, "default", tc.usage).Obj()).
+					Obj()
+				if !cache.AddOrUpdateWorkload(log, wl) {
+					t.Fatal("Failed adding workload")
+				}
+			}
+			got := cache.HeadroomFromStopping(tc.cqName, tc.policy)
+			if tc.wantNil {
+				if got != nil {
+					t.Errorf("Unexpected headroom: %v", got)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected headroom (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -58,9 +58,12 @@ func (t *tasCache) Clone() map[kueue.ResourceFlavorReference]*TASFlavorCache {
 	return maps.Clone(t.flavorCache)
 }
 
-func (t *tasCache) AddFlavor(flavor *kueue.ResourceFlavor) {
+// AddFlavor adds the flavor to the cache. It returns the flavors which
+// became synced, that is, for which Get started returning a cache.
+func (t *tasCache) AddFlavor(flavor *kueue.ResourceFlavor) []kueue.ResourceFlavorReference {
 	t.Lock()
 	defer t.Unlock()
+	var synced []kueue.ResourceFlavorReference
 	name := kueue.ResourceFlavorReference(flavor.Name)
 	if _, ok := t.flavors[name]; !ok {
 		flavorInfo := flavorInformation{
@@ -71,13 +74,18 @@ func (t *tasCache) AddFlavor(flavor *kueue.ResourceFlavor) {
 		t.flavors[name] = flavorInfo
 		if tInfo, ok := t.topologies[flavorInfo.TopologyName]; ok {
 			t.flavorCache[name] = t.NewTASFlavorCache(tInfo, flavorInfo)
+			synced = append(synced, name)
 		}
 	}
+	return synced
 }
 
-func (t *tasCache) AddTopology(topology *kueuealpha.Topology) {
+// AddTopology adds the topology to the cache. It returns the flavors
+// which became synced, that is, for which Get started returning a cache.
+func (t *tasCache) AddTopology(topology *kueuealpha.Topology) []kueue.ResourceFlavorReference {
 	t.Lock()
 	defer t.Unlock()
+	var synced []kueue.ResourceFlavorReference
 	name := kueue.TopologyReference(topology.Name)
 	if _, ok := t.topologies[name]; !ok {
 		tInfo := topologyInformation{
@@ -87,9 +95,12 @@ func (t *tasCache) AddTopology(topology *kueuealpha.Topology) {
 		for fName, flavorInfo := range t.flavors {
 			if flavorInfo.TopologyName == name {
 				t.flavorCache[fName] = t.NewTASFlavorCache(tInfo, flavorInfo)
+				synced = append(synced, fName)
 			}
 		}
 	}
+	slices.Sort(synced)
+	return synced
 }
 
 func (t *tasCache) DeleteFlavor(name kueue.ResourceFlavorReference) {
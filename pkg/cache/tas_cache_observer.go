@@ -0,0 +1,54 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/go-logr/logr"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// TASCacheObserver is notified when the tasCache of a flavor becomes
+// synced, once both the flavor and its topology are in the cache. The
+// notifications are sent while holding the cache lock, so observers must
+// not call the cache.
+type TASCacheObserver interface {
+	NotifyTASFlavorSynced(flavor kueue.ResourceFlavorReference)
+}
+
+// WithTASCacheObservers sets the observers notified when the tasCache of
+// a flavor becomes synced.
+func WithTASCacheObservers(observers ...TASCacheObserver) Option {
+	return func(o *options) {
+		o.tasCacheObservers = append(o.tasCacheObservers, observers...)
+	}
+}
+
+// tasFlavorsSynced accounts the delayed TAS usage of the ClusterQueues
+// using the flavors which became synced, and notifies the observers.
+func (c *Cache) tasFlavorsSynced(log logr.Logger, flavors []kueue.ResourceFlavorReference) {
+	for _, flavor := range flavors {
+		for _, cq := range c.hm.ClusterQueues() {
+			if _, found := cq.tasFlavors[flavor]; found {
+				cq.accountDelayedTASUsage(log)
+			}
+		}
+		for _, o := range c.tasCacheObservers {
+			o.NotifyTASFlavorSynced(flavor)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/features"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+type recordingTASCacheObserver struct {
+	synced []kueue.ResourceFlavorReference
+}
+
+func (o *recordingTASCacheObserver) NotifyTASFlavorSynced(flavor kueue.ResourceFlavorReference) {
+	o.synced = append(o.synced, flavor)
+}
+
+func TestTASCacheObserver(t *testing.T) {
+	features.SetFeatureGateDuringTest(t, features.TopologyAwareScheduling, true)
+	_, log := utiltesting.ContextWithLog(t)
+	observer := &recordingTASCacheObserver{}
+	cache := New(utiltesting.NewFakeClient(), WithTASCacheObservers(observer))
+
+	steps := []struct {
+		name string
+		do   func()
+		want []kueue.ResourceFlavorReference
+	}{
+		{
+			name: "flavor added before its topology",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("tas-a").TopologyName("default").Obj())
+			},
+		},
+		{
+			name: "topology added",
+			do: func() {
+				cache.AddOrUpdateTopology(log, utiltesting.MakeDefaultOneLevelTopology("default"))
+			},
+			want: []kueue.ResourceFlavorReference{"tas-a"},
+		},
+		{
+			name: "flavor added after its topology",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("tas-b").TopologyName("default").Obj())
+			},
+			want: []kueue.ResourceFlavorReference{"tas-a", "tas-b"},
+		},
+		{
+			name: "synced flavor and topology updated",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("tas-a").TopologyName("default").Obj())
+				cache.AddOrUpdateTopology(log, utiltesting.MakeDefaultOneLevelTopology("default"))
+			},
+			want: []kueue.ResourceFlavorReference{"tas-a", "tas-b"},
+		},
+		{
+			name: "flavor without topology added",
+			do: func() {
+				cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			},
+			want: []kueue.ResourceFlavorReference{"tas-a", "tas-b"},
+		},
+	}
+	for _, step := range steps {
+		step.do()
+		if diff := cmp.Diff(step.want, observer.synced); diff != "" {
+			t.Errorf("After step %q, unexpected synced flavors (-want,+got):\n%s", step.name, diff)
+		}
+	}
+}
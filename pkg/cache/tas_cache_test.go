@@ -2271,3 +2271,80 @@ func buildWantedResult(wantAssignment *kueue.TopologyAssignment) tasPodSetAssign
 	wantPodSetResult.TopologyAssignment = wantAssignment
 	return wantPodSetResult
 }
+
+func TestFindTopologyAssignmentSkipsUnreadyNodes(t *testing.T) {
+	const (
+		tasBlockLabel = "cloud.com/topology-block"
+		tasRackLabel  = "cloud.com/topology-rack"
+	)
+	makeNode := func(name, rack, cpu string) *testingnode.NodeWrapper {
+		return testingnode.MakeNode(name).
+			Label(tasBlockLabel, "b1").
+			Label(tasRackLabel, rack).
+			StatusAllocatable(corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse(cpu),
+				corev1.ResourcePods: resource.MustParse("10"),
+			})
+	}
+	levels := []string{tasBlockLabel, tasRackLabel}
+	tasInput := buildTASInput("main", &kueue.PodSetTopologyRequest{Required: ptr.To(tasRackLabel)}, resources.Requests{corev1.ResourceCPU: 1000}, 3)
+	wantAssignment := func(rack string) TASAssignmentsResult {
+		return TASAssignmentsResult{
+			"main": buildWantedResult(&kueue.TopologyAssignment{
+				Levels:  levels,
+				Domains: []kueue.TopologyDomainAssignment{{Count: 3, Values: []string{"b1", rack}}},
+			}),
+		}
+	}
+
+	cases := map[string]struct {
+		nodes           []corev1.Node
+		wantAssignments TASAssignmentsResult
+	}{
+		"all nodes ready": {
+			nodes: []corev1.Node{
+				*makeNode("r1-a", "r1", "2").Ready().Obj(),
+				*makeNode("r1-b", "r1", "2").Ready().Obj(),
+				*makeNode("r2", "r2", "4").Ready().Obj(),
+			},
+			wantAssignments: wantAssignment("r1"),
+		},
+		"domain with all its nodes unready is excluded": {
+			nodes: []corev1.Node{
+				*makeNode("r1-a", "r1", "2").NotReady().Obj(),
+				*makeNode("r1-b", "r1", "2").NotReady().Obj(),
+				*makeNode("r2", "r2", "4").Ready().Obj(),
+			},
+			wantAssignments: wantAssignment("r2"),
+		},
+		"capacity of the unready node is excluded": {
+			nodes: []corev1.Node{
+				*makeNode("r1-a", "r1", "2").Ready().Obj(),
+				*makeNode("r1-b", "r1", "2").NotReady().Obj(),
+				*makeNode("r2", "r2", "4").Ready().Obj(),
+			},
+			wantAssignments: wantAssignment("r2"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := utiltesting.ContextWithLog(t)
+			clientBuilder := utiltesting.NewClientBuilder()
+			for i := range tc.nodes {
+				clientBuilder.WithObjects(&tc.nodes[i])
+			}
+			_ = tasindexer.SetupIndexes(ctx, utiltesting.AsIndexer(clientBuilder))
+			tasCache := NewTASCache(clientBuilder.Build())
+			tasFlavorCache := tasCache.NewTASFlavorCache(topologyInformation{Levels: levels}, flavorInformation{TopologyName: "default"})
+
+			snapshot, err := tasFlavorCache.snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed to build the snapshot: %v", err)
+			}
+			gotAssignments := snapshot.FindTopologyAssignmentsForFlavor(FlavorTASRequests{tasInput}, false)
+			if diff := cmp.Diff(tc.wantAssignments, gotAssignments); diff != "" {
+				t.Errorf("Unexpected topology assignment (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -101,6 +101,8 @@ func (c *TASFlavorCache) snapshot(ctx context.Context) (*TASFlavorSnapshot, erro
 	var requiredLabels client.MatchingLabels = maps.Clone(c.flavor.NodeLabels)
 	var requiredLabelKeys client.HasLabels = slices.Clone(c.topology.Levels)
 
+	// Only the ready nodes are listed, so the capacity of the unready nodes
+	// is left out and the domains without ready nodes get no pods.
 	err := c.client.List(ctx, nodes, requiredLabels, requiredLabelKeys, client.MatchingFields{
 		indexer.ReadyNode:       "true",
 		indexer.SchedulableNode: "true",
@@ -0,0 +1,90 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// BestPackedTASFlavor returns the TAS flavor of the ClusterQueue in which
+// the PodSets of the workload requesting TAS pack into the fewest
+// topology domains, among the flavors with enough available quota and
+// topology capacity for them. Ties are broken by the order of the flavors
+// in the ResourceGroups. It returns false if the workload doesn't request
+// TAS, or if it doesn't fit in any TAS flavor.
+func (c *ClusterQueueSnapshot) BestPackedTASFlavor(wl *workload.Info) (kueue.ResourceFlavorReference, bool) {
+	var tasPodSets []int
+	for i := range wl.Obj.Spec.PodSets {
+		if wl.Obj.Spec.PodSets[i].TopologyRequest != nil || c.IsTASOnly() {
+			tasPodSets = append(tasPodSets, i)
+		}
+	}
+	if len(tasPodSets) == 0 {
+		return "", false
+	}
+	requests := resources.Requests{}
+	for _, i := range tasPodSets {
+		requests.Add(wl.TotalRequests[i].Requests)
+	}
+
+	var best kueue.ResourceFlavorReference
+	bestDomains := -1
+	for _, rg := range c.ResourceGroups {
+		for _, flavor := range rg.Flavors {
+			tasFlavor := c.TASFlavors[flavor]
+			if tasFlavor == nil || !c.fitsAvailable(flavor, requests) {
+				continue
+			}
+			flavorTASRequests := make(FlavorTASRequests, 0, len(tasPodSets))
+			for _, i := range tasPodSets {
+				psResources := wl.TotalRequests[i]
+				flavorTASRequests = append(flavorTASRequests, TASPodSetRequests{
+					PodSet:            &wl.Obj.Spec.PodSets[i],
+					SinglePodRequests: psResources.SinglePodRequests(),
+					Count:             psResources.Count,
+					Flavor:            flavor,
+					Implied:           wl.Obj.Spec.PodSets[i].TopologyRequest == nil,
+				})
+			}
+			result := tasFlavor.FindTopologyAssignmentsForFlavor(flavorTASRequests, false)
+			if result.Failure() != nil {
+				continue
+			}
+			domains := 0
+			for _, psAssignment := range result {
+				domains += len(psAssignment.TopologyAssignment.Domains)
+			}
+			if bestDomains < 0 || domains < bestDomains {
+				best, bestDomains = flavor, domains
+			}
+		}
+	}
+	return best, bestDomains >= 0
+}
+
+// fitsAvailable indicates whether the requests fit in the quota of the
+// flavor currently available to the ClusterQueue.
+func (c *ClusterQueueSnapshot) fitsAvailable(flavor kueue.ResourceFlavorReference, requests resources.Requests) bool {
+	for r, q := range requests {
+		if q > c.Available(resources.FlavorResource{Flavor: flavor, Resource: r}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	testingnode "sigs.k8s.io/kueue/pkg/util/testingjobs/node"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestBestPackedTASFlavor(t *testing.T) {
+	const tasBlockLabel = "cloud.com/topology-block"
+	levels := []string{tasBlockLabel, corev1.LabelHostname}
+	node := func(name, block, cpu string) corev1.Node {
+		return *testingnode.MakeNode(name).
+			Label(tasBlockLabel, block).
+			Label(corev1.LabelHostname, name).
+			StatusAllocatable(corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse(cpu),
+				corev1.ResourcePods: resource.MustParse("10"),
+			}).
+			Ready().
+			Obj()
+	}
+	// "spread" has two hosts with 1 CPU each, "packed" has one host with 2 CPUs.
+	flavorNodes := map[kueue.ResourceFlavorReference][]corev1.Node{
+		"spread": {node("x1", "b1", "1"), node("x2", "b1", "1")},
+		"packed": {node("x3", "b2", "2")},
+	}
+
+	cases := map[string]struct {
+		quota      string
+		podSet     *kueue.PodSet
+		wantFlavor kueue.ResourceFlavorReference
+		wantFound  bool
+	}{
+		"both flavors fit, one packs into fewer domains": {
+			quota: "4",
+			podSet: utiltesting.MakePodSet("main", 2).
+				PreferredTopologyRequest(tasBlockLabel).
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+			wantFlavor: "packed",
+			wantFound:  true,
+		},
+		"both flavors pack into the same number of domains": {
+			quota: "4",
+			podSet: utiltesting.MakePodSet("main", 1).
+				PreferredTopologyRequest(tasBlockLabel).
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+			wantFlavor: "spread",
+			wantFound:  true,
+		},
+		"only one flavor fits the topology": {
+			quota: "4",
+			podSet: utiltesting.MakePodSet("main", 1).
+				PreferredTopologyRequest(tasBlockLabel).
+				Request(corev1.ResourceCPU, "2").
+				Obj(),
+			wantFlavor: "packed",
+			wantFound:  true,
+		},
+		"the packed flavor lacks quota": {
+			quota: "1",
+			podSet: utiltesting.MakePodSet("main", 1).
+				PreferredTopologyRequest(tasBlockLabel).
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+			wantFlavor: "spread",
+			wantFound:  true,
+		},
+		"no flavor fits": {
+			quota: "4",
+			podSet: utiltesting.MakePodSet("main", 4).
+				RequiredTopologyRequest(tasBlockLabel).
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+		},
+		"no TAS requested": {
+			quota: "4",
+			podSet: utiltesting.MakePodSet("main", 1).
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spread").Obj())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("packed").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("spread").Resource(corev1.ResourceCPU, "4").Obj(),
+					*utiltesting.MakeFlavorQuotas("packed").Resource(corev1.ResourceCPU, tc.quota).Obj(),
+				).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cqSnapshot := snapshot.ClusterQueue("cq")
+			cqSnapshot.TASFlavors = make(map[kueue.ResourceFlavorReference]*TASFlavorSnapshot)
+			for flavor, nodes := range flavorNodes {
+				tasFlavor := newTASFlavorSnapshot(log, "topology", levels, nil)
+				for _, n := range nodes {
+					tasFlavor.addNode(n)
+				}
+				tasFlavor.initialize()
+				cqSnapshot.TASFlavors[flavor] = tasFlavor
+			}
+			wl := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").PodSets(*tc.podSet).Obj())
+			gotFlavor, gotFound := cqSnapshot.BestPackedTASFlavor(wl)
+			if gotFlavor != tc.wantFlavor || gotFound != tc.wantFound {
+				t.Errorf("Unexpected flavor, want=(%q, %v), got=(%q, %v)", tc.wantFlavor, tc.wantFound, gotFlavor, gotFound)
+			}
+		})
+	}
+}
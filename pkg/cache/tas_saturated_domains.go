@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"slices"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltas "sigs.k8s.io/kueue/pkg/util/tas"
+)
+
+// SaturatedTASDomains returns the sorted IDs of the lowest-level topology
+// domains of the TAS flavor whose TAS usage reached their capacity for
+// some resource. The capacity is computed from the nodes, which are listed
+// with the client. It returns nil if the flavor isn't a TAS flavor.
+func (c *Cache) SaturatedTASDomains(ctx context.Context, flavor kueue.ResourceFlavorReference) ([]string, error) {
+	flavorCache := c.tasCache.Get(flavor)
+	if flavorCache == nil {
+		return nil, nil
+	}
+	snapshot, err := flavorCache.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var saturated []string
+	for _, id := range snapshot.SaturatedDomains() {
+		saturated = append(saturated, string(id))
+	}
+	return saturated, nil
+}
+
+// SaturatedDomains returns the sorted IDs of the lowest-level topology
+// domains in the snapshot whose TAS usage reached the capacity left by
+// the non-TAS pods for some resource.
+func (s *TASFlavorSnapshot) SaturatedDomains() []utiltas.TopologyDomainID {
+	var saturated []utiltas.TopologyDomainID
+	for id, leaf := range s.leaves {
+		for rName, used := range leaf.tasUsage {
+			if used > 0 && used >= leaf.freeCapacity[rName] {
+				saturated = append(saturated, id)
+				break
+			}
+		}
+	}
+	slices.Sort(saturated)
+	return saturated
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	tasindexer "sigs.k8s.io/kueue/pkg/controller/tas/indexer"
+	"sigs.k8s.io/kueue/pkg/features"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	testingnode "sigs.k8s.io/kueue/pkg/util/testingjobs/node"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestSaturatedTASDomains(t *testing.T) {
+	features.SetFeatureGateDuringTest(t, features.TopologyAwareScheduling, true)
+	nodes := []corev1.Node{
+		*testingnode.MakeNode("x1").
+			Label(corev1.LabelHostname, "x1").
+			StatusAllocatable(corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("2"),
+				corev1.ResourcePods: resource.MustParse("10"),
+			}).
+			Ready().
+			Obj(),
+		*testingnode.MakeNode("x2").
+			Label(corev1.LabelHostname, "x2").
+			StatusAllocatable(corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("2"),
+				corev1.ResourcePods: resource.MustParse("2"),
+			}).
+			Ready().
+			Obj(),
+		*testingnode.MakeNode("x3").
+			Label(corev1.LabelHostname, "x3").
+			StatusAllocatable(corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("4"),
+				corev1.ResourcePods: resource.MustParse("10"),
+			}).
+			Ready().
+			Obj(),
+	}
+	usage := func(node string, cpu int64, count int32) workload.TopologyDomainRequests {
+		return workload.TopologyDomainRequests{
+			Values:            []string{node},
+			SinglePodRequests: resources.Requests{corev1.ResourceCPU: cpu},
+			Count:             count,
+		}
+	}
+
+	cases := map[string]struct {
+		flavor string
+		usage  []workload.TopologyDomainRequests
+		want   []string
+	}{
+		"no usage": {
+			flavor: "tas",
+		},
+		"domains with capacity left": {
+			flavor: "tas",
+			usage:  []workload.TopologyDomainRequests{usage("x1", 1_000, 1), usage("x3", 1_000, 3)},
+		},
+		"domains at capacity": {
+			flavor: "tas",
+			usage: []workload.TopologyDomainRequests{
+				usage("x1", 1_000, 2),
+				usage("x2", 500, 2),
+				usage("x3", 1_000, 3),
+			},
+			want: []string{"x1", "x2"},
+		},
+		"not a TAS flavor": {
+			flavor: "default",
+			usage:  []workload.TopologyDomainRequests{usage("x1", 1_000, 2)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			clientBuilder := utiltesting.NewClientBuilder()
+			for i := range nodes {
+				clientBuilder.WithObjects(&nodes[i])
+			}
+			_ = tasindexer.SetupIndexes(ctx, utiltesting.AsIndexer(clientBuilder))
+			cache := New(clientBuilder.Build())
+			cache.AddOrUpdateTopology(log, utiltesting.MakeDefaultOneLevelTopology("default"))
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("tas").TopologyName("default").Obj())
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cache.tasCache.Get("tas").addUsage(tc.usage)
+			got, err := cache.SaturatedTASDomains(ctx, kueue.ResourceFlavorReference(tc.flavor))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected saturated domains (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
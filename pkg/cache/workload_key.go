@@ -0,0 +1,64 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// WorkloadKeyFunc returns the key identifying a workload in the cache.
+type WorkloadKeyFunc func(*kueue.Workload) string
+
+// WithWorkloadKeyFunc sets the function computing the keys of the workloads
+// in the cache, for example to prefix them with the cluster they come from
+// so that workloads with the same namespace and name in different clusters
+// don't collide. By default, workload.Key is used.
+func WithWorkloadKeyFunc(f WorkloadKeyFunc) Option {
+	return func(o *options) {
+		o.workloadKeyFunc = f
+	}
+}
+
+// WorkloadKey returns the key of the workload in the cache. The callers of
+// the methods accepting a workload key must use it to compute the key.
+func (c *Cache) WorkloadKey(w *kueue.Workload) string {
+	return workloadKey(c.workloadKeyFunc, w)
+}
+
+// WorkloadKey returns the key of the workload in the snapshot, as computed
+// by the cache the snapshot was taken from.
+func (s *Snapshot) WorkloadKey(w *kueue.Workload) string {
+	return workloadKey(s.workloadKeyFunc, w)
+}
+
+func (c *clusterQueue) workloadKey(w *kueue.Workload) string {
+	return workloadKey(c.workloadKeyFunc, w)
+}
+
+func (c *ClusterQueueSnapshot) workloadKey(w *kueue.Workload) string {
+	return workloadKey(c.workloadKeyFunc, w)
+}
+
+// workloadKey returns the key of the workload computed by f, falling back
+// to workload.Key when f is not set.
+func workloadKey(f WorkloadKeyFunc, w *kueue.Workload) string {
+	if f == nil {
+		return workload.Key(w)
+	}
+	return f(w)
+}
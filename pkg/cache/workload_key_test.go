@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/resources"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestWorkloadKeyFunc(t *testing.T) {
+	const clusterLabel = "example.com/cluster"
+	clusterKey := func(w *kueue.Workload) string {
+		return w.Labels[clusterLabel] + "/" + workload.Key(w)
+	}
+	fr := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	makeWorkload := func(cluster string) *kueue.Workload {
+		return utiltesting.MakeWorkload("wl", "ns").
+			Label(clusterLabel, cluster).
+			Request(corev1.ResourceCPU, "1").
+			ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "1").Obj()).
+			Obj()
+	}
+
+	cases := map[string]struct {
+		opts           []Option
+		wantKeys       []string
+		wantUsage      int64
+		wantUsageAfter int64
+	}{
+		"default key": {
+			wantKeys:       []string{"ns/wl"},
+			wantUsage:      1_000,
+			wantUsageAfter: 0,
+		},
+		"cluster prefixed key": {
+			opts:           []Option{WithWorkloadKeyFunc(clusterKey)},
+			wantKeys:       []string{"east/ns/wl", "west/ns/wl"},
+			wantUsage:      2_000,
+			wantUsageAfter: 1_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cache := New(utiltesting.NewFakeClient(), tc.opts...)
+			cache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+				Obj()
+			if err := cache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed adding ClusterQueue: %v", err)
+			}
+			east, west := makeWorkload("east"), makeWorkload("west")
+			cache.AddOrUpdateWorkload(log, east)
+			cache.AddOrUpdateWorkload(log, west)
+
+			snapshot, err := cache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Failed taking snapshot: %v", err)
+			}
+			cqSnapshot := snapshot.ClusterQueue("cq")
+			gotKeys := slices.Sorted(maps.Keys(cqSnapshot.Workloads))
+			if diff := cmp.Diff(tc.wantKeys, gotKeys); diff != "" {
+				t.Errorf("Unexpected workload keys (-want,+got):\n%s", diff)
+			}
+			if got := cqSnapshot.ResourceNode.Usage[fr]; got != tc.wantUsage {
+				t.Errorf("Unexpected usage, want=%d, got=%d", tc.wantUsage, got)
+			}
+			snapshot.RemoveWorkload(workload.NewInfo(west))
+			if got := cqSnapshot.ResourceNode.Usage[fr]; got != tc.wantUsageAfter {
+				t.Errorf("Unexpected usage after removing the workload from the snapshot, want=%d, got=%d", tc.wantUsageAfter, got)
+			}
+
+			if err := cache.DeleteWorkload(log, west); err != nil {
+				t.Fatalf("Failed deleting workload: %v", err)
+			}
+			if got := cache.hm.ClusterQueue("cq").resourceNode.Usage[fr]; got != tc.wantUsageAfter {
+				t.Errorf("Unexpected usage after deleting the workload, want=%d, got=%d", tc.wantUsageAfter, got)
+			}
+		})
+	}
+}
@@ -53,6 +53,7 @@ var (
 	integrationsExternalFrameworkPath = integrationsPath.Child("externalFrameworks")
 	podOptionsPath                    = integrationsPath.Child("podOptions")
 	podOptionsNamespaceSelectorPath   = podOptionsPath.Child("namespaceSelector")
+	rayClusterOptionsPath             = integrationsPath.Child("rayClusterOptions")
 	managedJobsNamespaceSelectorPath  = field.NewPath("managedJobsNamespaceSelector")
 	waitForPodsReadyPath              = field.NewPath("waitForPodsReady")
 	requeuingStrategyPath             = waitForPodsReadyPath.Child("requeuingStrategy")
@@ -203,6 +204,11 @@ func validateIntegrations(c *configapi.Configuration, scheme *runtime.Scheme) fi
 	}
 
 	allErrs = append(allErrs, validatePodIntegrationOptions(c)...)
+	if opts := c.Integrations.RayClusterOptions; opts != nil && opts.WorkerReplicasBasis != "" &&
+		opts.WorkerReplicasBasis != configapi.WorkerReplicasCurrent && opts.WorkerReplicasBasis != configapi.WorkerReplicasMin {
+		allErrs = append(allErrs, field.NotSupported(rayClusterOptionsPath.Child("workerReplicasBasis"),
+			opts.WorkerReplicasBasis, []configapi.WorkerReplicasBasis{configapi.WorkerReplicasCurrent, configapi.WorkerReplicasMin}))
+	}
 	return allErrs
 }
 
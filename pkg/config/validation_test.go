@@ -417,6 +417,23 @@ func TestValidate(t *testing.T) {
 			wantErr:                nil,
 			managedJobsFeatureGate: true,
 		},
+		"unsupported integrations.rayClusterOptions.workerReplicasBasis": {
+			cfg: &configapi.Configuration{
+				Integrations: &configapi.Integrations{
+					Frameworks: []string{"ray.io/raycluster"},
+					PodOptions: defaultPodIntegrationOptions,
+					RayClusterOptions: &configapi.RayClusterIntegrationOptions{
+						WorkerReplicasBasis: "Max",
+					},
+				},
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeNotSupported,
+					Field: "integrations.rayClusterOptions.workerReplicasBasis",
+				},
+			},
+		},
 		"no supported waitForPodsReady.requeuingStrategy.timestamp": {
 			cfg: &configapi.Configuration{
 				Integrations: defaultIntegrations,
@@ -255,7 +255,7 @@ func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	// If the workload is admitted, updating the status here would set the Admitted condition to
 	// false before the workloads eviction.
-	if !workload.IsAdmitted(&wl) && workload.SyncAdmittedCondition(&wl, r.clock.Now()) {
+	if !workload.IsAdmitted(&wl) && workload.SyncAdmittedConditionWithChecks(&wl, r.cache.AdmissionChecksSatisfied(&wl), r.clock.Now()) {
 		if err := workload.ApplyAdmissionStatus(ctx, r.client, &wl, true, r.clock); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -646,11 +646,16 @@ func (r *WorkloadReconciler) Delete(e event.TypedDeleteEvent[*kueue.Workload]) b
 				}
 			}
 		})
+	} else {
+		// Release the quota earmarked for the workload, if it was waiting
+		// for its preemptions to complete.
+		r.cache.CancelPreemption(log, r.cache.WorkloadKey(e.Object))
 	}
 
 	// Even if the state is unknown, the last cached state tells us whether the
 	// workload was in the queues and should be cleared from them.
 	r.queues.DeleteWorkload(e.Object)
+	r.cache.ForgetRequeueCount(r.cache.WorkloadKey(e.Object))
 
 	return true
 }
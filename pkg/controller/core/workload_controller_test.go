@@ -395,14 +395,15 @@ func TestReconcile(t *testing.T) {
 	fakeClock := testingclock.NewFakeClock(testStartTime)
 
 	cases := map[string]struct {
-		workload       *kueue.Workload
-		cq             *kueue.ClusterQueue
-		lq             *kueue.LocalQueue
-		wantWorkload   *kueue.Workload
-		wantError      error
-		wantEvents     []utiltesting.EventRecord
-		wantResult     reconcile.Result
-		reconcilerOpts []Option
+		workload                *kueue.Workload
+		cq                      *kueue.ClusterQueue
+		admissionCheckSemantics cache.AdmissionCheckSemantics
+		lq                      *kueue.LocalQueue
+		wantWorkload            *kueue.Workload
+		wantError               error
+		wantEvents              []utiltesting.EventRecord
+		wantResult              reconcile.Result
+		reconcilerOpts          []Option
 	}{
 		"assign Admission Checks from ClusterQueue.spec.AdmissionCheckStrategy": {
 			workload: utiltesting.MakeWorkload("wl", "ns").
@@ -483,6 +484,63 @@ func TestReconcile(t *testing.T) {
 				},
 			},
 		},
+		"admit with any admission check ready": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuotaAt(utiltesting.MakeAdmission("cq").Obj(), testStartTime).
+				Queue("queue").
+				AdmissionChecks(
+					kueue.AdmissionCheckState{Name: "ac1", State: kueue.CheckStatePending},
+					kueue.AdmissionCheckState{Name: "ac2", State: kueue.CheckStateReady},
+				).
+				Obj(),
+			cq:                      utiltesting.MakeClusterQueue("cq").AdmissionChecks("ac1", "ac2").Obj(),
+			admissionCheckSemantics: cache.AdmissionCheckAny,
+			lq:                      utiltesting.MakeLocalQueue("queue", "ns").ClusterQueue("cq").Obj(),
+			wantWorkload: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).
+				Queue("queue").
+				AdmissionChecks(
+					kueue.AdmissionCheckState{Name: "ac1", State: kueue.CheckStatePending},
+					kueue.AdmissionCheckState{Name: "ac2", State: kueue.CheckStateReady},
+				).
+				Condition(metav1.Condition{
+					Type:    "Admitted",
+					Status:  "True",
+					Reason:  "Admitted",
+					Message: "The workload is admitted",
+				}).
+				Obj(),
+			wantEvents: []utiltesting.EventRecord{
+				{
+					Key:       types.NamespacedName{Namespace: "ns", Name: "wl"},
+					EventType: "Normal",
+					Reason:    "Admitted",
+					Message: fmt.Sprintf("Admitted by ClusterQueue cq, wait time since reservation was %.0fs",
+						fakeClock.Since(metav1.NewTime(testStartTime).Time.Truncate(time.Second)).Seconds()),
+				},
+			},
+		},
+		"don't admit with all admission checks required and one pending": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).
+				Queue("queue").
+				AdmissionChecks(
+					kueue.AdmissionCheckState{Name: "ac1", State: kueue.CheckStatePending},
+					kueue.AdmissionCheckState{Name: "ac2", State: kueue.CheckStateReady},
+				).
+				Obj(),
+			cq:                      utiltesting.MakeClusterQueue("cq").AdmissionChecks("ac1", "ac2").Obj(),
+			admissionCheckSemantics: cache.AdmissionCheckAll,
+			lq:                      utiltesting.MakeLocalQueue("queue", "ns").ClusterQueue("cq").Obj(),
+			wantWorkload: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Obj()).
+				Queue("queue").
+				AdmissionChecks(
+					kueue.AdmissionCheckState{Name: "ac1", State: kueue.CheckStatePending},
+					kueue.AdmissionCheckState{Name: "ac2", State: kueue.CheckStateReady},
+				).
+				Obj(),
+		},
 		"already admitted": {
 			workload: utiltesting.MakeWorkload("wl", "ns").
 				ReserveQuota(utiltesting.MakeAdmission("q1").Obj()).
@@ -1721,6 +1779,14 @@ func TestReconcile(t *testing.T) {
 				if err := qManager.AddClusterQueue(ctx, tc.cq); err != nil {
 					t.Errorf("couldn't add the cluster queue to the cache: %v", err)
 				}
+				if tc.admissionCheckSemantics != "" {
+					if err := cqCache.AddClusterQueue(ctx, tc.cq); err != nil {
+						t.Errorf("couldn't add the cluster queue to the cache: %v", err)
+					}
+					if err := cqCache.SetAdmissionCheckSemantics(kueue.ClusterQueueReference(tc.cq.Name), tc.admissionCheckSemantics); err != nil {
+						t.Errorf("couldn't set the admission check semantics: %v", err)
+					}
+				}
 			}
 
 			if tc.lq != nil {
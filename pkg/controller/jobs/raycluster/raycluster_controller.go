@@ -28,9 +28,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1beta1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/controller/jobframework"
 	"sigs.k8s.io/kueue/pkg/features"
@@ -73,7 +75,32 @@ func NewJob() jobframework.GenericJob {
 	return &RayCluster{}
 }
 
-var NewReconciler = jobframework.NewGenericReconcilerFactory(NewJob)
+func NewReconciler(client client.Client, record record.EventRecorder, opts ...jobframework.Option) jobframework.JobReconcilerInterface {
+	basis := workerReplicasBasis(jobframework.ProcessOptions(opts...).IntegrationOptions)
+	return jobframework.NewGenericReconcilerFactory(func() jobframework.GenericJob {
+		return &rayClusterWithOptions{RayCluster: &RayCluster{}, workerReplicasBasis: basis}
+	})(client, record, opts...)
+}
+
+// workerReplicasBasis returns the WorkerReplicasBasis configured in the
+// RayClusterIntegrationOptions, or WorkerReplicasCurrent if none is set.
+func workerReplicasBasis(integrationOpts map[string]any) configapi.WorkerReplicasBasis {
+	if opts, ok := integrationOpts[gvk.String()].(*configapi.RayClusterIntegrationOptions); ok && opts.WorkerReplicasBasis != "" {
+		return opts.WorkerReplicasBasis
+	}
+	return configapi.WorkerReplicasCurrent
+}
+
+// rayClusterWithOptions is a RayCluster whose PodSets account for the
+// worker groups according to the configured WorkerReplicasBasis.
+type rayClusterWithOptions struct {
+	*RayCluster
+	workerReplicasBasis configapi.WorkerReplicasBasis
+}
+
+func (j *rayClusterWithOptions) PodSets() ([]kueue.PodSet, error) {
+	return j.podSets(j.workerReplicasBasis)
+}
 
 type RayCluster rayv1.RayCluster
 
@@ -105,6 +132,10 @@ func (j *RayCluster) PodLabelSelector() string {
 }
 
 func (j *RayCluster) PodSets() ([]kueue.PodSet, error) {
+	return j.podSets(configapi.WorkerReplicasCurrent)
+}
+
+func (j *RayCluster) podSets(basis configapi.WorkerReplicasBasis) ([]kueue.PodSet, error) {
 	// len = workerGroups + head
 	podSets := make([]kueue.PodSet, len(j.Spec.WorkerGroupSpecs)+1)
 
@@ -129,6 +160,9 @@ func (j *RayCluster) PodSets() ([]kueue.PodSet, error) {
 		if wgs.Replicas != nil {
 			count = *wgs.Replicas
 		}
+		if count == 0 && basis == configapi.WorkerReplicasMin && wgs.MinReplicas != nil {
+			count = *wgs.MinReplicas
+		}
 		if wgs.NumOfHosts > 1 {
 			count *= wgs.NumOfHosts
 		}
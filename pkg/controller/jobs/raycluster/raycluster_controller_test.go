@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1beta1"
 	kueuealpha "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/controller/jobframework"
@@ -61,6 +62,7 @@ func TestPodSets(t *testing.T) {
 		rayCluster                    *RayCluster
 		wantPodSets                   func(rayJob *RayCluster) []kueue.PodSet
 		enableTopologyAwareScheduling bool
+		integrationOptions            map[string]any
 	}{
 		"no annotations": {
 			rayCluster: (*RayCluster)(testingrayutil.MakeCluster("raycluster", "ns").
@@ -278,11 +280,69 @@ func TestPodSets(t *testing.T) {
 			},
 			enableTopologyAwareScheduling: false,
 		},
+		"zero-replica autoscaling worker group; current replicas basis": {
+			rayCluster: (*RayCluster)(testingrayutil.MakeCluster("raycluster", "ns").
+				WithAutoscalingReplicas("workers-group-0", 0, 2, 10).
+				Obj()),
+			wantPodSets: func(rayJob *RayCluster) []kueue.PodSet {
+				return []kueue.PodSet{
+					*utiltesting.MakePodSet(headGroupPodSetName, 1).
+						PodSpec(*rayJob.Spec.HeadGroupSpec.Template.Spec.DeepCopy()).
+						Obj(),
+					*utiltesting.MakePodSet("workers-group-0", 0).
+						PodSpec(*rayJob.Spec.WorkerGroupSpecs[0].Template.Spec.DeepCopy()).
+						Obj(),
+				}
+			},
+			integrationOptions: map[string]any{
+				gvk.String(): &configapi.RayClusterIntegrationOptions{WorkerReplicasBasis: configapi.WorkerReplicasCurrent},
+			},
+		},
+		"zero-replica autoscaling worker group; min replicas basis": {
+			rayCluster: (*RayCluster)(testingrayutil.MakeCluster("raycluster", "ns").
+				WithAutoscalingReplicas("workers-group-0", 0, 2, 10).
+				Obj()),
+			wantPodSets: func(rayJob *RayCluster) []kueue.PodSet {
+				return []kueue.PodSet{
+					*utiltesting.MakePodSet(headGroupPodSetName, 1).
+						PodSpec(*rayJob.Spec.HeadGroupSpec.Template.Spec.DeepCopy()).
+						Obj(),
+					*utiltesting.MakePodSet("workers-group-0", 2).
+						PodSpec(*rayJob.Spec.WorkerGroupSpecs[0].Template.Spec.DeepCopy()).
+						Obj(),
+				}
+			},
+			integrationOptions: map[string]any{
+				gvk.String(): &configapi.RayClusterIntegrationOptions{WorkerReplicasBasis: configapi.WorkerReplicasMin},
+			},
+		},
+		"scaled autoscaling worker group; min replicas basis": {
+			rayCluster: (*RayCluster)(testingrayutil.MakeCluster("raycluster", "ns").
+				WithAutoscalingReplicas("workers-group-0", 3, 2, 10).
+				Obj()),
+			wantPodSets: func(rayJob *RayCluster) []kueue.PodSet {
+				return []kueue.PodSet{
+					*utiltesting.MakePodSet(headGroupPodSetName, 1).
+						PodSpec(*rayJob.Spec.HeadGroupSpec.Template.Spec.DeepCopy()).
+						Obj(),
+					*utiltesting.MakePodSet("workers-group-0", 3).
+						PodSpec(*rayJob.Spec.WorkerGroupSpecs[0].Template.Spec.DeepCopy()).
+						Obj(),
+				}
+			},
+			integrationOptions: map[string]any{
+				gvk.String(): &configapi.RayClusterIntegrationOptions{WorkerReplicasBasis: configapi.WorkerReplicasMin},
+			},
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			features.SetFeatureGateDuringTest(t, features.TopologyAwareScheduling, tc.enableTopologyAwareScheduling)
-			gotPodSets, err := tc.rayCluster.PodSets()
+			job := &rayClusterWithOptions{
+				RayCluster:          tc.rayCluster,
+				workerReplicasBasis: workerReplicasBasis(tc.integrationOptions),
+			}
+			gotPodSets, err := job.PodSets()
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -169,6 +169,11 @@ const (
 	//
 	// Enable hierarchical cohorts
 	HierarchicalCohorts featuregate.Feature = "HierarchicalCohorts"
+
+	// Enable earmarking, for the preemptor, the quota released by the
+	// workloads it preempted, so that it can't be taken by other workloads
+	// while the preemptions complete.
+	PreemptionGraceAccounting featuregate.Feature = "PreemptionGraceAccounting"
 )
 
 func init() {
@@ -261,6 +266,9 @@ var defaultVersionedFeatureGates = map[featuregate.Feature]featuregate.Versioned
 	HierarchicalCohorts: {
 		{Version: version.MustParse("0.11"), Default: true, PreRelease: featuregate.Beta},
 	},
+	PreemptionGraceAccounting: {
+		{Version: version.MustParse("0.11"), Default: false, PreRelease: featuregate.Alpha},
+	},
 }
 
 func SetFeatureGateDuringTest(tb testing.TB, f featuregate.Feature, value bool) {
@@ -206,6 +206,15 @@ The label 'result' can have the following values:
 		}, []string{"name", "namespace"},
 	)
 
+	admissionCheckWaitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: constants.KueueName,
+			Name:      "admission_check_wait_seconds",
+			Help:      "The time from when a workload got the quota reservation until all its admission checks passed, as observed by the cache, per 'cluster_queue'",
+			Buckets:   generateExponentialBuckets(14),
+		}, []string{"cluster_queue"},
+	)
+
 	EvictedWorkloadsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Subsystem: constants.KueueName,
@@ -333,6 +342,24 @@ For a LocalQueue, the metric only reports a value of 1 for one of the statuses.`
 		}, []string{"name", "namespace", "flavor", "resource"},
 	)
 
+	ClusterQueueResourceOvercommit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: constants.KueueName,
+			Name:      "cluster_queue_resource_overcommit",
+			Help: `Reports the cluster_queue's resource usage above the nominal quota within all the flavors.
+If zero, it means that the cluster_queue is not borrowing the resource of the flavor.`,
+		}, []string{"cohort", "cluster_queue", "flavor", "resource"},
+	)
+
+	ClusterQueueResourceUsageIncreaseTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: constants.KueueName,
+			Name:      "cluster_queue_resource_usage_increase_total",
+			Help: `The total amount of resources by which the cluster_queue's resource usage increased, as workloads were admitted, within all the flavors.
+Its rate is the rate at which the cluster_queue admits resources.`,
+		}, []string{"cohort", "cluster_queue", "flavor", "resource"},
+	)
+
 	ClusterQueueResourceNominalQuota = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: constants.KueueName,
@@ -417,6 +444,10 @@ func AdmissionChecksWaitTime(cqName kueue.ClusterQueueReference, waitTime time.D
 	admissionChecksWaitTime.WithLabelValues(string(cqName)).Observe(waitTime.Seconds())
 }
 
+func ReportAdmissionCheckWait(cqName kueue.ClusterQueueReference, waitTime time.Duration) {
+	admissionCheckWaitSeconds.WithLabelValues(string(cqName)).Observe(waitTime.Seconds())
+}
+
 func LocalQueueAdmissionChecksWaitTime(lq LocalQueueReference, waitTime time.Duration) {
 	localQueueAdmissionChecksWaitTime.WithLabelValues(lq.Name, lq.Namespace).Observe(waitTime.Seconds())
 }
@@ -468,8 +499,10 @@ func ClearClusterQueueMetrics(cqName string) {
 	AdmittedWorkloadsTotal.DeleteLabelValues(cqName)
 	admissionWaitTime.DeleteLabelValues(cqName)
 	admissionChecksWaitTime.DeleteLabelValues(cqName)
+	admissionCheckWaitSeconds.DeleteLabelValues(cqName)
 	EvictedWorkloadsTotal.DeletePartialMatch(prometheus.Labels{"cluster_queue": cqName})
 	PreemptedWorkloadsTotal.DeletePartialMatch(prometheus.Labels{"preempting_cluster_queue": cqName})
+	ClearClusterQueueResourceOvercommit(cqName)
 }
 
 func ClearLocalQueueMetrics(lq LocalQueueReference) {
@@ -543,6 +576,18 @@ func ReportClusterQueueResourceUsage(cohort kueue.CohortReference, queue, flavor
 	ClusterQueueResourceUsage.WithLabelValues(string(cohort), queue, flavor, resource).Set(usage)
 }
 
+func ReportClusterQueueResourceOvercommit(cohort kueue.CohortReference, queue, flavor, resource string, overcommit float64) {
+	ClusterQueueResourceOvercommit.WithLabelValues(string(cohort), queue, flavor, resource).Set(overcommit)
+}
+
+func ClearClusterQueueResourceOvercommit(cqName string) {
+	ClusterQueueResourceOvercommit.DeletePartialMatch(prometheus.Labels{"cluster_queue": cqName})
+}
+
+func ReportClusterQueueResourceUsageIncrease(cohort kueue.CohortReference, queue, flavor, resource string, increase float64) {
+	ClusterQueueResourceUsageIncreaseTotal.WithLabelValues(string(cohort), queue, flavor, resource).Add(increase)
+}
+
 func ReportLocalQueueResourceUsage(lq LocalQueueReference, flavor, resource string, usage float64) {
 	LocalQueueResourceUsage.WithLabelValues(lq.Name, lq.Namespace, flavor, resource).Set(usage)
 }
@@ -566,6 +611,7 @@ func ClearClusterQueueResourceMetrics(cqName string) {
 	}
 	ClusterQueueResourceUsage.DeletePartialMatch(lbls)
 	ClusterQueueResourceReservations.DeletePartialMatch(lbls)
+	ClusterQueueResourceUsageIncreaseTotal.DeletePartialMatch(lbls)
 }
 
 func ClearLocalQueueResourceMetrics(lq LocalQueueReference) {
@@ -635,12 +681,15 @@ func Register() {
 		PreemptedWorkloadsTotal,
 		admissionWaitTime,
 		admissionChecksWaitTime,
+		admissionCheckWaitSeconds,
 		ClusterQueueResourceUsage,
 		ClusterQueueByStatus,
 		ClusterQueueResourceReservations,
 		ClusterQueueResourceNominalQuota,
 		ClusterQueueResourceBorrowingLimit,
 		ClusterQueueResourceLendingLimit,
+		ClusterQueueResourceOvercommit,
+		ClusterQueueResourceUsageIncreaseTotal,
 		ClusterQueueWeightedShare,
 		CohortWeightedShare,
 	)
@@ -144,6 +144,17 @@ func TestReportAndCleanupClusterQueueUsage(t *testing.T) {
 	expectFilteredMetricsCount(t, ClusterQueueResourceUsage, 0, "cluster_queue", "queue", "flavor", "flavor", "resource", "res2")
 }
 
+func TestReportAndCleanupClusterQueueOvercommit(t *testing.T) {
+	ReportClusterQueueResourceOvercommit("cohort", "queue", "flavor", "res", 0)
+	ReportClusterQueueResourceOvercommit("cohort", "queue", "flavor2", "res", 2)
+
+	expectFilteredMetricsCount(t, ClusterQueueResourceOvercommit, 2, "cluster_queue", "queue")
+
+	ClearClusterQueueMetrics("queue")
+
+	expectFilteredMetricsCount(t, ClusterQueueResourceOvercommit, 0, "cluster_queue", "queue")
+}
+
 func TestReportAndCleanupClusterQueueEvictedNumber(t *testing.T) {
 	ReportEvictedWorkloads("cluster_queue1", "Preempted")
 	ReportEvictedWorkloads("cluster_queue1", "Evicted")
@@ -0,0 +1,106 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"slices"
+	"sort"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/features"
+	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// GlobalAdmissionOrder returns the keys of the pending workloads of all the
+// active ClusterQueues, in the order in which the scheduler would consider
+// them, assuming that every workload is either admitted or set aside when
+// considered.
+//
+// The scheduler takes the head of every ClusterQueue in each cycle. So the
+// n-th workload of a ClusterQueue, following the ordering of its heap, is
+// considered in the n-th cycle. This honors the queueing strategy of the
+// ClusterQueue: with BestEffortFIFO, the workloads which were found
+// inadmissible wait, without being considered, until they are requeued,
+// while with StrictFIFO they are kept in the heap.
+//
+// Within a cycle, the workloads are ordered like the scheduler does: when
+// the dominantResourceShares of the ClusterQueues are provided, as with
+// fair sharing, the lower share goes first, then the higher priority, if
+// PrioritySortingWithinCohort is enabled, and then FIFO. Whether the
+// workloads would borrow is not taken into account, as it depends on the
+// flavor assignment.
+func (m *Manager) GlobalAdmissionOrder(dominantResourceShares map[kueue.ClusterQueueReference]int) []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	type pending struct {
+		cqName kueue.ClusterQueueReference
+		info   *workload.Info
+	}
+	var cycles [][]pending
+	for cqName, cq := range m.hm.ClusterQueues() {
+		if m.statusChecker != nil && !m.statusChecker.ClusterQueueActive(cqName) {
+			continue
+		}
+		for i, info := range cq.activeSnapshot() {
+			if i == len(cycles) {
+				cycles = append(cycles, nil)
+			}
+			cycles[i] = append(cycles[i], pending{cqName: cqName, info: info})
+		}
+	}
+
+	var keys []string
+	for _, cycle := range cycles {
+		sort.Slice(cycle, func(i, j int) bool {
+			a, b := cycle[i], cycle[j]
+			if dominantResourceShares != nil {
+				if drsA, drsB := dominantResourceShares[a.cqName], dominantResourceShares[b.cqName]; drsA != drsB {
+					return drsA < drsB
+				}
+			}
+			if features.Enabled(features.PrioritySortingWithinCohort) {
+				if pA, pB := utilpriority.Priority(a.info.Obj), utilpriority.Priority(b.info.Obj); pA != pB {
+					return pA > pB
+				}
+			}
+			tA := m.workloadOrdering.GetQueueOrderTimestamp(a.info.Obj)
+			tB := m.workloadOrdering.GetQueueOrderTimestamp(b.info.Obj)
+			if !tA.Equal(tB) {
+				return tA.Before(tB)
+			}
+			return a.cqName < b.cqName
+		})
+		for _, p := range cycle {
+			keys = append(keys, workload.Key(p.info.Obj))
+		}
+	}
+	return keys
+}
+
+// activeSnapshot returns the workloads in the heap of the ClusterQueue,
+// in the order in which they are popped.
+func (c *ClusterQueue) activeSnapshot() []*workload.Info {
+	c.rwm.RLock()
+	defer c.rwm.RUnlock()
+	elements := slices.Clone(c.heap.List())
+	sort.Slice(elements, func(i, j int) bool {
+		return c.lessFunc(elements[i], elements[j])
+	})
+	return elements
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestGlobalAdmissionOrder(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	clusterQueues := []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("active-strict").QueueingStrategy(kueue.StrictFIFO).Obj(),
+		utiltesting.MakeClusterQueue("active-best-effort").QueueingStrategy(kueue.BestEffortFIFO).Obj(),
+		utiltesting.MakeClusterQueue("pending").Obj(),
+	}
+	queues := []*kueue.LocalQueue{
+		utiltesting.MakeLocalQueue("strict", "ns").ClusterQueue("active-strict").Obj(),
+		utiltesting.MakeLocalQueue("best-effort", "ns").ClusterQueue("active-best-effort").Obj(),
+		utiltesting.MakeLocalQueue("pending", "ns").ClusterQueue("pending").Obj(),
+	}
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("s1", "ns").Queue("strict").Creation(now).Obj(),
+		utiltesting.MakeWorkload("b1", "ns").Queue("best-effort").Creation(now.Add(time.Second)).Obj(),
+		utiltesting.MakeWorkload("s2", "ns").Queue("strict").Creation(now.Add(2 * time.Second)).Obj(),
+		utiltesting.MakeWorkload("b2", "ns").Queue("best-effort").Creation(now.Add(3 * time.Second)).Obj(),
+		utiltesting.MakeWorkload("s3", "ns").Queue("strict").Creation(now.Add(4 * time.Second)).Obj(),
+		utiltesting.MakeWorkload("b-high", "ns").Queue("best-effort").Priority(10).Creation(now.Add(5 * time.Second)).Obj(),
+		utiltesting.MakeWorkload("p1", "ns").Queue("pending").Creation(now).Obj(),
+	}
+
+	cases := map[string]struct {
+		dominantResourceShares map[kueue.ClusterQueueReference]int
+		want                   []string
+	}{
+		"classical ordering": {
+			want: []string{"ns/b-high", "ns/s1", "ns/s2", "ns/b2", "ns/s3"},
+		},
+		"fair sharing": {
+			dominantResourceShares: map[kueue.ClusterQueueReference]int{
+				"active-strict":      100,
+				"active-best-effort": 200,
+			},
+			want: []string{"ns/s1", "ns/b-high", "ns/s2", "ns/b2", "ns/s3"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), headsTimeout)
+			defer cancel()
+			cl := utiltesting.NewFakeClient(workloads[0], workloads[1])
+			manager := NewManager(cl, &fakeStatusChecker{})
+			for _, cq := range clusterQueues {
+				if err := manager.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+				}
+			}
+			for _, q := range queues {
+				if err := manager.AddLocalQueue(ctx, q); err != nil {
+					t.Fatalf("Failed adding LocalQueue %s: %v", q.Name, err)
+				}
+			}
+			for _, wl := range workloads[:2] {
+				if err := manager.AddOrUpdateWorkload(wl); err != nil {
+					t.Fatalf("Failed adding workload %s: %v", wl.Name, err)
+				}
+			}
+			// s1 and b1 are found inadmissible: s1 goes back to the head of
+			// the StrictFIFO ClusterQueue, while b1 waits to be requeued.
+			for _, head := range manager.Heads(ctx) {
+				manager.RequeueWorkload(ctx, &head, RequeueReasonGeneric)
+			}
+			for _, wl := range workloads[2:] {
+				if err := manager.AddOrUpdateWorkload(wl); err != nil {
+					t.Fatalf("Failed adding workload %s: %v", wl.Name, err)
+				}
+			}
+
+			got := manager.GlobalAdmissionOrder(tc.dominantResourceShares)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected admission order (-want,+got):\n%s", diff)
+			}
+			if info := manager.getClusterQueue("active-best-effort").inadmissibleWorkloads[workload.Key(workloads[1])]; info == nil {
+				t.Error("Expected b1 to wait as inadmissible")
+			}
+		})
+	}
+}
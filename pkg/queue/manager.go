@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -675,6 +676,22 @@ func (m *Manager) PendingWorkloadsInfo(cqName kueue.ClusterQueueReference) []*wo
 	return cq.Snapshot()
 }
 
+// SuspendedWorkloads returns the sorted keys of the workloads pending in
+// the ClusterQueue, whose jobs are kept suspended while they await
+// admission. Workloads leave the list once they reserve quota, which
+// is when their jobs are unsuspended. Pending workloads aren't tracked
+// by the cache, which only holds workloads reserving quota.
+func (m *Manager) SuspendedWorkloads(cqName kueue.ClusterQueueReference) []string {
+	var keys []string
+	for _, wi := range m.PendingWorkloadsInfo(cqName) {
+		if !workload.HasQuotaReservation(wi.Obj) {
+			keys = append(keys, workload.Key(wi.Obj))
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 // ClusterQueueFromLocalQueue returns ClusterQueue name and whether it's found,
 // given a QueueKey(namespace/localQueueName) as the parameter
 func (m *Manager) ClusterQueueFromLocalQueue(localQueueKey string) (kueue.ClusterQueueReference, bool) {
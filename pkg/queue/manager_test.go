@@ -1285,3 +1285,48 @@ func TestGetPendingWorkloadsInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestSuspendedWorkloads(t *testing.T) {
+	ctx, _ := utiltesting.ContextWithLog(t)
+	manager := NewManager(utiltesting.NewFakeClient(), nil)
+	if err := manager.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	if err := manager.AddLocalQueue(ctx, utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding LocalQueue: %v", err)
+	}
+	if got := manager.SuspendedWorkloads("cq"); len(got) != 0 {
+		t.Errorf("Unexpected suspended workloads in an empty ClusterQueue: %v", got)
+	}
+
+	// The workloads of the suspended jobs await admission.
+	first := utiltesting.MakeWorkload("b", "ns").Queue("lq").Obj()
+	second := utiltesting.MakeWorkload("a", "ns").Queue("lq").Obj()
+	for _, wl := range []*kueue.Workload{first, second} {
+		if err := manager.AddOrUpdateWorkload(wl); err != nil {
+			t.Fatalf("Failed adding workload: %v", err)
+		}
+	}
+	if diff := cmp.Diff([]string{"ns/a", "ns/b"}, manager.SuspendedWorkloads("cq")); diff != "" {
+		t.Errorf("Unexpected suspended workloads (-want,+got):\n%s", diff)
+	}
+
+	// The workload reserves quota, so its job is unsuspended.
+	reserved := first.DeepCopy()
+	workload.SetQuotaReservation(reserved, utiltesting.MakeAdmission("cq").Obj(), nil)
+	if err := manager.UpdateWorkload(first, reserved); err != nil {
+		t.Fatalf("Failed updating workload: %v", err)
+	}
+	if diff := cmp.Diff([]string{"ns/a"}, manager.SuspendedWorkloads("cq")); diff != "" {
+		t.Errorf("Unexpected suspended workloads after reserving quota (-want,+got):\n%s", diff)
+	}
+
+	// The admitted workload is removed from the queues.
+	manager.DeleteWorkload(second)
+	if got := manager.SuspendedWorkloads("cq"); len(got) != 0 {
+		t.Errorf("Unexpected suspended workloads after admission: %v", got)
+	}
+	if got := manager.SuspendedWorkloads("missing"); got != nil {
+		t.Errorf("Unexpected suspended workloads for a missing ClusterQueue: %v", got)
+	}
+}
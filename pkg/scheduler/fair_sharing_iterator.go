@@ -27,7 +27,6 @@ import (
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/features"
-	"sigs.k8s.io/kueue/pkg/util/priority"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -42,11 +41,12 @@ type fairSharingIterator struct {
 	log           logr.Logger
 }
 
-func makeFairSharingIterator(ctx context.Context, entries []entry, workloadOrdering workload.Ordering) *fairSharingIterator {
+func makeFairSharingIterator(ctx context.Context, entries []entry, workloadOrdering workload.Ordering, workloadKey cache.WorkloadKeyFunc) *fairSharingIterator {
 	f := fairSharingIterator{
 		cqToEntry: make(map[*cache.ClusterQueueSnapshot]*entry, len(entries)),
 		entryComparer: entryComparer{
 			workloadOrdering: workloadOrdering,
+			workloadKey:      workloadKey,
 		},
 		log: ctrl.LoggerFrom(ctx),
 	}
@@ -162,11 +162,13 @@ type drsKey struct {
 type entryComparer struct {
 	drsValues        map[drsKey]int
 	workloadOrdering workload.Ordering
+	// workloadKey computes the keys identifying the workloads in drsValues.
+	workloadKey cache.WorkloadKeyFunc
 }
 
 func (e *entryComparer) less(a, b *entry, parentCohort kueue.CohortReference) bool {
-	aDrs := e.drsValues[drsKey{parentCohort: parentCohort, workloadKey: workload.Key(a.Obj)}]
-	bDrs := e.drsValues[drsKey{parentCohort: parentCohort, workloadKey: workload.Key(b.Obj)}]
+	aDrs := e.drsValues[drsKey{parentCohort: parentCohort, workloadKey: e.workloadKey(a.Obj)}]
+	bDrs := e.drsValues[drsKey{parentCohort: parentCohort, workloadKey: e.workloadKey(b.Obj)}]
 	// 1: DRF
 	if aDrs != bDrs {
 		return aDrs < bDrs
@@ -174,8 +176,8 @@ func (e *entryComparer) less(a, b *entry, parentCohort kueue.CohortReference) bo
 
 	// 2: Priority
 	if features.Enabled(features.PrioritySortingWithinCohort) {
-		p1 := priority.Priority(a.Obj)
-		p2 := priority.Priority(b.Obj)
+		p1 := a.priority()
+		p2 := b.priority()
 		if p1 != p2 {
 			return p1 > p2
 		}
@@ -205,14 +207,14 @@ func (ec *entryComparer) computeDRS(rootCohort *cache.CohortSnapshot, cqToEntry
 
 		// calculate DRS, with workload, for CQ.
 		dominantResourceShare := cq.DominantResourceShare()
-		ec.drsValues[drsKey{parentCohort: cq.Parent().GetName(), workloadKey: workload.Key(entry.Obj)}] = dominantResourceShare
+		ec.drsValues[drsKey{parentCohort: cq.Parent().GetName(), workloadKey: ec.workloadKey(entry.Obj)}] = dominantResourceShare
 
 		// calculate DRS, with workload, for all Cohorts on
 		// path to root.
 		cohort := cq.Parent()
 		for cohort.HasParent() {
 			dominantResourceShare := cohort.DominantResourceShare()
-			ec.drsValues[drsKey{parentCohort: cohort.Parent().GetName(), workloadKey: workload.Key(entry.Obj)}] = dominantResourceShare
+			ec.drsValues[drsKey{parentCohort: cohort.Parent().GetName(), workloadKey: ec.workloadKey(entry.Obj)}] = dominantResourceShare
 			cohort = cohort.Parent()
 		}
 
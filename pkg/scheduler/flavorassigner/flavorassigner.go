@@ -49,8 +49,18 @@ type Assignment struct {
 	// flavors assigned.
 	Usage workload.Usage
 
+	// requests are the accumulated requests of the pod sets, from which
+	// the quota usage is computed with the usageAccounting of the
+	// ClusterQueue.
+	requests        resources.FlavorResourceQuantities
+	usageAccounting workload.UsageAccounting
+
 	// representativeMode is the cached representative mode for this assignment.
 	representativeMode *FlavorAssignmentMode
+
+	// nodeLabelMismatches holds the flavors which were skipped because
+	// their node labels don't match the node affinity of a pod set.
+	nodeLabelMismatches []kueue.ResourceFlavorReference
 }
 
 // UpdateForTASResult updates the Assignment with the TAS result
@@ -90,6 +100,61 @@ func (a *Assignment) Borrows() bool {
 	return a.Borrowing
 }
 
+// NodeLabelMismatches returns the flavors which were skipped because their
+// node labels don't match the node affinity of a pod set, once per skip.
+func (a *Assignment) NodeLabelMismatches() []kueue.ResourceFlavorReference {
+	return a.nodeLabelMismatches
+}
+
+// BorrowedQuantities returns, for every flavor and resource, the quantity
+// that the assignment would borrow from the cohort of cq, on top of what
+// cq is already borrowing.
+func (a *Assignment) BorrowedQuantities(cq *cache.ClusterQueueSnapshot) resources.FlavorResourceQuantities {
+	borrowed := make(resources.FlavorResourceQuantities)
+	for fr, val := range a.Usage.Quota {
+		usage := cq.ResourceNode.Usage[fr]
+		nominal := cq.QuotaFor(fr).Nominal
+		if b := max(0, usage+val-nominal) - max(0, usage-nominal); b > 0 {
+			borrowed[fr] = b
+		}
+	}
+	return borrowed
+}
+
+// CompareBorrowing compares the quantities that the assignments a and b
+// would borrow in cq. It returns the difference of the borrowed quantities
+// of a minus b for the flavors and resources where they differ, along with:
+//   - -1 if a borrows less than b, that is, no more for any flavor and
+//     resource and less for at least one.
+//   - 1 if b borrows less than a.
+//   - 0 if both borrow the same, or if each borrows less for some flavor
+//     and resource.
+func CompareBorrowing(cq *cache.ClusterQueueSnapshot, a, b *Assignment) (int, resources.FlavorResourceQuantities) {
+	diff := a.BorrowedQuantities(cq)
+	for fr, q := range b.BorrowedQuantities(cq) {
+		diff[fr] -= q
+	}
+	aLess, bLess := false, false
+	for fr, q := range diff {
+		switch {
+		case q < 0:
+			aLess = true
+		case q > 0:
+			bLess = true
+		default:
+			delete(diff, fr)
+		}
+	}
+	switch {
+	case aLess && !bLess:
+		return -1, diff
+	case bLess && !aLess:
+		return 1, diff
+	default:
+		return 0, diff
+	}
+}
+
 func (a *Assignment) podSetAssignmentByName(psName kueue.PodSetReference) *PodSetAssignment {
 	if idx := slices.IndexFunc(a.PodSets, func(ps PodSetAssignment) bool { return ps.Name == psName }); idx != -1 {
 		return &a.PodSets[idx]
@@ -158,9 +223,13 @@ func (a *Assignment) TotalRequestsFor(wl *workload.Info) resources.FlavorResourc
 		}
 		for res, q := range ps.Requests {
 			flv := aps.Flavors[res].Name
-			usage[resources.FlavorResource{Flavor: flv, Resource: res}] += q
+			fr := resources.FlavorResource{Flavor: flv, Resource: res}
+			usage[fr] = a.usageAccounting.Combine(usage[fr], q)
 		}
 	}
+	for fr, q := range usage {
+		usage[fr] = a.usageAccounting.Quota(fr, q)
+	}
 	return usage
 }
 
@@ -378,6 +447,38 @@ func (a *FlavorAssigner) Assign(log logr.Logger, counts []int32) Assignment {
 	return a.assignFlavors(log, counts)
 }
 
+// orderedResources returns the requested resources in the order their
+// ResourceGroups are evaluated: by decreasing group priority, then in
+// spec order. The resources not covered by any ResourceGroup go last.
+func (a *FlavorAssigner) orderedResources(requests resources.Requests) []corev1.ResourceName {
+	groups := make([]int, len(a.cq.ResourceGroups))
+	for i := range groups {
+		groups[i] = i
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return a.cq.ResourceGroupPriority(&a.cq.ResourceGroups[groups[i]]) > a.cq.ResourceGroupPriority(&a.cq.ResourceGroups[groups[j]])
+	})
+	ordered := make([]corev1.ResourceName, 0, len(requests))
+	for _, i := range groups {
+		covered := make([]corev1.ResourceName, 0, len(requests))
+		for resName := range requests {
+			if a.cq.ResourceGroups[i].CoveredResources.Has(resName) {
+				covered = append(covered, resName)
+			}
+		}
+		slices.Sort(covered)
+		ordered = append(ordered, covered...)
+	}
+	uncovered := make([]corev1.ResourceName, 0, len(requests)-len(ordered))
+	for resName := range requests {
+		if a.cq.RGByResource(resName) == nil {
+			uncovered = append(uncovered, resName)
+		}
+	}
+	slices.Sort(uncovered)
+	return append(ordered, uncovered...)
+}
+
 func (a *FlavorAssigner) assignFlavors(log logr.Logger, counts []int32) Assignment {
 	var requests []workload.PodSetResources
 	if len(counts) == 0 {
@@ -393,6 +494,8 @@ func (a *FlavorAssigner) assignFlavors(log logr.Logger, counts []int32) Assignme
 		Usage: workload.Usage{
 			Quota: make(resources.FlavorResourceQuantities),
 		},
+		requests:        make(resources.FlavorResourceQuantities),
+		usageAccounting: a.cq.UsageAccounting,
 		LastState: workload.AssignmentClusterQueueState{
 			LastTriedFlavorIdx:     make([]map[corev1.ResourceName]int, 0, len(requests)),
 			ClusterQueueGeneration: a.cq.AllocatableResourceGeneration,
@@ -411,13 +514,13 @@ func (a *FlavorAssigner) assignFlavors(log logr.Logger, counts []int32) Assignme
 			Count:    podSet.Count,
 		}
 
-		for resName := range podSet.Requests {
+		for _, resName := range a.orderedResources(podSet.Requests) {
 			if _, found := psAssignment.Flavors[resName]; found {
 				// This resource got assigned the same flavor as its resource group.
 				// No need to compute again.
 				continue
 			}
-			flavors, status := a.findFlavorForPodSetResource(log, i, podSet.Requests, resName, assignment.Usage.Quota)
+			flavors, status := a.findFlavorForPodSetResource(log, i, podSet.Requests, resName, &assignment)
 			if status.IsError() || len(flavors) == 0 {
 				psAssignment.Flavors = nil
 				psAssignment.Status = status
@@ -485,23 +588,31 @@ func (a *Assignment) append(requests resources.Requests, psAssignment *PodSetAss
 			a.Borrowing = true
 		}
 		fr := resources.FlavorResource{Flavor: flvAssignment.Name, Resource: resource}
-		a.Usage.Quota[fr] += requests[resource]
+		a.requests[fr] = a.usageAccounting.Combine(a.requests[fr], requests[resource])
+		a.Usage.Quota[fr] = a.usageAccounting.Quota(fr, a.requests[fr])
 		flavorIdx[resource] = flvAssignment.TriedFlavorIdx
 	}
 	a.LastState.LastTriedFlavorIdx = append(a.LastState.LastTriedFlavorIdx, flavorIdx)
 }
 
+// quotaWith returns the quota usage for the flavor and resource once the
+// requests of another pod set are added.
+func (a *Assignment) quotaWith(fr resources.FlavorResource, val int64) int64 {
+	return a.usageAccounting.Quota(fr, a.usageAccounting.Combine(a.requests[fr], val))
+}
+
 // findFlavorForPodSetResource finds the flavor which can satisfy the podSet request
 // for all resources in the same group as resName.
 // Returns the chosen flavor, along with the information about resources that need to be borrowed.
 // If the flavor cannot be immediately assigned, it returns a status with
-// reasons or failure.
+// reasons or failure. The flavors skipped because of a node label mismatch
+// are recorded in the assignment.
 func (a *FlavorAssigner) findFlavorForPodSetResource(
 	log logr.Logger,
 	psID int,
 	requests resources.Requests,
 	resName corev1.ResourceName,
-	assignmentUsage resources.FlavorResourceQuantities,
+	assignment *Assignment,
 ) (ResourceAssignment, *Status) {
 	resourceGroup := a.cq.RGByResource(resName)
 	if resourceGroup == nil {
@@ -521,8 +632,14 @@ func (a *FlavorAssigner) findFlavorForPodSetResource(
 	// We will only check against the flavors' labels for the resource.
 	selector := flavorSelector(podSpec, resourceGroup.LabelKeys)
 	attemptedFlavorIdx := -1
+	var triedFlavors int32
 	idx := a.wl.LastAssignment.NextFlavorToTryForPodSetResource(psID, resName)
 	for ; idx < len(resourceGroup.Flavors); idx++ {
+		if maxFlavors := a.cq.MaxFlavorsToTry; maxFlavors != nil && triedFlavors >= *maxFlavors {
+			status.appendf("stopped after trying %d flavors", triedFlavors)
+			break
+		}
+		triedFlavors++
 		attemptedFlavorIdx = idx
 		fName := resourceGroup.Flavors[idx]
 		flavor, exist := a.resourceFlavors[fName]
@@ -551,6 +668,7 @@ func (a *FlavorAssigner) findFlavorForPodSetResource(
 				return nil, status
 			}
 			status.appendf("flavor %s doesn't match node affinity", fName)
+			assignment.nodeLabelMismatches = append(assignment.nodeLabelMismatches, fName)
 			continue
 		}
 		needsBorrowing := false
@@ -561,7 +679,7 @@ func (a *FlavorAssigner) findFlavorForPodSetResource(
 			resQuota := a.cq.QuotaFor(resources.FlavorResource{Flavor: fName, Resource: rName})
 			// Check considering the flavor usage by previous pod sets.
 			fr := resources.FlavorResource{Flavor: fName, Resource: rName}
-			mode, borrow, s := a.fitsResourceQuota(log, fr, val+assignmentUsage[fr], resQuota)
+			mode, borrow, s := a.fitsResourceQuota(log, fr, assignment.quotaWith(fr, val), resQuota)
 			if s != nil {
 				status.reasons = append(status.reasons, s.reasons...)
 			}
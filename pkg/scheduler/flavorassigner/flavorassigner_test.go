@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/kueue/pkg/cache"
@@ -2275,6 +2276,402 @@ func TestDeletedFlavors(t *testing.T) {
 	}
 }
 
+func TestMaxFlavorsToTry(t *testing.T) {
+	cases := map[string]struct {
+		maxFlavors  *int32
+		wantRepMode FlavorAssignmentMode
+		wantFlavor  kueue.ResourceFlavorReference
+		wantReasons []string
+	}{
+		"no limit": {
+			wantRepMode: Fit,
+			wantFlavor:  "three",
+		},
+		"limit stops the search before the fitting flavor": {
+			maxFlavors:  ptr.To[int32](2),
+			wantRepMode: NoFit,
+			wantReasons: []string{
+				"insufficient quota for cpu in flavor one, request > maximum capacity (3 > 1)",
+				"insufficient quota for cpu in flavor two, request > maximum capacity (3 > 1)",
+				"stopped after trying 2 flavors",
+			},
+		},
+		"limit reaching the fitting flavor": {
+			maxFlavors:  ptr.To[int32](3),
+			wantRepMode: Fit,
+			wantFlavor:  "three",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			wlInfo := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "3").Obj())
+			cqCache := cache.New(utiltesting.NewFakeClient())
+			flavorMap := make(map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor)
+			for _, name := range []kueue.ResourceFlavorReference{"one", "two", "three"} {
+				flavorMap[name] = utiltesting.MakeResourceFlavor(string(name)).Obj()
+				cqCache.AddOrUpdateResourceFlavor(log, flavorMap[name])
+			}
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("one").Resource(corev1.ResourceCPU, "1").Obj(),
+					*utiltesting.MakeFlavorQuotas("two").Resource(corev1.ResourceCPU, "1").Obj(),
+					*utiltesting.MakeFlavorQuotas("three").Resource(corev1.ResourceCPU, "4").Obj(),
+				).
+				Obj()
+			if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed to add CQ to cache: %v", err)
+			}
+			if err := cqCache.SetMaxFlavorsToTry("cq", tc.maxFlavors); err != nil {
+				t.Fatalf("Failed to set the max flavors to try: %v", err)
+			}
+			snapshot, err := cqCache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error while building snapshot: %v", err)
+			}
+
+			assignment := New(wlInfo, snapshot.ClusterQueue("cq"), flavorMap, false, &testOracle{}).Assign(log, nil)
+			if repMode := assignment.RepresentativeMode(); repMode != tc.wantRepMode {
+				t.Errorf("Unexpected representative mode, want=%s, got=%s", tc.wantRepMode, repMode)
+			}
+			psAssignment := assignment.PodSets[0]
+			var gotFlavor kueue.ResourceFlavorReference
+			if fa := psAssignment.Flavors[corev1.ResourceCPU]; fa != nil {
+				gotFlavor = fa.Name
+			}
+			if gotFlavor != tc.wantFlavor {
+				t.Errorf("Unexpected flavor, want=%q, got=%q", tc.wantFlavor, gotFlavor)
+			}
+			var gotReasons []string
+			if psAssignment.Status != nil {
+				gotReasons = psAssignment.Status.reasons
+			}
+			if diff := cmp.Diff(tc.wantReasons, gotReasons); diff != "" {
+				t.Errorf("Unexpected reasons (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAssignmentNodeLabelMismatches(t *testing.T) {
+	cases := map[string]struct {
+		podSets []kueue.PodSet
+		want    []kueue.ResourceFlavorReference
+	}{
+		"no node selector": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("main", 1).Request(corev1.ResourceCPU, "1").Obj(),
+			},
+		},
+		"mismatch skipped for every pod set": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("driver", 1).
+					Request(corev1.ResourceCPU, "1").
+					NodeSelector(map[string]string{"type": "spot"}).
+					Obj(),
+				*utiltesting.MakePodSet("workers", 2).
+					Request(corev1.ResourceCPU, "1").
+					NodeSelector(map[string]string{"type": "spot"}).
+					Obj(),
+			},
+			want: []kueue.ResourceFlavorReference{"on-demand", "on-demand"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			wlInfo := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").PodSets(tc.podSets...).Obj())
+			cqCache := cache.New(utiltesting.NewFakeClient())
+			flavorMap := map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor{
+				"on-demand": utiltesting.MakeResourceFlavor("on-demand").NodeLabel("type", "on-demand").Obj(),
+				"spot":      utiltesting.MakeResourceFlavor("spot").NodeLabel("type", "spot").Obj(),
+			}
+			for _, rf := range flavorMap {
+				cqCache.AddOrUpdateResourceFlavor(log, rf)
+			}
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+					*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "4").Obj(),
+				).
+				Obj()
+			if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed to add CQ to cache: %v", err)
+			}
+			snapshot, err := cqCache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error while building snapshot: %v", err)
+			}
+
+			assignment := New(wlInfo, snapshot.ClusterQueue("cq"), flavorMap, false, &testOracle{}).Assign(log, nil)
+			if repMode := assignment.RepresentativeMode(); repMode != Fit {
+				t.Errorf("Unexpected representative mode, want=%s, got=%s", Fit, repMode)
+			}
+			if diff := cmp.Diff(tc.want, assignment.NodeLabelMismatches()); diff != "" {
+				t.Errorf("Unexpected node label mismatches (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResourceGroupPriority(t *testing.T) {
+	cases := map[string]struct {
+		priorities  map[corev1.ResourceName]int32
+		wantMessage string
+	}{
+		"spec order by default": {
+			wantMessage: "insufficient quota for cpu in flavor one, request > maximum capacity (2 > 1)",
+		},
+		"higher priority group evaluated first": {
+			priorities:  map[corev1.ResourceName]int32{corev1.ResourceMemory: 1},
+			wantMessage: "insufficient quota for memory in flavor two, request > maximum capacity (2Mi > 1Mi)",
+		},
+		"lower priority group evaluated last": {
+			priorities:  map[corev1.ResourceName]int32{corev1.ResourceCPU: -1},
+			wantMessage: "insufficient quota for memory in flavor two, request > maximum capacity (2Mi > 1Mi)",
+		},
+		"same priority keeps the spec order": {
+			priorities: map[corev1.ResourceName]int32{
+				corev1.ResourceCPU:    1,
+				corev1.ResourceMemory: 1,
+			},
+			wantMessage: "insufficient quota for cpu in flavor one, request > maximum capacity (2 > 1)",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			wlInfo := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "2").
+				Request(corev1.ResourceMemory, "2Mi").
+				Obj())
+			cqCache := cache.New(utiltesting.NewFakeClient())
+			flavorMap := map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor{
+				"one": utiltesting.MakeResourceFlavor("one").Obj(),
+				"two": utiltesting.MakeResourceFlavor("two").Obj(),
+			}
+			for _, rf := range flavorMap {
+				cqCache.AddOrUpdateResourceFlavor(log, rf)
+			}
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("one").Resource(corev1.ResourceCPU, "1").Obj()).
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("two").Resource(corev1.ResourceMemory, "1Mi").Obj()).
+				Obj()
+			if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed to add CQ to cache: %v", err)
+			}
+			for rName, priority := range tc.priorities {
+				if err := cqCache.SetResourceGroupPriority("cq", rName, ptr.To(priority)); err != nil {
+					t.Fatalf("Failed to set the resource group priority: %v", err)
+				}
+			}
+			snapshot, err := cqCache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error while building snapshot: %v", err)
+			}
+
+			assignment := New(wlInfo, snapshot.ClusterQueue("cq"), flavorMap, false, &testOracle{}).Assign(log, nil)
+			if repMode := assignment.RepresentativeMode(); repMode != NoFit {
+				t.Errorf("Unexpected representative mode, want=%s, got=%s", NoFit, repMode)
+			}
+			if diff := cmp.Diff(tc.wantMessage, assignment.PodSets[0].Status.Message()); diff != "" {
+				t.Errorf("Unexpected status message (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUsageAccounting(t *testing.T) {
+	defaultCPU := resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}
+	defaultGPU := resources.FlavorResource{Flavor: "default", Resource: "example.com/gpu"}
+	cases := map[string]struct {
+		cacheOptions []cache.Option
+		translations map[corev1.ResourceName]resource.Quantity
+		podSets      []kueue.PodSet
+		wantMode     FlavorAssignmentMode
+		wantQuota    resources.FlavorResourceQuantities
+	}{
+		"untranslated requests don't fit": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("main", 1).Request("example.com/gpu", "3").Obj(),
+			},
+			wantMode:  NoFit,
+			wantQuota: resources.FlavorResourceQuantities{},
+		},
+		"translated requests fit": {
+			translations: map[corev1.ResourceName]resource.Quantity{
+				"example.com/gpu": resource.MustParse("0.5"),
+			},
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("main", 1).Request("example.com/gpu", "3").Obj(),
+			},
+			wantMode:  Fit,
+			wantQuota: resources.FlavorResourceQuantities{defaultGPU: 2},
+		},
+		"translated requests of several podsets": {
+			translations: map[corev1.ResourceName]resource.Quantity{
+				"example.com/gpu": resource.MustParse("0.5"),
+			},
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("driver", 1).Request("example.com/gpu", "1").Obj(),
+				*utiltesting.MakePodSet("workers", 1).Request("example.com/gpu", "4").Obj(),
+			},
+			wantMode:  NoFit,
+			wantQuota: resources.FlavorResourceQuantities{defaultGPU: 1},
+		},
+		"requests rounded up to the granularity": {
+			cacheOptions: []cache.Option{
+				cache.WithQuotaGranularity(resources.FlavorResourceQuantities{defaultCPU: 1_000}),
+			},
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("main", 1).Request(corev1.ResourceCPU, "1500m").Obj(),
+			},
+			wantMode:  Fit,
+			wantQuota: resources.FlavorResourceQuantities{defaultCPU: 2_000},
+		},
+		"requests rounded up beyond the quota": {
+			cacheOptions: []cache.Option{
+				cache.WithQuotaGranularity(resources.FlavorResourceQuantities{defaultCPU: 2_000}),
+			},
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("main", 1).Request(corev1.ResourceCPU, "2500m").Obj(),
+			},
+			wantMode:  NoFit,
+			wantQuota: resources.FlavorResourceQuantities{},
+		},
+		"requests of the podsets summed": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("launcher", 1).Request(corev1.ResourceCPU, "2").Obj(),
+				*utiltesting.MakePodSet("workers", 1).Request(corev1.ResourceCPU, "2").Obj(),
+			},
+			wantMode:  NoFit,
+			wantQuota: resources.FlavorResourceQuantities{defaultCPU: 2_000},
+		},
+		"largest requests among the podsets": {
+			cacheOptions: []cache.Option{
+				cache.WithPodSetAccounting(workload.PodSetAccountingMax),
+			},
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("launcher", 1).Request(corev1.ResourceCPU, "2").Obj(),
+				*utiltesting.MakePodSet("workers", 1).Request(corev1.ResourceCPU, "2").Obj(),
+			},
+			wantMode:  Fit,
+			wantQuota: resources.FlavorResourceQuantities{defaultCPU: 2_000},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			wlInfo := workload.NewInfo(utiltesting.MakeWorkload("wl", "ns").PodSets(tc.podSets...).Obj())
+			cqCache := cache.New(utiltesting.NewFakeClient(), tc.cacheOptions...)
+			flavorMap := map[kueue.ResourceFlavorReference]*kueue.ResourceFlavor{
+				"default": utiltesting.MakeResourceFlavor("default").Obj(),
+			}
+			cqCache.AddOrUpdateResourceFlavor(log, flavorMap["default"])
+			cq := utiltesting.MakeClusterQueue("cq").
+				ResourceGroup(*utiltesting.MakeFlavorQuotas("default").
+					Resource(corev1.ResourceCPU, "3").
+					Resource("example.com/gpu", "2").
+					Obj()).
+				Obj()
+			if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed to add CQ to cache: %v", err)
+			}
+			if err := cqCache.SetResourceTranslations(log, "cq", tc.translations); err != nil {
+				t.Fatalf("Failed to set the resource translations: %v", err)
+			}
+			snapshot, err := cqCache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error while building snapshot: %v", err)
+			}
+
+			assignment := New(wlInfo, snapshot.ClusterQueue("cq"), flavorMap, false, &testOracle{}).Assign(log, nil)
+			if repMode := assignment.RepresentativeMode(); repMode != tc.wantMode {
+				t.Errorf("Unexpected representative mode, want=%s, got=%s", tc.wantMode, repMode)
+			}
+			if diff := cmp.Diff(tc.wantQuota, assignment.Usage.Quota); diff != "" {
+				t.Errorf("Unexpected quota usage (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompareBorrowing(t *testing.T) {
+	onDemandCPU := resources.FlavorResource{Flavor: "on-demand", Resource: corev1.ResourceCPU}
+	spotCPU := resources.FlavorResource{Flavor: "spot", Resource: corev1.ResourceCPU}
+	nominalOnly := &Assignment{
+		Usage: workload.Usage{Quota: resources.FlavorResourceQuantities{onDemandCPU: 3_000}},
+	}
+	borrowing := &Assignment{
+		Usage: workload.Usage{Quota: resources.FlavorResourceQuantities{spotCPU: 3_000}},
+	}
+	borrowingMore := &Assignment{
+		Usage: workload.Usage{Quota: resources.FlavorResourceQuantities{onDemandCPU: 5_000}},
+	}
+	cases := map[string]struct {
+		a, b     *Assignment
+		want     int
+		wantDiff resources.FlavorResourceQuantities
+	}{
+		"nominal only vs borrowing": {
+			a:        nominalOnly,
+			b:        borrowing,
+			want:     -1,
+			wantDiff: resources.FlavorResourceQuantities{spotCPU: -2_000},
+		},
+		"borrowing vs nominal only": {
+			a:        borrowing,
+			b:        nominalOnly,
+			want:     1,
+			wantDiff: resources.FlavorResourceQuantities{spotCPU: 2_000},
+		},
+		"same assignment": {
+			a:    nominalOnly,
+			b:    nominalOnly,
+			want: 0,
+		},
+		"each borrows less for a different flavor": {
+			a:    borrowing,
+			b:    borrowingMore,
+			want: 0,
+			wantDiff: resources.FlavorResourceQuantities{
+				onDemandCPU: -1_000,
+				spotCPU:     2_000,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cqCache := cache.New(utiltesting.NewFakeClient())
+			cqCache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("on-demand").Obj())
+			cqCache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("spot").Obj())
+			cq := utiltesting.MakeClusterQueue("cq").
+				Cohort("cohort").
+				ResourceGroup(
+					*utiltesting.MakeFlavorQuotas("on-demand").Resource(corev1.ResourceCPU, "4").Obj(),
+					*utiltesting.MakeFlavorQuotas("spot").Resource(corev1.ResourceCPU, "1").Obj(),
+				).
+				Obj()
+			if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Failed to add CQ to cache: %v", err)
+			}
+			snapshot, err := cqCache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error while building snapshot: %v", err)
+			}
+
+			got, gotDiff := CompareBorrowing(snapshot.ClusterQueue("cq"), tc.a, tc.b)
+			if got != tc.want {
+				t.Errorf("Unexpected comparison, want=%d, got=%d", tc.want, got)
+			}
+			if diff := cmp.Diff(tc.wantDiff, gotDiff, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Unexpected borrowing difference (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestLastAssignmentOutdated(t *testing.T) {
 	type args struct {
 		wl *workload.Info
@@ -32,6 +32,9 @@ type PodSetReducer[R any] struct {
 	deltas     []int32
 	totalDelta int32
 	fits       func([]int32) (R, bool)
+	// granularity is the multiple to which the reduced counts are
+	// rounded down. Values lower than 2 disable the rounding.
+	granularity int32
 }
 
 func NewPodSetReducer[R any](podSets []kueue.PodSet, fits func([]int32) (R, bool)) *PodSetReducer[R] {
@@ -53,6 +56,34 @@ func NewPodSetReducer[R any](podSets []kueue.PodSet, fits func([]int32) (R, bool
 	return psr
 }
 
+// WithGranularity makes the reducer round the reduced counts down to a
+// multiple of the granularity, so that partial gangs are not admitted.
+// Counts which would be rounded below the PodSet's MinCount are clamped to
+// the lowest multiple not below it.
+func (psr *PodSetReducer[R]) WithGranularity(granularity int32) *PodSetReducer[R] {
+	psr.granularity = granularity
+	return psr
+}
+
+// roundToGranularity rounds down the reduced counts, without going below
+// the lowest multiple of the granularity which is not below the minimum.
+// When there is no such multiple lower than the full count, the full count
+// is kept. The counts stay non-increasing with the search index, as
+// required by the binary search.
+func (psr *PodSetReducer[R]) roundToGranularity(counts []int32) {
+	if psr.granularity < 2 {
+		return
+	}
+	for i, c := range counts {
+		if psr.deltas[i] == 0 || c == psr.fullCounts[i] {
+			continue
+		}
+		minCount := psr.fullCounts[i] - psr.deltas[i]
+		lowest := min(minCount+(psr.granularity-minCount%psr.granularity)%psr.granularity, psr.fullCounts[i])
+		counts[i] = max(c-c%psr.granularity, lowest)
+	}
+}
+
 func fillPodSetSizesForSearchIndex(out, fullCounts, deltas []int32, upFactor int32, downFactor int32) {
 	// this will panic if len(out) < len(deltas)
 	for i, v := range deltas {
@@ -75,6 +106,7 @@ func (psr *PodSetReducer[R]) Search() (R, bool) {
 	current := make([]int32, len(psr.podSets))
 	idx := sort.Search(int(psr.totalDelta)+1, func(i int) bool {
 		fillPodSetSizesForSearchIndex(current, psr.fullCounts, psr.deltas, int32(i), psr.totalDelta)
+		psr.roundToGranularity(current)
 		r, f := psr.fits(current)
 		if f {
 			lastGoodIdx = i
@@ -25,10 +25,11 @@ import (
 
 func TestSearch(t *testing.T) {
 	cases := map[string]struct {
-		podSets    []kueue.PodSet
-		countLimit int32
-		wantCount  int32
-		wantFound  bool
+		podSets     []kueue.PodSet
+		countLimit  int32
+		granularity int32
+		wantCount   int32
+		wantFound   bool
 	}{
 		"empty": {
 			podSets:    []kueue.PodSet{},
@@ -119,6 +120,52 @@ func TestSearch(t *testing.T) {
 			wantFound:  true,
 			wantCount:  150_000,
 		},
+		"rounded down to granularity": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("ps1", 10).SetMinimumCount(1).Obj(),
+			},
+			countLimit:  9,
+			granularity: 4,
+			wantFound:   true,
+			wantCount:   8,
+		},
+		"rounded below min count": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("ps1", 10).SetMinimumCount(7).Obj(),
+			},
+			countLimit:  7,
+			granularity: 4,
+			wantFound:   false,
+			wantCount:   0,
+		},
+		"rounded down above min count": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("ps1", 10).SetMinimumCount(5).Obj(),
+			},
+			countLimit:  8,
+			granularity: 4,
+			wantFound:   true,
+			wantCount:   8,
+		},
+		"no multiple of granularity between min count and count": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("ps1", 10).SetMinimumCount(9).Obj(),
+			},
+			countLimit:  9,
+			granularity: 4,
+			wantFound:   false,
+			wantCount:   0,
+		},
+		"granularity ignored for podsets without partial admission": {
+			podSets: []kueue.PodSet{
+				*utiltesting.MakePodSet("ps1", 10).SetMinimumCount(1).Obj(),
+				*utiltesting.MakePodSet("ps2", 3).Obj(),
+			},
+			countLimit:  12,
+			granularity: 4,
+			wantFound:   true,
+			wantCount:   11,
+		},
 		"podset with replica count 0": {
 			podSets: []kueue.PodSet{
 				*utiltesting.MakePodSet("ps1", 0).SetMinimumCount(0).Obj(),
@@ -136,7 +183,7 @@ func TestSearch(t *testing.T) {
 					total += v
 				}
 				return total, total <= tc.countLimit
-			})
+			}).WithGranularity(tc.granularity)
 			count, found := red.Search()
 			if count != tc.wantCount {
 				t.Errorf("Unexpected count:%d, want: %d", count, tc.wantCount)
@@ -17,22 +17,27 @@ limitations under the License.
 package preemption
 
 import (
+	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 type PreemptedWorkloads map[string]*workload.Info
 
-func (p PreemptedWorkloads) HasAny(newTargets []*Target) bool {
+// HasAny returns whether any of the targets was already preempted. The
+// workloads are identified by the keys computed by workloadKey.
+func (p PreemptedWorkloads) HasAny(newTargets []*Target, workloadKey cache.WorkloadKeyFunc) bool {
 	for _, target := range newTargets {
-		if _, found := p[workload.Key(target.WorkloadInfo.Obj)]; found {
+		if _, found := p[workloadKey(target.WorkloadInfo.Obj)]; found {
 			return true
 		}
 	}
 	return false
 }
 
-func (p PreemptedWorkloads) Insert(newTargets []*Target) {
+// Insert records the targets as preempted, by the keys computed by
+// workloadKey.
+func (p PreemptedWorkloads) Insert(newTargets []*Target, workloadKey cache.WorkloadKeyFunc) {
 	for _, target := range newTargets {
-		p[workload.Key(target.WorkloadInfo.Obj)] = target.WorkloadInfo
+		p[workloadKey(target.WorkloadInfo.Obj)] = target.WorkloadInfo
 	}
 }
@@ -43,7 +43,6 @@ import (
 	"sigs.k8s.io/kueue/pkg/resources"
 	"sigs.k8s.io/kueue/pkg/scheduler/flavorassigner"
 	"sigs.k8s.io/kueue/pkg/scheduler/preemption/fairsharing"
-	"sigs.k8s.io/kueue/pkg/util/priority"
 	"sigs.k8s.io/kueue/pkg/util/routine"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
@@ -107,10 +106,10 @@ func candidatesOnlyFromQueue(candidates []*workload.Info, clusterQueue kueue.Clu
 	return result
 }
 
-func candidatesFromCQOrUnderThreshold(candidates []*workload.Info, clusterQueue kueue.ClusterQueueReference, threshold int32) []*workload.Info {
+func candidatesFromCQOrUnderThreshold(candidates []*workload.Info, clusterQueue kueue.ClusterQueueReference, threshold int32, workloadPriority func(*workload.Info) int32) []*workload.Info {
 	result := make([]*workload.Info, 0, len(candidates))
 	for _, wi := range candidates {
-		if wi.ClusterQueue == clusterQueue || priority.Priority(wi.Obj) < threshold {
+		if wi.ClusterQueue == clusterQueue || workloadPriority(wi) < threshold {
 			result = append(result, wi)
 		}
 	}
@@ -142,11 +141,11 @@ func (p *Preemptor) GetTargets(log logr.Logger, wl workload.Info, assignment fla
 }
 
 func (p *Preemptor) getTargets(preemptionCtx *preemptionCtx) []*Target {
-	candidates := p.findCandidates(preemptionCtx.preemptor.Obj, preemptionCtx.preemptorCQ, preemptionCtx.frsNeedPreemption)
+	candidates := p.findCandidates(&preemptionCtx.preemptor, preemptionCtx.preemptorCQ, preemptionCtx.snapshot, preemptionCtx.frsNeedPreemption)
 	if len(candidates) == 0 {
 		return nil
 	}
-	sort.Slice(candidates, candidatesOrdering(candidates, preemptionCtx.preemptorCQ.Name, p.clock.Now()))
+	sort.Slice(candidates, candidatesOrdering(candidates, preemptionCtx.preemptorCQ.Name, preemptionCtx.preemptorCQ.ReclaimEvictionOrder, preemptionCtx.snapshot.WorkloadPriority, p.clock.Now()))
 	if p.enableFairSharing {
 		return fairPreemptions(preemptionCtx, candidates, p.fsStrategies)
 	}
@@ -172,7 +171,7 @@ func (p *Preemptor) getTargets(preemptionCtx *preemptionCtx) []*Target {
 	if borrowWithinCohort, thresholdPrio := canBorrowWithinCohort(preemptionCtx); borrowWithinCohort {
 		if !queueUnderNominalInResourcesNeedingPreemption(preemptionCtx) {
 			// It can only preempt workloads from another CQ if they are strictly under allowBorrowingBelowPriority.
-			candidates = candidatesFromCQOrUnderThreshold(candidates, preemptionCtx.preemptor.ClusterQueue, *thresholdPrio)
+			candidates = candidatesFromCQOrUnderThreshold(candidates, preemptionCtx.preemptor.ClusterQueue, *thresholdPrio, preemptionCtx.snapshot.WorkloadPriority)
 		}
 		return minimalPreemptions(preemptionCtx, candidates, true, thresholdPrio)
 	}
@@ -196,7 +195,7 @@ func canBorrowWithinCohort(preemptionCtx *preemptionCtx) (bool, *int32) {
 	if borrowWithinCohort == nil || borrowWithinCohort.Policy == kueue.BorrowWithinCohortPolicyNever {
 		return false, nil
 	}
-	threshold := priority.Priority(preemptionCtx.preemptor.Obj)
+	threshold := preemptionCtx.snapshot.WorkloadPriority(&preemptionCtx.preemptor)
 	if borrowWithinCohort.MaxPriorityThreshold != nil && *borrowWithinCohort.MaxPriorityThreshold < threshold {
 		threshold = *borrowWithinCohort.MaxPriorityThreshold + 1
 	}
@@ -288,7 +287,7 @@ func minimalPreemptions(preemptionCtx *preemptionCtx, candidates []*workload.Inf
 			}
 			reason = kueue.InCohortReclamationReason
 			if allowBorrowingBelowPriority != nil {
-				if priority.Priority(candWl.Obj) >= *allowBorrowingBelowPriority {
+				if preemptionCtx.snapshot.WorkloadPriority(candWl) >= *allowBorrowingBelowPriority {
 					// We set allowBorrowing=false if there is a candidate with priority
 					// exceeding allowBorrowingBelowPriority added to targets.
 					//
@@ -476,17 +475,19 @@ func flavorResourcesNeedPreemption(assignment flavorassigner.Assignment) sets.Se
 
 // findCandidates obtains candidates for preemption within the ClusterQueue and
 // cohort that respect the preemption policy and are using a resource that the
-// preempting workload needs.
-func (p *Preemptor) findCandidates(wl *kueue.Workload, cq *cache.ClusterQueueSnapshot, frsNeedPreemption sets.Set[resources.FlavorResource]) []*workload.Info {
+// preempting workload needs. The priorities are compared as ranked by the
+// snapshot, taking the DefaultPriority of the ClusterQueues into account.
+func (p *Preemptor) findCandidates(preemptor *workload.Info, cq *cache.ClusterQueueSnapshot, snapshot *cache.Snapshot, frsNeedPreemption sets.Set[resources.FlavorResource]) []*workload.Info {
 	var candidates []*workload.Info
-	wlPriority := priority.Priority(wl)
+	wl := preemptor.Obj
+	wlPriority := snapshot.WorkloadPriority(preemptor)
 
 	if cq.Preemption.WithinClusterQueue != kueue.PreemptionPolicyNever {
 		considerSamePrio := (cq.Preemption.WithinClusterQueue == kueue.PreemptionPolicyLowerOrNewerEqualPriority)
 		preemptorTS := p.workloadOrdering.GetQueueOrderTimestamp(wl)
 
 		for _, candidateWl := range cq.Workloads {
-			candidatePriority := priority.Priority(candidateWl.Obj)
+			candidatePriority := snapshot.WorkloadPriority(candidateWl)
 			if candidatePriority > wlPriority {
 				continue
 			}
@@ -510,7 +511,7 @@ func (p *Preemptor) findCandidates(wl *kueue.Workload, cq *cache.ClusterQueueSna
 				continue
 			}
 			for _, candidateWl := range cohortCQ.Workloads {
-				if onlyLowerPriority && priority.Priority(candidateWl.Obj) >= priority.Priority(wl) {
+				if onlyLowerPriority && snapshot.WorkloadPriority(candidateWl) >= wlPriority {
 					continue
 				}
 				if !workloadUsesResources(candidateWl, frsNeedPreemption) {
@@ -588,7 +589,11 @@ func queueUnderNominalInResourcesNeedingPreemption(preemptionCtx *preemptionCtx)
 // same ClusterQueue as the preemptor.
 // 2. Workloads with lower priority first.
 // 3. Workloads admitted more recently first.
-func candidatesOrdering(candidates []*workload.Info, cq kueue.ClusterQueueReference, now time.Time) func(int, int) bool {
+// When the reclaimOrder of the ClusterQueue is NewestFirst, the workloads
+// from other ClusterQueues are ordered by 3. before 2.
+// The priority of the workloads is given by priorityOf, which accounts for
+// the default priority of their ClusterQueues.
+func candidatesOrdering(candidates []*workload.Info, cq kueue.ClusterQueueReference, reclaimOrder cache.ReclaimEvictionOrder, priorityOf func(*workload.Info) int32, now time.Time) func(int, int) bool {
 	return func(i, j int) bool {
 		a := candidates[i]
 		b := candidates[j]
@@ -602,13 +607,16 @@ func candidatesOrdering(candidates []*workload.Info, cq kueue.ClusterQueueRefere
 		if aInCQ != bInCQ {
 			return !aInCQ
 		}
-		pa := priority.Priority(a.Obj)
-		pb := priority.Priority(b.Obj)
+		timeA := quotaReservationTime(a.Obj, now)
+		timeB := quotaReservationTime(b.Obj, now)
+		if !aInCQ && reclaimOrder == cache.ReclaimEvictionOrderNewestFirst && !timeA.Equal(timeB) {
+			return timeA.After(timeB)
+		}
+		pa := priorityOf(a)
+		pb := priorityOf(b)
 		if pa != pb {
 			return pa < pb
 		}
-		timeA := quotaReservationTime(a.Obj, now)
-		timeB := quotaReservationTime(b.Obj, now)
 		if !timeA.Equal(timeB) {
 			return timeA.After(timeB)
 		}
@@ -45,6 +45,7 @@ import (
 	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/resources"
 	"sigs.k8s.io/kueue/pkg/scheduler/flavorassigner"
+	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
 	"sigs.k8s.io/kueue/pkg/util/slices"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
@@ -2778,7 +2779,7 @@ func TestCandidatesOrdering(t *testing.T) {
 			ReserveQuotaAt(utiltesting.MakeAdmission("self").Obj(), now.Add(time.Second)).
 			Obj()),
 	}
-	sort.Slice(candidates, candidatesOrdering(candidates, "self", now))
+	sort.Slice(candidates, candidatesOrdering(candidates, "self", cache.ReclaimEvictionOrderPriority, workloadPriority, now))
 	gotNames := make([]string, len(candidates))
 	for i, c := range candidates {
 		gotNames[i] = workload.Key(c.Obj)
@@ -2789,6 +2790,257 @@ func TestCandidatesOrdering(t *testing.T) {
 	}
 }
 
+func TestCandidatesOrderingReclaimEvictionOrder(t *testing.T) {
+	now := time.Now()
+	makeCandidates := func() []*workload.Info {
+		return []*workload.Info{
+			workload.NewInfo(utiltesting.MakeWorkload("old-low", "").
+				ReserveQuotaAt(utiltesting.MakeAdmission("other").Obj(), now).
+				Priority(-10).
+				Obj()),
+			workload.NewInfo(utiltesting.MakeWorkload("new-high", "").
+				ReserveQuotaAt(utiltesting.MakeAdmission("other").Obj(), now.Add(2*time.Second)).
+				Priority(10).
+				Obj()),
+			workload.NewInfo(utiltesting.MakeWorkload("mid-low", "").
+				ReserveQuotaAt(utiltesting.MakeAdmission("other").Obj(), now.Add(time.Second)).
+				Priority(-10).
+				Obj()),
+			workload.NewInfo(utiltesting.MakeWorkload("self-new-low", "").
+				ReserveQuotaAt(utiltesting.MakeAdmission("self").Obj(), now.Add(3*time.Second)).
+				Priority(-20).
+				Obj()),
+			workload.NewInfo(utiltesting.MakeWorkload("self-old-high", "").
+				ReserveQuotaAt(utiltesting.MakeAdmission("self").Obj(), now).
+				Priority(20).
+				Obj()),
+		}
+	}
+	cases := map[string]struct {
+		reclaimOrder cache.ReclaimEvictionOrder
+		want         []string
+	}{
+		"priority": {
+			reclaimOrder: cache.ReclaimEvictionOrderPriority,
+			want:         []string{"/mid-low", "/old-low", "/new-high", "/self-new-low", "/self-old-high"},
+		},
+		"default is priority": {
+			want: []string{"/mid-low", "/old-low", "/new-high", "/self-new-low", "/self-old-high"},
+		},
+		"newest first": {
+			reclaimOrder: cache.ReclaimEvictionOrderNewestFirst,
+			want:         []string{"/new-high", "/mid-low", "/old-low", "/self-new-low", "/self-old-high"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			candidates := makeCandidates()
+			sort.Slice(candidates, candidatesOrdering(candidates, "self", tc.reclaimOrder, workloadPriority, now))
+			gotNames := make([]string, len(candidates))
+			for i, c := range candidates {
+				gotNames[i] = workload.Key(c.Obj)
+			}
+			if diff := cmp.Diff(tc.want, gotNames); diff != "" {
+				t.Errorf("Sorted with wrong order (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCandidatesOrderingDefaultPriority(t *testing.T) {
+	ctx, log := utiltesting.ContextWithLog(t)
+	now := time.Now()
+	cqCache := cache.New(utiltesting.NewFakeClient())
+	cqCache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cqName := range []string{"self", "other", "no-default"} {
+		cq := utiltesting.MakeClusterQueue(cqName).
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+			Obj()
+		if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	if err := cqCache.SetDefaultPriority("other", ptr.To[int32](5)); err != nil {
+		t.Fatalf("Failed setting the default priority: %v", err)
+	}
+	if err := cqCache.SetDefaultPriority("self", ptr.To[int32](-5)); err != nil {
+		t.Fatalf("Failed setting the default priority: %v", err)
+	}
+	snapshot, err := cqCache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	candidates := []*workload.Info{
+		workload.NewInfo(utiltesting.MakeWorkload("other-unprioritized", "").
+			ReserveQuotaAt(utiltesting.MakeAdmission("other").Obj(), now.Add(time.Second)).
+			Obj()),
+		workload.NewInfo(utiltesting.MakeWorkload("other-low", "").
+			ReserveQuotaAt(utiltesting.MakeAdmission("other").Obj(), now).
+			PriorityClass("low").
+			Priority(1).
+			Obj()),
+		workload.NewInfo(utiltesting.MakeWorkload("other-high", "").
+			ReserveQuotaAt(utiltesting.MakeAdmission("other").Obj(), now).
+			PriorityClass("high").
+			Priority(10).
+			Obj()),
+		workload.NewInfo(utiltesting.MakeWorkload("no-default-unprioritized", "").
+			ReserveQuotaAt(utiltesting.MakeAdmission("no-default").Obj(), now).
+			Obj()),
+		workload.NewInfo(utiltesting.MakeWorkload("self-zero", "").
+			ReserveQuotaAt(utiltesting.MakeAdmission("self").Obj(), now).
+			PriorityClass("zero").
+			Priority(0).
+			Obj()),
+		workload.NewInfo(utiltesting.MakeWorkload("self-unprioritized", "").
+			ReserveQuotaAt(utiltesting.MakeAdmission("self").Obj(), now).
+			Obj()),
+	}
+	sort.Slice(candidates, candidatesOrdering(candidates, "self", cache.ReclaimEvictionOrderPriority, snapshot.WorkloadPriority, now))
+	gotNames := make([]string, len(candidates))
+	for i, c := range candidates {
+		gotNames[i] = workload.Key(c.Obj)
+	}
+	wantCandidates := []string{"/no-default-unprioritized", "/other-low", "/other-unprioritized", "/other-high", "/self-unprioritized", "/self-zero"}
+	if diff := cmp.Diff(wantCandidates, gotNames); diff != "" {
+		t.Errorf("Sorted with wrong order (-want,+got):\n%s", diff)
+	}
+}
+
+func TestPreemptionDefaultPriority(t *testing.T) {
+	now := time.Now()
+	cases := map[string]struct {
+		lenderDefaultPriority *int32
+		selfDefaultPriority   *int32
+		admitted              []kueue.Workload
+		incoming              *kueue.Workload
+		wantPreempted         sets.Set[string]
+	}{
+		"reclaim from a lender without a default priority": {
+			admitted: []kueue.Workload{
+				*utiltesting.MakeWorkload("lender-unprioritized", "").
+					Request(corev1.ResourceCPU, "4").
+					ReserveQuotaAt(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "default", "4").Obj(), now).
+					Obj(),
+			},
+			incoming: utiltesting.MakeWorkload("in", "").
+				PriorityClass("mid").
+				Priority(50).
+				Request(corev1.ResourceCPU, "4").
+				Obj(),
+			wantPreempted: sets.New(targetKeyReason("/lender-unprioritized", kueue.InCohortReclamationReason)),
+		},
+		"the default priority of the lender protects its workloads from the reclamation": {
+			lenderDefaultPriority: ptr.To[int32](100),
+			admitted: []kueue.Workload{
+				*utiltesting.MakeWorkload("lender-unprioritized", "").
+					Request(corev1.ResourceCPU, "4").
+					ReserveQuotaAt(utiltesting.MakeAdmission("lender").Assignment(corev1.ResourceCPU, "default", "4").Obj(), now).
+					Obj(),
+			},
+			incoming: utiltesting.MakeWorkload("in", "").
+				PriorityClass("mid").
+				Priority(50).
+				Request(corev1.ResourceCPU, "4").
+				Obj(),
+		},
+		"the default priority of the preemptor allows preempting within the ClusterQueue": {
+			selfDefaultPriority: ptr.To[int32](100),
+			admitted: []kueue.Workload{
+				*utiltesting.MakeWorkload("self-mid", "").
+					PriorityClass("mid").
+					Priority(50).
+					Request(corev1.ResourceCPU, "4").
+					ReserveQuotaAt(utiltesting.MakeAdmission("self").Assignment(corev1.ResourceCPU, "default", "4").Obj(), now).
+					Obj(),
+			},
+			incoming: utiltesting.MakeWorkload("in", "").
+				Request(corev1.ResourceCPU, "4").
+				Obj(),
+			wantPreempted: sets.New(targetKeyReason("/self-mid", kueue.InClusterQueueReason)),
+		},
+		"no preemption within the ClusterQueue without a default priority": {
+			admitted: []kueue.Workload{
+				*utiltesting.MakeWorkload("self-mid", "").
+					PriorityClass("mid").
+					Priority(50).
+					Request(corev1.ResourceCPU, "4").
+					ReserveQuotaAt(utiltesting.MakeAdmission("self").Assignment(corev1.ResourceCPU, "default", "4").Obj(), now).
+					Obj(),
+			},
+			incoming: utiltesting.MakeWorkload("in", "").
+				Request(corev1.ResourceCPU, "4").
+				Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, log := utiltesting.ContextWithLog(t)
+			cl := utiltesting.NewClientBuilder().
+				WithLists(&kueue.WorkloadList{Items: tc.admitted}).
+				Build()
+			cqCache := cache.New(cl)
+			cqCache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+			clusterQueues := []*kueue.ClusterQueue{
+				utiltesting.MakeClusterQueue("self").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+					Preemption(kueue.ClusterQueuePreemption{
+						WithinClusterQueue:  kueue.PreemptionPolicyLowerPriority,
+						ReclaimWithinCohort: kueue.PreemptionPolicyLowerPriority,
+					}).
+					Obj(),
+				utiltesting.MakeClusterQueue("lender").
+					Cohort("cohort").
+					ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "0").Obj()).
+					Obj(),
+			}
+			for _, cq := range clusterQueues {
+				if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Couldn't add ClusterQueue to cache: %v", err)
+				}
+			}
+			if err := cqCache.SetDefaultPriority("lender", tc.lenderDefaultPriority); err != nil {
+				t.Fatalf("Failed setting the default priority: %v", err)
+			}
+			if err := cqCache.SetDefaultPriority("self", tc.selfDefaultPriority); err != nil {
+				t.Fatalf("Failed setting the default priority: %v", err)
+			}
+
+			broadcaster := record.NewBroadcaster()
+			scheme := runtime.NewScheme()
+			recorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: constants.AdmissionName})
+			preemptor := New(cl, workload.Ordering{}, recorder, config.FairSharing{}, clocktesting.NewFakeClock(now))
+
+			snapshot, err := cqCache.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error while building snapshot: %v", err)
+			}
+			wlInfo := workload.NewInfo(tc.incoming)
+			wlInfo.ClusterQueue = "self"
+			targets := preemptor.GetTargets(log, *wlInfo, singlePodSetAssignment(
+				flavorassigner.ResourceAssignment{
+					corev1.ResourceCPU: &flavorassigner.FlavorAssignment{
+						Name: "default", Mode: flavorassigner.Preempt,
+					},
+				},
+			), snapshot)
+			gotTargets := sets.New(slices.Map(targets, func(t **Target) string {
+				return targetKeyReason(workload.Key((*t).WorkloadInfo.Obj), (*t).Reason)
+			})...)
+			if diff := cmp.Diff(tc.wantPreempted, gotTargets, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Issued preemptions (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func workloadPriority(wi *workload.Info) int32 {
+	return utilpriority.Priority(wi.Obj)
+}
+
 func singlePodSetAssignment(assignments flavorassigner.ResourceAssignment) flavorassigner.Assignment {
 	return flavorassigner.Assignment{
 		PodSets: []flavorassigner.PodSetAssignment{{
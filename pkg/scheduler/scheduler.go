@@ -199,7 +199,7 @@ func (s *Scheduler) schedule(ctx context.Context) wait.SpeedSignal {
 	entries, inadmissibleEntries := s.nominate(ctx, headWorkloads, snapshot)
 
 	// 4. Create iterator which returns ordered entries.
-	iterator := makeIterator(ctx, entries, s.workloadOrdering, s.fairSharing.Enable)
+	iterator := makeIterator(ctx, entries, s.workloadOrdering, s.fairSharing.Enable, snapshot.WorkloadKey)
 
 	// 5. Admit entries, ensuring that no more than one workload gets
 	// admitted by a cohort (if borrowing).
@@ -221,6 +221,8 @@ func (s *Scheduler) schedule(ctx context.Context) wait.SpeedSignal {
 		mode := e.assignment.RepresentativeMode()
 		if mode == flavorassigner.NoFit {
 			log.V(3).Info("Skipping workload as FlavorAssigner assigned NoFit mode")
+			// The quota earmarked by its victims, if any, isn't enough.
+			s.cache.CancelPreemption(log, s.cache.WorkloadKey(e.Obj))
 			continue
 		}
 		log.V(2).Info("Attempting to schedule workload")
@@ -243,21 +245,38 @@ func (s *Scheduler) schedule(ctx context.Context) wait.SpeedSignal {
 		}
 
 		// We skip multiple-preemptions per cohort if any of the targets are overlapping
-		if preemptedWorkloads.HasAny(e.preemptionTargets) {
+		if preemptedWorkloads.HasAny(e.preemptionTargets, snapshot.WorkloadKey) {
 			setSkipped(e, "Workload has overlapping preemption targets with another workload")
 			skippedPreemptions[cq.Name]++
 			continue
 		}
 
+		if mode == flavorassigner.Preempt && !s.cache.PreemptionBudgetAllows(cq.Name, newPreemptionsCount(e.preemptionTargets)) {
+			setSkipped(e, "Workload requires preemptions beyond the preemption budget of the ClusterQueue")
+			skippedPreemptions[cq.Name]++
+			continue
+		}
+
 		usage := e.assignmentUsage()
+		victims := make([]*workload.Info, len(e.preemptionTargets))
+		for i, target := range e.preemptionTargets {
+			victims[i] = target.WorkloadInfo
+		}
+		if !s.cache.FitsLocalQueueQuotaCap(&e.Info, usage.Quota, victims) {
+			setSkipped(e, "Workload exceeds the quota cap of its LocalQueue")
+			continue
+		}
+		// The quota earmarked for the workload is replaced by its usage.
+		restoreEarmarks := snapshot.SimulateEarmarkRelease(s.cache.WorkloadKey(e.Obj))
 		if !fits(cq, &usage, preemptedWorkloads, e.preemptionTargets) {
+			restoreEarmarks()
 			setSkipped(e, "Workload no longer fits after processing another workload")
 			if mode == flavorassigner.Preempt {
 				skippedPreemptions[cq.Name]++
 			}
 			continue
 		}
-		preemptedWorkloads.Insert(e.preemptionTargets)
+		preemptedWorkloads.Insert(e.preemptionTargets, snapshot.WorkloadKey)
 		cq.AddUsage(usage)
 
 		if e.assignment.RepresentativeMode() == flavorassigner.Preempt {
@@ -266,6 +285,13 @@ func (s *Scheduler) schedule(ctx context.Context) wait.SpeedSignal {
 			preempted, err := s.preemptor.IssuePreemptions(ctx, &e.Info, e.preemptionTargets)
 			if err != nil {
 				log.Error(err, "Failed to preempt workloads")
+			} else {
+				s.cache.RecordPreemptions(e.ClusterQueue, victims)
+				if features.Enabled(features.PreemptionGraceAccounting) {
+					if err := s.cache.MarkPreempting(&e.Info, victims); err != nil {
+						log.V(2).Info("Failed to earmark the quota of the preempted workloads", "error", err)
+					}
+				}
 			}
 			if preempted != 0 {
 				e.inadmissibleMsg += fmt.Sprintf(". Pending the preemption of %d workload(s)", preempted)
@@ -344,6 +370,16 @@ func (e *entry) assignmentUsage() workload.Usage {
 	return e.assignment.Usage
 }
 
+// priority returns the priority of the workload as ranked by its
+// ClusterQueue, which takes the DefaultPriority of the ClusterQueue into
+// account.
+func (e *entry) priority() int32 {
+	if e.clusterQueueSnapshot == nil {
+		return priority.Priority(e.Obj)
+	}
+	return e.clusterQueueSnapshot.WorkloadPriority(&e.Info)
+}
+
 // nominate returns the workloads with their requirements (resource flavors, borrowing) if
 // they were admitted by the clusterQueues in the snapshot. The second return value
 // is the list of inadmissibleEntries.
@@ -365,6 +401,8 @@ func (s *Scheduler) nominate(ctx context.Context, workloads []workload.Info, sna
 			e.inadmissibleMsg = fmt.Sprintf("ClusterQueue %s is inactive", w.ClusterQueue)
 		} else if e.clusterQueueSnapshot == nil {
 			e.inadmissibleMsg = fmt.Sprintf("ClusterQueue %s not found", w.ClusterQueue)
+		} else if snap.AdmissionBlockedByCohortDeficit(e.clusterQueueSnapshot) {
+			e.inadmissibleMsg = "The usage of the Cohort exceeds its capacity"
 		} else if err := s.client.Get(ctx, types.NamespacedName{Name: w.Obj.Namespace}, &ns); err != nil {
 			e.inadmissibleMsg = fmt.Sprintf("Could not obtain workload namespace: %v", err)
 		} else if !e.clusterQueueSnapshot.NamespaceSelector.Matches(labels.Set(ns.Labels)) {
@@ -375,7 +413,9 @@ func (s *Scheduler) nominate(ctx context.Context, workloads []workload.Info, sna
 		} else if err := workload.ValidateLimitRange(ctx, s.client, &w); err != nil {
 			e.inadmissibleMsg = fmt.Sprintf("%s: %v", errLimitRangeConstraintsUnsatisfiedResources, err.ToAggregate())
 		} else {
+			restoreEarmarks := snap.SimulateEarmarkRelease(s.cache.WorkloadKey(w.Obj))
 			e.assignment, e.preemptionTargets = s.getAssignments(log, &e.Info, snap)
+			restoreEarmarks()
 			e.inadmissibleMsg = e.assignment.Message()
 			e.Info.LastAssignment = &e.assignment.LastState
 			entries = append(entries, e)
@@ -386,6 +426,19 @@ func (s *Scheduler) nominate(ctx context.Context, workloads []workload.Info, sna
 	return entries, inadmissibleEntries
 }
 
+// newPreemptionsCount returns the number of targets which are not evicted
+// yet, so that the ongoing preemptions are not charged again to the
+// preemption budget.
+func newPreemptionsCount(targets []*preemption.Target) int {
+	count := 0
+	for _, target := range targets {
+		if !workload.IsEvicted(target.WorkloadInfo.Obj) {
+			count++
+		}
+	}
+	return count
+}
+
 func fits(cq *cache.ClusterQueueSnapshot, usage *workload.Usage, preemptedWorkloads preemption.PreemptedWorkloads, newTargets []*preemption.Target) bool {
 	workloads := slices.Collect(maps.Values(preemptedWorkloads))
 	for _, target := range newTargets {
@@ -440,6 +493,7 @@ func (s *Scheduler) getInitialAssignments(log logr.Logger, wl *workload.Info, sn
 	cq := snap.ClusterQueue(wl.ClusterQueue)
 	flvAssigner := flavorassigner.New(wl, cq, snap.ResourceFlavors, s.fairSharing.Enable, preemption.NewOracle(s.preemptor, snap))
 	fullAssignment := flvAssigner.Assign(log, nil)
+	s.cache.RecordNodeLabelMismatches(fullAssignment.NodeLabelMismatches())
 
 	arm := fullAssignment.RepresentativeMode()
 	if arm == flavorassigner.Fit {
@@ -468,7 +522,7 @@ func (s *Scheduler) getInitialAssignments(log logr.Logger, wl *workload.Info, sn
 				}
 			}
 			return nil, false
-		})
+		}).WithGranularity(snap.PartialAdmissionGranularity)
 		if pa, found := reducer.Search(); found {
 			return pa.assignment, pa.preemptionTargets
 		}
@@ -594,8 +648,8 @@ func (e entryOrdering) Less(i, j int) bool {
 
 	// 2. Higher priority first if not disabled.
 	if features.Enabled(features.PrioritySortingWithinCohort) {
-		p1 := priority.Priority(a.Obj)
-		p2 := priority.Priority(b.Obj)
+		p1 := a.priority()
+		p2 := b.priority()
 		if p1 != p2 {
 			return p1 > p2
 		}
@@ -614,9 +668,9 @@ type entryIterator interface {
 	hasNext() bool
 }
 
-func makeIterator(ctx context.Context, entries []entry, workloadOrdering workload.Ordering, enableFairSharing bool) entryIterator {
+func makeIterator(ctx context.Context, entries []entry, workloadOrdering workload.Ordering, enableFairSharing bool, workloadKey cache.WorkloadKeyFunc) entryIterator {
 	if enableFairSharing {
-		return makeFairSharingIterator(ctx, entries, workloadOrdering)
+		return makeFairSharingIterator(ctx, entries, workloadOrdering, workloadKey)
 	}
 	return makeClassicalIterator(entries, workloadOrdering)
 }
@@ -660,6 +714,7 @@ func (s *Scheduler) requeueAndUpdate(ctx context.Context, e entry) {
 	log.V(2).Info("Workload re-queued", "workload", klog.KObj(e.Obj), "clusterQueue", klog.KRef("", string(e.ClusterQueue)), "queue", klog.KRef(e.Obj.Namespace, e.Obj.Spec.QueueName), "requeueReason", e.requeueReason, "added", added, "status", e.status)
 
 	if e.status == notNominated || e.status == skipped {
+		s.cache.RecordRejection(s.cache.WorkloadKey(e.Obj), e.ClusterQueue, e.inadmissibleMsg)
 		patch := workload.PrepareWorkloadPatch(e.Obj, true, s.clock)
 		reservationIsChanged := workload.UnsetQuotaReservationWithCondition(patch, "Pending", e.inadmissibleMsg, s.clock.Now())
 		resourceRequestsIsChanged := workload.PropagateResourceRequests(patch, &e.Info)
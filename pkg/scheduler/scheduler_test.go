@@ -52,6 +52,7 @@ import (
 	"sigs.k8s.io/kueue/pkg/queue"
 	"sigs.k8s.io/kueue/pkg/resources"
 	"sigs.k8s.io/kueue/pkg/scheduler/flavorassigner"
+	"sigs.k8s.io/kueue/pkg/scheduler/preemption"
 	"sigs.k8s.io/kueue/pkg/util/limitrange"
 	"sigs.k8s.io/kueue/pkg/util/routine"
 	"sigs.k8s.io/kueue/pkg/util/slices"
@@ -240,12 +241,42 @@ func TestSchedule(t *testing.T) {
 			},
 		},
 	}
+	deficitClusterQueues := []kueue.ClusterQueue{
+		*utiltesting.MakeClusterQueue("deficit-borrower").
+			Cohort("deficit").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2").Obj()).
+			Obj(),
+		*utiltesting.MakeClusterQueue("deficit-guaranteed").
+			Cohort("deficit").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "2", "", "0").Obj()).
+			Obj(),
+	}
+	deficitLocalQueues := []kueue.LocalQueue{
+		*utiltesting.MakeLocalQueue("deficit-borrower", "eng-alpha").ClusterQueue("deficit-borrower").Obj(),
+		*utiltesting.MakeLocalQueue("deficit-guaranteed", "eng-beta").ClusterQueue("deficit-guaranteed").Obj(),
+	}
+	deficitWorkloads := []kueue.Workload{
+		// The usage exceeds the capacity of the Cohort, which can happen
+		// when its quota is reduced after the workload was admitted.
+		*utiltesting.MakeWorkload("overadmitted", "eng-alpha").
+			Queue("deficit-borrower").
+			Request(corev1.ResourceCPU, "3").
+			ReserveQuota(utiltesting.MakeAdmission("deficit-borrower").Assignment(corev1.ResourceCPU, "default", "3").Obj()).
+			Admitted(true).
+			Obj(),
+		*utiltesting.MakeWorkload("new", "eng-beta").
+			Queue("deficit-guaranteed").
+			Request(corev1.ResourceCPU, "1").
+			Obj(),
+	}
 	cases := map[string]struct {
 		// Features
 		disableLendingLimit     bool
 		disablePartialAdmission bool
 		enableFairSharing       bool
 
+		cacheOptions []cache.Option
+
 		workloads      []kueue.Workload
 		objects        []client.Object
 		admissionError error
@@ -1368,6 +1399,28 @@ func TestSchedule(t *testing.T) {
 				"cq2": {"sales/wl2"},
 			},
 		},
+		"cohort in deficit, admission within the guaranteed quota is allowed by default": {
+			additionalClusterQueues: deficitClusterQueues,
+			additionalLocalQueues:   deficitLocalQueues,
+			workloads:               deficitWorkloads,
+			wantScheduled:           []string{"eng-beta/new"},
+			wantAssignments: map[string]kueue.Admission{
+				"eng-alpha/overadmitted": *utiltesting.MakeAdmission("deficit-borrower").Assignment(corev1.ResourceCPU, "default", "3").Obj(),
+				"eng-beta/new":           *utiltesting.MakeAdmission("deficit-guaranteed").Assignment(corev1.ResourceCPU, "default", "1").Obj(),
+			},
+		},
+		"cohort in deficit, admission is blocked by the policy": {
+			cacheOptions:            []cache.Option{cache.WithCohortDeficitPolicy(cache.CohortDeficitBlockAdmission)},
+			additionalClusterQueues: deficitClusterQueues,
+			additionalLocalQueues:   deficitLocalQueues,
+			workloads:               deficitWorkloads,
+			wantAssignments: map[string]kueue.Admission{
+				"eng-alpha/overadmitted": *utiltesting.MakeAdmission("deficit-borrower").Assignment(corev1.ResourceCPU, "default", "3").Obj(),
+			},
+			wantInadmissibleLeft: map[kueue.ClusterQueueReference][]string{
+				"deficit-guaranteed": {"eng-beta/new"},
+			},
+		},
 		"preemption while borrowing, workload waiting for preemption should not block a borrowing workload in another CQ": {
 			additionalClusterQueues: []kueue.ClusterQueue{
 				*utiltesting.MakeClusterQueue("cq_shared").
@@ -3087,7 +3140,7 @@ func TestSchedule(t *testing.T) {
 				)...)
 			cl := clientBuilder.Build()
 			recorder := &utiltesting.EventRecorder{}
-			cqCache := cache.New(cl)
+			cqCache := cache.New(cl, tc.cacheOptions...)
 			qManager := queue.NewManager(cl, cqCache)
 			// Workloads are loaded into queues or clusterQueues as we add them.
 			for _, q := range allQueues {
@@ -3223,6 +3276,108 @@ func TestSchedule(t *testing.T) {
 	}
 }
 
+func TestSchedulePreemptionGrace(t *testing.T) {
+	features.SetFeatureGateDuringTest(t, features.PreemptionGraceAccounting, true)
+	ctx, log := utiltesting.ContextWithLog(t)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+
+	cq := utiltesting.MakeClusterQueue("cq").
+		ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "4").Obj()).
+		Preemption(kueue.ClusterQueuePreemption{WithinClusterQueue: kueue.PreemptionPolicyLowerPriority}).
+		Obj()
+	lq := utiltesting.MakeLocalQueue("lq", "ns").ClusterQueue("cq").Obj()
+	victim := utiltesting.MakeWorkload("victim", "ns").
+		Queue("lq").
+		Request(corev1.ResourceCPU, "4").
+		ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "default", "4").Obj()).
+		Admitted(true).
+		Obj()
+	preemptor := utiltesting.MakeWorkload("preemptor", "ns").
+		Queue("lq").
+		Priority(100).
+		Request(corev1.ResourceCPU, "4").
+		Obj()
+
+	cl := utiltesting.NewClientBuilder().
+		WithObjects(utiltesting.MakeNamespace("ns"), lq, victim, preemptor).
+		WithStatusSubresource(&kueue.Workload{}).
+		Build()
+	cqCache := cache.New(cl)
+	qManager := queue.NewManager(cl, cqCache)
+	cqCache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Inserting clusterQueue in cache: %v", err)
+	}
+	if err := qManager.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Inserting clusterQueue in manager: %v", err)
+	}
+	if err := qManager.AddLocalQueue(ctx, lq); err != nil {
+		t.Fatalf("Inserting queue in manager: %v", err)
+	}
+	if !cqCache.AddOrUpdateWorkload(log, victim) {
+		t.Fatal("Failed adding the victim to the cache")
+	}
+
+	scheduler := New(qManager, cqCache, cl, &utiltesting.EventRecorder{}, WithClock(t, fakeClock))
+	gotScheduled := sets.New[string]()
+	var mu sync.Mutex
+	scheduler.applyAdmission = func(ctx context.Context, w *kueue.Workload) error {
+		mu.Lock()
+		gotScheduled.Insert(workload.Key(w))
+		mu.Unlock()
+		return nil
+	}
+	wg := sync.WaitGroup{}
+	scheduler.setAdmissionRoutineWrapper(routine.NewWrapper(
+		func() { wg.Add(1) },
+		func() { wg.Done() },
+	))
+	scheduler.preemptor.OverrideApply(func(context.Context, *kueue.Workload, string, string) error {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, queueingTimeout)
+	go qManager.CleanUpOnContext(ctx)
+	defer cancel()
+
+	if err := qManager.AddOrUpdateWorkload(preemptor); err != nil {
+		t.Fatalf("Failed adding the preemptor to the queues: %v", err)
+	}
+	scheduler.schedule(ctx)
+	wg.Wait()
+	if !cqCache.IsPreempting(workload.Key(victim)) {
+		t.Fatal("Expected the victim to be in its preemption grace period")
+	}
+
+	// The victim releases its quota, which is earmarked for the preemptor.
+	if err := cqCache.DeleteWorkload(log, victim); err != nil {
+		t.Fatalf("Failed deleting the victim from the cache: %v", err)
+	}
+	snapshot, err := cqCache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	if got := snapshot.ClusterQueue("cq").Available(resources.FlavorResource{Flavor: "default", Resource: corev1.ResourceCPU}); got != 0 {
+		t.Errorf("Unexpected available quota with earmarked usage: %d", got)
+	}
+
+	if err := qManager.AddOrUpdateWorkload(preemptor); err != nil {
+		t.Fatalf("Failed adding the preemptor to the queues: %v", err)
+	}
+	scheduler.schedule(ctx)
+	wg.Wait()
+	if diff := cmp.Diff(sets.New(workload.Key(preemptor)), gotScheduled); diff != "" {
+		t.Errorf("Unexpected scheduled workloads (-want,+got):\n%s", diff)
+	}
+	snapshot, err = cqCache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+	if got := snapshot.ClusterQueue("cq").Earmarks; len(got) != 0 {
+		t.Errorf("Unexpected earmarks left after admitting the preemptor: %v", got)
+	}
+}
+
 func TestEntryOrdering(t *testing.T) {
 	now := time.Now()
 	input := []entry{
@@ -3472,7 +3627,7 @@ func TestEntryOrdering(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			features.SetFeatureGateDuringTest(t, features.PrioritySortingWithinCohort, tc.prioritySorting)
-			iter := makeIterator(context.Background(), tc.input, tc.workloadOrdering, false)
+			iter := makeIterator(context.Background(), tc.input, tc.workloadOrdering, false, workload.Key)
 			order := make([]string, len(tc.input))
 			for i := range tc.input {
 				order[i] = iter.pop().Obj.Name
@@ -7539,3 +7694,79 @@ func TestScheduleForTASCohorts(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkloadKeyCollisions(t *testing.T) {
+	const clusterLabel = "example.com/cluster"
+	clusterKey := func(w *kueue.Workload) string {
+		return w.Labels[clusterLabel] + "/" + workload.Key(w)
+	}
+	now := time.Now().Truncate(time.Second)
+	ctx, log := utiltesting.ContextWithLog(t)
+	cqCache := cache.New(utiltesting.NewFakeClient(), cache.WithWorkloadKeyFunc(clusterKey))
+	cqCache.AddOrUpdateResourceFlavor(log, utiltesting.MakeResourceFlavor("default").Obj())
+	for _, name := range []string{"a", "b"} {
+		cq := utiltesting.MakeClusterQueue(name).
+			Cohort("cohort").
+			ResourceGroup(*utiltesting.MakeFlavorQuotas("default").Resource(corev1.ResourceCPU, "10").Obj()).
+			Obj()
+		if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	// The ClusterQueue "a" uses all its nominal quota, so that admitting
+	// any workload makes it borrow.
+	cqCache.AddOrUpdateWorkload(log, utiltesting.MakeWorkload("admitted", "ns").
+		Request(corev1.ResourceCPU, "10").
+		ReserveQuota(utiltesting.MakeAdmission("a").Assignment(corev1.ResourceCPU, "default", "10").Obj()).
+		Obj())
+	snapshot, err := cqCache.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed taking snapshot: %v", err)
+	}
+
+	// Both workloads share namespace and name, but come from different clusters.
+	makeEntry := func(cluster string, cqName kueue.ClusterQueueReference, created time.Time) entry {
+		wl := utiltesting.MakeWorkload("wl", "ns").
+			Label(clusterLabel, cluster).
+			Creation(created).
+			Request(corev1.ResourceCPU, "1").
+			Obj()
+		return entry{
+			Info: *workload.NewInfo(wl),
+			assignment: flavorassigner.Assignment{
+				Usage: workload.Usage{Quota: resources.FlavorResourceQuantities{
+					{Flavor: "default", Resource: corev1.ResourceCPU}: 1_000,
+				}},
+			},
+			clusterQueueSnapshot: snapshot.ClusterQueue(cqName),
+		}
+	}
+	// The older workload would win a tie, but it makes its ClusterQueue
+	// borrow, so it must come second.
+	entries := []entry{
+		makeEntry("east", "a", now),
+		makeEntry("west", "b", now.Add(time.Second)),
+	}
+
+	t.Run("fair sharing", func(t *testing.T) {
+		iter := makeFairSharingIterator(ctx, entries, workload.Ordering{PodsReadyRequeuingTimestamp: config.EvictionTimestamp}, snapshot.WorkloadKey)
+		var got []string
+		for iter.hasNext() {
+			got = append(got, snapshot.WorkloadKey(iter.pop().Obj))
+		}
+		if diff := cmp.Diff([]string{"west/ns/wl", "east/ns/wl"}, got); diff != "" {
+			t.Errorf("Unexpected order of the workloads (-want,+got):\n%s", diff)
+		}
+	})
+
+	t.Run("preempted workloads", func(t *testing.T) {
+		preempted := make(preemption.PreemptedWorkloads)
+		preempted.Insert([]*preemption.Target{{WorkloadInfo: &entries[0].Info}}, snapshot.WorkloadKey)
+		if preempted.HasAny([]*preemption.Target{{WorkloadInfo: &entries[1].Info}}, snapshot.WorkloadKey) {
+			t.Error("Unexpected collision between the preempted workloads of different clusters")
+		}
+		if !preempted.HasAny([]*preemption.Target{{WorkloadInfo: &entries[0].Info}}, snapshot.WorkloadKey) {
+			t.Error("Expected the preempted workload to be found")
+		}
+	})
+}
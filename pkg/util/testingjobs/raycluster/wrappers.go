@@ -172,6 +172,19 @@ func (j *ClusterWrapper) WithNumOfHosts(groupName string, value int32) *ClusterW
 	return j
 }
 
+// WithAutoscalingReplicas sets the replicas, minReplicas and maxReplicas of
+// the worker group.
+func (j *ClusterWrapper) WithAutoscalingReplicas(groupName string, replicas, minReplicas, maxReplicas int32) *ClusterWrapper {
+	for index, group := range j.Spec.WorkerGroupSpecs {
+		if group.GroupName == groupName {
+			j.Spec.WorkerGroupSpecs[index].Replicas = ptr.To(replicas)
+			j.Spec.WorkerGroupSpecs[index].MinReplicas = ptr.To(minReplicas)
+			j.Spec.WorkerGroupSpecs[index].MaxReplicas = ptr.To(maxReplicas)
+		}
+	}
+	return j
+}
+
 // WorkloadPriorityClass updates job workloadpriorityclass.
 func (j *ClusterWrapper) WorkloadPriorityClass(wpc string) *ClusterWrapper {
 	if j.Labels == nil {
@@ -88,6 +88,10 @@ func (w *ResourceFlavorWebhook) ValidateDelete(_ context.Context, _ runtime.Obje
 func ValidateResourceFlavor(rf *kueue.ResourceFlavor) field.ErrorList {
 	var allErrs field.ErrorList
 
+	if kueue.ResourceFlavorReference(rf.Name) == kueue.OrphanedResourceFlavorName {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("metadata", "name"), "reserved to report the usage of deleted flavors"))
+	}
+
 	specPath := field.NewPath("spec")
 	allErrs = append(allErrs, metavalidation.ValidateLabels(rf.Spec.NodeLabels, specPath.Child("nodeLabels"))...)
 
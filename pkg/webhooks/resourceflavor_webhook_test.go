@@ -63,6 +63,13 @@ func TestValidateResourceFlavor(t *testing.T) {
 				field.Invalid(field.NewPath("spec", "nodeLabels"), "@abc", ""),
 			},
 		},
+		{
+			name: "reserved name",
+			rf:   utiltesting.MakeResourceFlavor(string(kueue.OrphanedResourceFlavorName)).Obj(),
+			wantErr: field.ErrorList{
+				field.Forbidden(field.NewPath("metadata", "name"), ""),
+			},
+		},
 	}
 
 	for _, tc := range testcases {
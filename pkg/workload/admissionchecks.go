@@ -31,11 +31,16 @@ import (
 // with the state of QuotaReserved and AdmissionChecks.
 // Return true if any change was done.
 func SyncAdmittedCondition(w *kueue.Workload, now time.Time) bool {
+	return SyncAdmittedConditionWithChecks(w, HasAllChecksReady(w), now)
+}
+
+// SyncAdmittedConditionWithChecks is like SyncAdmittedCondition, but relies on
+// checksSatisfied to tell whether the AdmissionChecks allow the admission.
+func SyncAdmittedConditionWithChecks(w *kueue.Workload, checksSatisfied bool, now time.Time) bool {
 	hasReservation := HasQuotaReservation(w)
-	hasAllChecksReady := HasAllChecksReady(w)
 	isAdmitted := IsAdmitted(w)
 
-	if isAdmitted == (hasReservation && hasAllChecksReady) {
+	if isAdmitted == (hasReservation && checksSatisfied) {
 		return false
 	}
 	newCondition := metav1.Condition{
@@ -46,7 +51,7 @@ func SyncAdmittedCondition(w *kueue.Workload, now time.Time) bool {
 		ObservedGeneration: w.Generation,
 	}
 	switch {
-	case !hasReservation && !hasAllChecksReady:
+	case !hasReservation && !checksSatisfied:
 		newCondition.Status = metav1.ConditionFalse
 		newCondition.Reason = "NoReservationUnsatisfiedChecks"
 		newCondition.Message = "The workload has no reservation and not all checks ready"
@@ -54,7 +59,7 @@ func SyncAdmittedCondition(w *kueue.Workload, now time.Time) bool {
 		newCondition.Status = metav1.ConditionFalse
 		newCondition.Reason = "NoReservation"
 		newCondition.Message = "The workload has no reservation"
-	case !hasAllChecksReady:
+	case !checksSatisfied:
 		newCondition.Status = metav1.ConditionFalse
 		newCondition.Reason = "UnsatisfiedChecks"
 		newCondition.Message = "The workload has not all checks ready"
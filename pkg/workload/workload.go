@@ -28,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -88,6 +89,10 @@ type AssignmentClusterQueueState struct {
 type InfoOptions struct {
 	excludedResourcePrefixes []string
 	resourceTransformations  map[corev1.ResourceName]*config.ResourceTransformation
+	resourceTranslations     map[corev1.ResourceName]resource.Quantity
+	quotaGranularity         resources.FlavorResourceQuantities
+	podSetAccounting         PodSetAccounting
+	admittedGeneration       int64
 }
 
 type InfoOption func(*InfoOptions)
@@ -108,6 +113,51 @@ func WithResourceTransformations(transforms []config.ResourceTransformation) Inf
 	}
 }
 
+// WithResourceTranslations sets the factors by which the usage of the
+// resources is multiplied when computing the usage of the workload.
+func WithResourceTranslations(translations map[corev1.ResourceName]resource.Quantity) InfoOption {
+	return func(o *InfoOptions) {
+		o.resourceTranslations = translations
+	}
+}
+
+// WithQuotaGranularity sets, by flavor and resource, the minimum
+// allocation unit to which the usage of the workload is rounded up.
+func WithQuotaGranularity(granularity resources.FlavorResourceQuantities) InfoOption {
+	return func(o *InfoOptions) {
+		o.quotaGranularity = granularity
+	}
+}
+
+// PodSetAccounting defines how the usage of the podsets of a workload is
+// combined for each flavor and resource.
+type PodSetAccounting string
+
+const (
+	// PodSetAccountingSum sums the usage of the podsets. This is the
+	// default.
+	PodSetAccountingSum PodSetAccounting = "Sum"
+	// PodSetAccountingMax takes the largest usage among the podsets, for
+	// workloads whose podsets overlap, for example because they share
+	// containers, so that the shared resources are not counted twice.
+	PodSetAccountingMax PodSetAccounting = "Max"
+)
+
+// WithPodSetAccounting sets how the usage of the podsets is combined.
+func WithPodSetAccounting(a PodSetAccounting) InfoOption {
+	return func(o *InfoOptions) {
+		o.podSetAccounting = a
+	}
+}
+
+// WithAdmittedGeneration sets the AllocatableResourceGeneration of the
+// ClusterQueue at the time the workload was admitted.
+func WithAdmittedGeneration(generation int64) InfoOption {
+	return func(o *InfoOptions) {
+		o.admittedGeneration = generation
+	}
+}
+
 func (s *AssignmentClusterQueueState) Clone() *AssignmentClusterQueueState {
 	c := AssignmentClusterQueueState{
 		LastTriedFlavorIdx:     make([]map[corev1.ResourceName]int, len(s.LastTriedFlavorIdx)),
@@ -159,6 +209,18 @@ type Info struct {
 	// already admitted.
 	ClusterQueue   kueue.ClusterQueueReference
 	LastAssignment *AssignmentClusterQueueState
+	// ResourceTranslations holds, by resource, the factor by which the
+	// requests are multiplied when computing the usage of the workload.
+	ResourceTranslations map[corev1.ResourceName]resource.Quantity
+	// QuotaGranularity holds, by flavor and resource, the minimum
+	// allocation unit to which the usage of the workload is rounded up.
+	QuotaGranularity resources.FlavorResourceQuantities
+	// PodSetAccounting defines how the usage of the podsets is combined.
+	// Empty means PodSetAccountingSum.
+	PodSetAccounting PodSetAccounting
+	// AllocatableResourceGeneration is the generation of the ClusterQueue
+	// at the time the workload was admitted in the cache.
+	AllocatableResourceGeneration int64
 }
 
 type PodSetResources struct {
@@ -221,7 +283,11 @@ func NewInfo(w *kueue.Workload, opts ...InfoOption) *Info {
 		opt(&options)
 	}
 	info := &Info{
-		Obj: w,
+		Obj:                           w,
+		ResourceTranslations:          options.resourceTranslations,
+		QuotaGranularity:              options.quotaGranularity,
+		PodSetAccounting:              options.podSetAccounting,
+		AllocatableResourceGeneration: options.admittedGeneration,
 	}
 	if w.Status.Admission != nil {
 		info.ClusterQueue = w.Status.Admission.ClusterQueue
@@ -232,6 +298,34 @@ func NewInfo(w *kueue.Workload, opts ...InfoOption) *Info {
 	return info
 }
 
+// AdmittedGeneration returns the AllocatableResourceGeneration of the
+// ClusterQueue at the time the workload was admitted, or 0 if unknown.
+// It can be compared with the current generation of the ClusterQueue to
+// detect admissions made under outdated quotas.
+func (i *Info) AdmittedGeneration() int64 {
+	return i.AllocatableResourceGeneration
+}
+
+// Clone returns a copy of the Info which can be modified without
+// affecting the original. The TopologyRequests of the podsets are shared.
+func (i *Info) Clone() *Info {
+	c := *i
+	c.Obj = i.Obj.DeepCopy()
+	c.TotalRequests = make([]PodSetResources, len(i.TotalRequests))
+	for idx := range i.TotalRequests {
+		psr := i.TotalRequests[idx]
+		psr.Requests = psr.Requests.Clone()
+		psr.Flavors = maps.Clone(psr.Flavors)
+		c.TotalRequests[idx] = psr
+	}
+	if i.LastAssignment != nil {
+		c.LastAssignment = i.LastAssignment.Clone()
+	}
+	c.ResourceTranslations = maps.Clone(i.ResourceTranslations)
+	c.QuotaGranularity = maps.Clone(i.QuotaGranularity)
+	return &c
+}
+
 func (i *Info) Update(wl *kueue.Workload) {
 	i.Obj = wl
 }
@@ -251,20 +345,96 @@ func (i *Info) Usage() Usage {
 
 // FlavorResourceUsage returns the total resource usage for the workload,
 // per flavor (if assigned, otherwise flavor shows as empty string), per resource.
+// With PodSetAccountingMax, the usage is the largest among the podsets
+// instead of their sum.
 func (i *Info) FlavorResourceUsage() resources.FlavorResourceQuantities {
 	total := make(resources.FlavorResourceQuantities)
 	if i == nil {
 		return total
 	}
+	accounting := i.UsageAccounting()
 	for _, psReqs := range i.TotalRequests {
 		for res, q := range psReqs.Requests {
-			flv := psReqs.Flavors[res]
-			total[resources.FlavorResource{Flavor: flv, Resource: res}] += q
+			fr := resources.FlavorResource{Flavor: psReqs.Flavors[res], Resource: res}
+			total[fr] = accounting.Combine(total[fr], q)
 		}
 	}
+	for fr, q := range total {
+		total[fr] = accounting.Quota(fr, q)
+	}
 	return total
 }
 
+// UsageAccounting defines how the requests of the podsets of a workload
+// are accounted as quota usage. The flavor assignment uses the accounting
+// of the ClusterQueue, so that admission checks the same usage the cache
+// accounts once the workload is admitted.
+type UsageAccounting struct {
+	// ResourceTranslations holds, by resource, the factor by which the
+	// requests are multiplied.
+	ResourceTranslations map[corev1.ResourceName]resource.Quantity
+	// QuotaGranularity holds, by flavor and resource, the minimum
+	// allocation unit to which the usage is rounded up.
+	QuotaGranularity resources.FlavorResourceQuantities
+	// PodSetAccounting defines how the requests of the podsets are
+	// combined. Empty means PodSetAccountingSum.
+	PodSetAccounting PodSetAccounting
+}
+
+// NewUsageAccounting returns the usage accounting set by the options.
+func NewUsageAccounting(opts ...InfoOption) UsageAccounting {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return UsageAccounting{
+		ResourceTranslations: options.resourceTranslations,
+		QuotaGranularity:     options.quotaGranularity,
+		PodSetAccounting:     options.podSetAccounting,
+	}
+}
+
+// UsageAccounting returns how the requests of the workload are accounted
+// as quota usage.
+func (i *Info) UsageAccounting() UsageAccounting {
+	return UsageAccounting{
+		ResourceTranslations: i.ResourceTranslations,
+		QuotaGranularity:     i.QuotaGranularity,
+		PodSetAccounting:     i.PodSetAccounting,
+	}
+}
+
+// Combine returns the requests of the podsets for a flavor and resource
+// combined with the requests of another podset.
+func (u *UsageAccounting) Combine(requests, podSetRequests int64) int64 {
+	if u.PodSetAccounting == PodSetAccountingMax {
+		return max(requests, podSetRequests)
+	}
+	return requests + podSetRequests
+}
+
+// Quota returns the quota usage for the requests of the podsets for the
+// flavor and resource.
+func (u *UsageAccounting) Quota(fr resources.FlavorResource, requests int64) int64 {
+	if factor, found := u.ResourceTranslations[fr.Resource]; found {
+		requests = translateValue(requests, factor)
+	}
+	if unit := u.QuotaGranularity[fr]; unit > 0 && requests%unit != 0 {
+		requests += unit - requests%unit
+	}
+	return requests
+}
+
+// translateValue multiplies the value by the factor, rounding up so that
+// a fraction of a unit still counts against the quota.
+func translateValue(v int64, factor resource.Quantity) int64 {
+	milli := v * factor.MilliValue()
+	if milli%1000 > 0 {
+		return milli/1000 + 1
+	}
+	return milli / 1000
+}
+
 func dropExcludedResources(input corev1.ResourceList, excludedPrefixes []string) corev1.ResourceList {
 	res := corev1.ResourceList{}
 	for inputName, inputQuantity := range input {
@@ -813,6 +813,115 @@ func TestFlavorResourceUsage(t *testing.T) {
 				{Flavor: "model_b", Resource: "example.com/gpu"}: 1,
 			},
 		},
+		"translated resource": {
+			info: &Info{
+				TotalRequests: []PodSetResources{
+					{
+						Requests: resources.Requests{
+							corev1.ResourceCPU: 1_000,
+							"example.com/gpu":  3,
+						},
+						Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+							corev1.ResourceCPU: "default",
+							"example.com/gpu":  "model_a",
+						},
+					},
+					{
+						Requests: resources.Requests{
+							"example.com/gpu": 2,
+						},
+						Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+							"example.com/gpu": "model_b",
+						},
+					},
+				},
+				ResourceTranslations: map[corev1.ResourceName]resource.Quantity{
+					"example.com/gpu": resource.MustParse("0.5"),
+				},
+			},
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: "cpu"}:             1_000,
+				{Flavor: "model_a", Resource: "example.com/gpu"}: 2,
+				{Flavor: "model_b", Resource: "example.com/gpu"}: 1,
+			},
+		},
+		"rounded up to the granularity": {
+			info: &Info{
+				TotalRequests: []PodSetResources{{
+					Requests: resources.Requests{
+						corev1.ResourceCPU:    1_500,
+						corev1.ResourceMemory: 2 * utiltesting.Gi,
+						"example.com/gpu":     3,
+					},
+					Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+						corev1.ResourceCPU:    "default",
+						corev1.ResourceMemory: "default",
+						"example.com/gpu":     "model_a",
+					},
+				}},
+				ResourceTranslations: map[corev1.ResourceName]resource.Quantity{
+					"example.com/gpu": resource.MustParse("0.5"),
+				},
+				QuotaGranularity: resources.FlavorResourceQuantities{
+					{Flavor: "default", Resource: "cpu"}:             1_000,
+					{Flavor: "default", Resource: "memory"}:          utiltesting.Gi,
+					{Flavor: "model_a", Resource: "example.com/gpu"}: 4,
+				},
+			},
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: "cpu"}:             2_000,
+				{Flavor: "default", Resource: "memory"}:          2 * utiltesting.Gi,
+				{Flavor: "model_a", Resource: "example.com/gpu"}: 4,
+			},
+		},
+		"overlapping podsets, summed": {
+			info: &Info{
+				TotalRequests: []PodSetResources{
+					{
+						Requests: resources.Requests{corev1.ResourceCPU: 2_000, "example.com/gpu": 1},
+						Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+							corev1.ResourceCPU: "default",
+							"example.com/gpu":  "model_a",
+						},
+					},
+					{
+						Requests: resources.Requests{corev1.ResourceCPU: 3_000},
+						Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+							corev1.ResourceCPU: "default",
+						},
+					},
+				},
+				PodSetAccounting: PodSetAccountingSum,
+			},
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: "cpu"}:             5_000,
+				{Flavor: "model_a", Resource: "example.com/gpu"}: 1,
+			},
+		},
+		"overlapping podsets, max'd": {
+			info: &Info{
+				TotalRequests: []PodSetResources{
+					{
+						Requests: resources.Requests{corev1.ResourceCPU: 2_000, "example.com/gpu": 1},
+						Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+							corev1.ResourceCPU: "default",
+							"example.com/gpu":  "model_a",
+						},
+					},
+					{
+						Requests: resources.Requests{corev1.ResourceCPU: 3_000},
+						Flavors: map[corev1.ResourceName]kueue.ResourceFlavorReference{
+							corev1.ResourceCPU: "default",
+						},
+					},
+				},
+				PodSetAccounting: PodSetAccountingMax,
+			},
+			want: resources.FlavorResourceQuantities{
+				{Flavor: "default", Resource: "cpu"}:             3_000,
+				{Flavor: "model_a", Resource: "example.com/gpu"}: 1,
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -473,6 +473,30 @@ func ExpectWorkloadToBeAdmittedAs(ctx context.Context, k8sClient client.Client,
 	}, Timeout, Interval).Should(gomega.Succeed())
 }
 
+// ExpectWorkloadFlavorAssignment waits for the workload to be admitted with the
+// resource assigned to wantFlavor in all of its PodSets requesting it.
+func ExpectWorkloadFlavorAssignment(ctx context.Context, k8sClient client.Client, key client.ObjectKey, resource corev1.ResourceName, wantFlavor kueue.ResourceFlavorReference) {
+	gomega.EventuallyWithOffset(1, func(g gomega.Gomega) {
+		var wl kueue.Workload
+		g.Expect(k8sClient.Get(ctx, key, &wl)).To(gomega.Succeed())
+		expectFlavorAssignment(g, &wl, resource, wantFlavor)
+	}, Timeout, Interval).Should(gomega.Succeed())
+}
+
+func expectFlavorAssignment(g gomega.Gomega, wl *kueue.Workload, resource corev1.ResourceName, wantFlavor kueue.ResourceFlavorReference) {
+	g.Expect(workload.IsAdmitted(wl)).To(gomega.BeTrue(), "Workload %s is not admitted", klog.KObj(wl))
+	got := make(map[kueue.PodSetReference]kueue.ResourceFlavorReference)
+	if wl.Status.Admission != nil {
+		for _, psa := range wl.Status.Admission.PodSetAssignments {
+			if flavor, found := psa.Flavors[resource]; found {
+				got[psa.Name] = flavor
+			}
+		}
+	}
+	g.Expect(got).NotTo(gomega.BeEmpty(), "Resource %s is not assigned in workload %s", resource, klog.KObj(wl))
+	g.Expect(got).To(gomega.HaveEach(wantFlavor), "Unexpected flavors assigned for resource %s in workload %s", resource, klog.KObj(wl))
+}
+
 var attemptStatuses = []metrics.AdmissionResult{metrics.AdmissionResultInadmissible, metrics.AdmissionResultSuccess}
 
 func ExpectAdmissionAttemptsMetric(pending, admitted int) {
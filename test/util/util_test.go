@@ -0,0 +1,83 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestExpectWorkloadFlavorAssignment(t *testing.T) {
+	testCases := map[string]struct {
+		wl          *kueue.Workload
+		wantFailure string
+	}{
+		"assigned to the expected flavor": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "on-demand", "1").Obj()).
+				Admitted(true).
+				Obj(),
+		},
+		"assigned to another flavor": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceCPU, "spot", "1").Obj()).
+				Admitted(true).
+				Obj(),
+			wantFailure: "spot",
+		},
+		"resource not assigned": {
+			wl: utiltesting.MakeWorkload("wl", "ns").
+				ReserveQuota(utiltesting.MakeAdmission("cq").Assignment(corev1.ResourceMemory, "on-demand", "1Gi").Obj()).
+				Admitted(true).
+				Obj(),
+			wantFailure: "Resource cpu is not assigned",
+		},
+		"not admitted": {
+			wl:          utiltesting.MakeWorkload("wl", "ns").Obj(),
+			wantFailure: "is not admitted",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if tc.wantFailure == "" {
+				ctx, _ := utiltesting.ContextWithLog(t)
+				k8sClient := utiltesting.NewFakeClient(tc.wl)
+				gomega.RegisterTestingT(t)
+				ExpectWorkloadFlavorAssignment(ctx, k8sClient, client.ObjectKeyFromObject(tc.wl), corev1.ResourceCPU, "on-demand")
+				return
+			}
+			var failures []string
+			g := gomega.NewGomega(func(message string, _ ...int) {
+				failures = append(failures, message)
+			})
+			expectFlavorAssignment(g, tc.wl, corev1.ResourceCPU, "on-demand")
+			if len(failures) == 0 {
+				t.Fatal("Expected the assertion to fail")
+			}
+			if !strings.Contains(failures[0], tc.wantFailure) {
+				t.Errorf("Failure %q doesn't contain %q", failures[0], tc.wantFailure)
+			}
+		})
+	}
+}